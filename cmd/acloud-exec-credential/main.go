@@ -0,0 +1,60 @@
+// Command acloud-exec-credential is a kubectl exec-credential plugin that logs in to the OpenID Connect provider of an
+// Avisi Cloud cluster and prints the ExecCredential containing the ID token. See package execcredential.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/avisi-cloud/go-client/pkg/execcredential"
+)
+
+func main() {
+	config := execcredential.Config{}
+	var scopes string
+	var timeout time.Duration
+	flag.StringVar(&config.IssuerURL, "issuer-url", "", "URL of the OpenID Connect provider")
+	flag.StringVar(&config.ClientID, "client-id", "", "OIDC client ID")
+	flag.StringVar(&config.ClientSecret, "client-secret", "", "OIDC client secret, optional")
+	flag.StringVar(&config.ClusterIdentity, "cluster-identity", "", "identity of the cluster, used as key of the token cache")
+	flag.StringVar(&scopes, "scopes", "email,groups", "comma separated scopes to request in addition to openid")
+	flag.StringVar(&config.CacheDir, "cache-dir", execcredential.DefaultCacheDir(), "directory of the token cache")
+	flag.StringVar(&config.ListenAddress, "listen-address", execcredential.DefaultListenAddress, "address to receive the OIDC redirect on")
+	flag.DurationVar(&timeout, "timeout", 5*time.Minute, "maximum duration of the login")
+	flag.Parse()
+
+	if scopes != "" {
+		config.Scopes = strings.Split(scopes, ",")
+	}
+
+	execInfo := os.Getenv(execcredential.EnvExecInfo)
+	input, err := execcredential.ParseExecInfo(execInfo)
+	if err != nil {
+		fail(err)
+	}
+	// kubectl tells whether the plugin may interact with the user, a plugin run by hand is always interactive
+	config.Interactive = execInfo == "" || input.Spec.Interactive
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	ctx, cancelTimeout := context.WithTimeout(ctx, timeout)
+	defer cancelTimeout()
+
+	credential, err := execcredential.GetExecCredential(ctx, config, input)
+	if err != nil {
+		fail(err)
+	}
+	if err := credential.Write(os.Stdout); err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	_, _ = fmt.Fprintf(os.Stderr, "acloud-exec-credential: %v\n", err)
+	os.Exit(1)
+}
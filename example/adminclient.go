@@ -11,16 +11,16 @@ import (
 )
 
 func RunAdminExample() {
-	client := acloudapi.NewAdminClient(
-		acloudapi.NewPersonalAccessTokenAuthenticator(os.Getenv("ACLOUD_PAT")),
-		acloudapi.ClientOpts{
-			Debug:                        false,
-			Trace:                        false,
-			DebugShowAuthorizationHeader: false,
-			APIUrl:                       os.Getenv("ACLOUD_URL"),
-			UserAgent:                    "example",
+	config, err := acloudapi.LoadClientConfig(acloudapi.LoadClientConfigOpts{
+		ClientOpts: acloudapi.ClientOpts{
+			UserAgent: "example",
 		},
-	)
+	})
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "failed to load client config: %v", err)
+		os.Exit(1)
+	}
+	client := acloudapi.NewAdminClient(config.Authenticator, config.ClientOpts)
 
 	organisationIdentity := "ame"
 	organisation, err := client.GetOrganisation(context.Background(), organisationIdentity)
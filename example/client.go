@@ -11,16 +11,16 @@ import (
 )
 
 func RunExample() {
-	client := acloudapi.NewClient(
-		acloudapi.NewPersonalAccessTokenAuthenticator(os.Getenv("ACLOUD_PAT")),
-		acloudapi.ClientOpts{
-			Debug:                        false,
-			Trace:                        false,
-			DebugShowAuthorizationHeader: false,
-			APIUrl:                       os.Getenv("ACLOUD_URL"),
-			UserAgent:                    "example",
+	config, err := acloudapi.LoadClientConfig(acloudapi.LoadClientConfigOpts{
+		ClientOpts: acloudapi.ClientOpts{
+			UserAgent: "example",
 		},
-	)
+	})
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "failed to load client config: %v", err)
+		os.Exit(1)
+	}
+	client := acloudapi.NewClient(config.Authenticator, config.ClientOpts)
 
 	organisationSlug := "ame"
 	cloudAccounts, err := client.GetCloudAccounts(context.Background(), organisationSlug)
@@ -4,6 +4,15 @@ go 1.23.0
 
 toolchain go1.26.6
 
-require github.com/go-resty/resty/v2 v2.17.2
+require (
+	github.com/go-resty/resty/v2 v2.17.2
+	golang.org/x/time v0.12.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
-require golang.org/x/net v0.43.0 // indirect
+require (
+	github.com/kr/pretty v0.3.1 // indirect
+	github.com/rogpeppe/go-internal v1.13.1 // indirect
+	golang.org/x/net v0.43.0 // indirect
+	gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c // indirect
+)
@@ -0,0 +1,350 @@
+package acloudapitest
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/avisi-cloud/go-client/pkg/acloudapi"
+)
+
+func (s *Server) registerAdminAPI() {
+	s.handle("GET /admin/v1/clusters", s.adminListClusters)
+	s.handle("GET /admin/v1/clusters/{identity}", s.adminGetCluster)
+	s.handle("PUT /admin/v1/clusters/{identity}", s.adminUpdateCluster)
+	s.handle("GET /admin/v1/orgs/{org}", s.adminGetOrganisation)
+
+	s.handle("GET /admin/v1/scheduled-cluster-upgrades", s.listScheduledClusterUpgrades)
+	s.handle("POST /admin/v1/scheduled-cluster-upgrades", s.createScheduledClusterUpgrade)
+	s.handle("GET /admin/v1/scheduled-cluster-upgrades/{identity}", s.getScheduledClusterUpgrade)
+	s.handle("PATCH /admin/v1/scheduled-cluster-upgrades/{identity}", s.updateScheduledClusterUpgrade)
+	s.handle("DELETE /admin/v1/scheduled-cluster-upgrades/{identity}", s.cancelScheduledClusterUpgrade)
+
+	s.handle("GET /admin/v1/update-channels", s.adminListUpdateChannels)
+
+	s.handle("GET "+acloudapi.ClusterVersionsURL, s.listClusterVersions(func(v *acloudapi.AdminClusterVersion) bool {
+		return v.DeletedAt == nil
+	}))
+	s.handle("GET "+acloudapi.ClusterVersionsURL+"/available", s.listClusterVersions(func(v *acloudapi.AdminClusterVersion) bool {
+		return v.DeletedAt == nil && v.Available
+	}))
+	s.handle("GET "+acloudapi.ClusterVersionsURL+"/history", s.listClusterVersions(func(v *acloudapi.AdminClusterVersion) bool {
+		return true
+	}))
+	s.handle("POST "+acloudapi.ClusterVersionsURL, s.createClusterVersion)
+	s.handle("GET "+acloudapi.ClusterVersionsURL+"/{version}", s.getClusterVersion)
+	s.handle("PUT "+acloudapi.ClusterVersionsURL+"/{version}", s.updateClusterVersion)
+	s.handle("DELETE "+acloudapi.ClusterVersionsURL+"/{version}", s.deleteClusterVersion)
+}
+
+func (s *Server) adminListClusters(w http.ResponseWriter, r *http.Request) {
+	clusters := []acloudapi.Cluster{}
+	for _, organisation := range s.organisations {
+		for _, cluster := range organisation.clusters {
+			s.observeCluster(cluster)
+			clusters = append(clusters, cluster.cluster)
+		}
+	}
+	acloudapi.SortClusters(clusters)
+	writePage(w, r, s.opts.PageSize, clusters)
+}
+
+// lookupClusterByIdentity returns the cluster of the request, writing a not found response if it does not exist
+func (s *Server) lookupClusterByIdentity(w http.ResponseWriter, r *http.Request) *clusterState {
+	_, cluster := s.clusterByIdentity(r.PathValue("identity"))
+	if cluster == nil {
+		writeNotFound(w, "cluster", r.PathValue("identity"))
+	}
+	return cluster
+}
+
+func (s *Server) adminGetCluster(w http.ResponseWriter, r *http.Request) {
+	if cluster := s.lookupClusterByIdentity(w, r); cluster != nil {
+		s.observeCluster(cluster)
+		writeJSON(w, http.StatusOK, cluster.cluster)
+	}
+}
+
+func (s *Server) adminUpdateCluster(w http.ResponseWriter, r *http.Request) {
+	cluster := s.lookupClusterByIdentity(w, r)
+	if cluster == nil {
+		return
+	}
+	update := acloudapi.AdminUpdateClusterRequest{}
+	if !readJSON(w, r, &update) {
+		return
+	}
+	if update.Version == "" {
+		writeFieldError(w, "version", "must not be empty")
+		return
+	}
+	if update.Version != cluster.cluster.Version {
+		s.transitionCluster(cluster, acloudapi.ClusterStatusUpgrading, acloudapi.ClusterStatusRunning, func(c *acloudapi.Cluster) {
+			c.Version = update.Version
+		})
+	}
+	writeJSON(w, http.StatusOK, cluster.cluster)
+}
+
+// adminGetOrganisation returns an organisation by its ID or slug
+func (s *Server) adminGetOrganisation(w http.ResponseWriter, r *http.Request) {
+	org := r.PathValue("org")
+	for _, organisation := range s.organisations {
+		o := organisation.organisation
+		if o.ID == org || o.Slug == org {
+			writeJSON(w, http.StatusOK, acloudapi.AdminOrganisation{
+				ID:                                  o.ID,
+				Name:                                o.Name,
+				VatCode:                             o.VatCode,
+				ContactEmail:                        o.ContactEmail,
+				BillingEmail:                        o.BillingEmail,
+				VatCodeValidated:                    o.VatCodeValidated,
+				VatCodeValidatedAt:                  o.VatCodeValidatedAt,
+				PhoneNumber:                         o.PhoneNumber,
+				CreatedAt:                           o.CreatedAt,
+				AcceptedTerms:                       o.AcceptedTerms,
+				AcceptedTermsAt:                     o.AcceptedTermsAt,
+				RestrictedToAvailableCloudProviders: o.RestrictedToAvailableCloudProviders,
+				Slug:                                o.Slug,
+			})
+			return
+		}
+	}
+	writeNotFound(w, "organisation", org)
+}
+
+// splitQuery returns the comma separated values of a query parameter
+func splitQuery(r *http.Request, name string) []string {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+func (s *Server) listScheduledClusterUpgrades(w http.ResponseWriter, r *http.Request) {
+	clusterIdentities := splitQuery(r, "clusterIdentities")
+	statuses := splitQuery(r, "statuses")
+	upgrades := []acloudapi.ScheduledClusterUpgrade{}
+	for _, upgrade := range s.scheduledClusterUpgrades {
+		observe(s, &upgrade.transitions, &upgrade.upgrade)
+		if clusterIdentities != nil && !slices.Contains(clusterIdentities, upgrade.upgrade.ClusterIdentity) {
+			continue
+		}
+		if statuses != nil && !slices.Contains(statuses, string(upgrade.upgrade.Status)) {
+			continue
+		}
+		upgrades = append(upgrades, upgrade.upgrade)
+	}
+	writePage(w, r, s.opts.PageSize, upgrades)
+}
+
+// lookupScheduledClusterUpgrade returns the scheduled cluster upgrade of the request, writing a not found response if
+// it does not exist
+func (s *Server) lookupScheduledClusterUpgrade(w http.ResponseWriter, r *http.Request) (int, *scheduledClusterUpgradeState) {
+	index, upgrade := s.scheduledClusterUpgrade(r.PathValue("identity"))
+	if upgrade == nil {
+		writeNotFound(w, "scheduled cluster upgrade", r.PathValue("identity"))
+	}
+	return index, upgrade
+}
+
+func (s *Server) getScheduledClusterUpgrade(w http.ResponseWriter, r *http.Request) {
+	if _, upgrade := s.lookupScheduledClusterUpgrade(w, r); upgrade != nil {
+		observe(s, &upgrade.transitions, &upgrade.upgrade)
+		writeJSON(w, http.StatusOK, upgrade.upgrade)
+	}
+}
+
+func (s *Server) createScheduledClusterUpgrade(w http.ResponseWriter, r *http.Request) {
+	create := acloudapi.CreateScheduledClusterUpgradeRequest{}
+	if !readJSON(w, r, &create) {
+		return
+	}
+	_, cluster := s.clusterByIdentity(create.ClusterIdentity)
+	switch {
+	case cluster == nil:
+		writeFieldError(w, "clusterIdentity", fmt.Sprintf("cluster %s does not exist", create.ClusterIdentity))
+		return
+	case create.ToClusterVersion == "":
+		writeFieldError(w, "toClusterVersion", "must not be empty")
+		return
+	case !create.WindowEnd.After(create.WindowStart):
+		writeFieldError(w, "windowEnd", "must be after windowStart")
+		return
+	}
+	now := s.now()
+	upgrade := &scheduledClusterUpgradeState{upgrade: acloudapi.ScheduledClusterUpgrade{
+		Identity:            s.nextIdentity(),
+		ClusterIdentity:     create.ClusterIdentity,
+		CreatedAt:           now,
+		ModifiedAt:          now,
+		WindowStart:         create.WindowStart,
+		WindowEnd:           create.WindowEnd,
+		FromClusterVersion:  cluster.cluster.Version,
+		ToClusterVersion:    create.ToClusterVersion,
+		Status:              acloudapi.Requested,
+		ScheduleRequestDate: now,
+	}}
+	upgrade.transitions = append(upgrade.transitions, func(u *acloudapi.ScheduledClusterUpgrade) {
+		if u.Status == acloudapi.Requested {
+			u.Status = acloudapi.Scheduled
+			u.ModifiedAt = s.now()
+		}
+	})
+	s.scheduledClusterUpgrades = append(s.scheduledClusterUpgrades, upgrade)
+	writeJSON(w, http.StatusCreated, upgrade.upgrade)
+}
+
+func (s *Server) updateScheduledClusterUpgrade(w http.ResponseWriter, r *http.Request) {
+	_, upgrade := s.lookupScheduledClusterUpgrade(w, r)
+	if upgrade == nil {
+		return
+	}
+	update := acloudapi.UpdateScheduledClusterUpgradeRequest{}
+	if !readJSON(w, r, &update) {
+		return
+	}
+	u := &upgrade.upgrade
+	windowStart, windowEnd := u.WindowStart, u.WindowEnd
+	setIfNotNil(&windowStart, update.WindowStart)
+	setIfNotNil(&windowEnd, update.WindowEnd)
+	if !windowEnd.After(windowStart) {
+		writeFieldError(w, "windowEnd", "must be after windowStart")
+		return
+	}
+	u.WindowStart, u.WindowEnd = windowStart, windowEnd
+	if update.Status != "" {
+		u.Status = update.Status
+	}
+	if update.Reason != "" {
+		u.Reason = update.Reason
+	}
+	if update.Version != "" {
+		u.ToClusterVersion = update.Version
+	}
+	u.ModifiedAt = s.now()
+	writeJSON(w, http.StatusOK, upgrade.upgrade)
+}
+
+func (s *Server) cancelScheduledClusterUpgrade(w http.ResponseWriter, r *http.Request) {
+	index, upgrade := s.lookupScheduledClusterUpgrade(w, r)
+	if upgrade == nil {
+		return
+	}
+	switch upgrade.upgrade.Status {
+	case acloudapi.Requested, acloudapi.Scheduled, acloudapi.ScheduledNotified:
+	default:
+		writeError(w, http.StatusConflict, fmt.Sprintf("scheduled cluster upgrade with status %s cannot be cancelled", upgrade.upgrade.Status))
+		return
+	}
+	s.scheduledClusterUpgrades = slices.Delete(s.scheduledClusterUpgrades, index, index+1)
+	writeJSON(w, http.StatusOK, upgrade.upgrade)
+}
+
+func (s *Server) adminListUpdateChannels(w http.ResponseWriter, r *http.Request) {
+	writePage(w, r, s.opts.PageSize, s.updateChannels)
+}
+
+// clusterVersionResponse returns a cluster version including the computed number of clusters using it
+func (s *Server) clusterVersionResponse(clusterVersion *acloudapi.AdminClusterVersion) acloudapi.AdminClusterVersion {
+	response := *clusterVersion
+	response.ClusterCount = 0
+	for _, organisation := range s.organisations {
+		for _, cluster := range organisation.clusters {
+			if cluster.cluster.Version == clusterVersion.Version {
+				response.ClusterCount++
+			}
+		}
+	}
+	return response
+}
+
+func (s *Server) listClusterVersions(include func(v *acloudapi.AdminClusterVersion) bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		clusterVersions := []acloudapi.AdminClusterVersion{}
+		for _, clusterVersion := range s.clusterVersions {
+			if include(clusterVersion) {
+				clusterVersions = append(clusterVersions, s.clusterVersionResponse(clusterVersion))
+			}
+		}
+		writePage(w, r, s.opts.PageSize, clusterVersions)
+	}
+}
+
+// lookupClusterVersion returns the cluster version of the request, writing a not found response if it does not exist
+func (s *Server) lookupClusterVersion(w http.ResponseWriter, r *http.Request) *acloudapi.AdminClusterVersion {
+	clusterVersion := s.clusterVersion(r.PathValue("version"))
+	if clusterVersion == nil || clusterVersion.DeletedAt != nil {
+		writeNotFound(w, "cluster version", r.PathValue("version"))
+		return nil
+	}
+	return clusterVersion
+}
+
+func (s *Server) getClusterVersion(w http.ResponseWriter, r *http.Request) {
+	if clusterVersion := s.lookupClusterVersion(w, r); clusterVersion != nil {
+		writeJSON(w, http.StatusOK, s.clusterVersionResponse(clusterVersion))
+	}
+}
+
+func (s *Server) createClusterVersion(w http.ResponseWriter, r *http.Request) {
+	create := acloudapi.AdminCreateClusterVersionRequest{}
+	if !readJSON(w, r, &create) {
+		return
+	}
+	switch {
+	case create.Version == "":
+		writeFieldError(w, "version", "must not be empty")
+		return
+	case create.KubernetesVersion == "":
+		writeFieldError(w, "kubernetesVersion", "must not be empty")
+		return
+	case s.clusterVersion(create.Version) != nil:
+		writeError(w, http.StatusConflict, fmt.Sprintf("cluster version %s already exists", create.Version))
+		return
+	}
+	now := s.now()
+	clusterVersion := &acloudapi.AdminClusterVersion{
+		Version:                  create.Version,
+		KubernetesVersion:        create.KubernetesVersion,
+		ClusterControllerVersion: create.ClusterControllerVersion,
+		AddonControllerVersion:   create.AddonControllerVersion,
+		Available:                create.Available,
+		CreatedAt:                now,
+		ModifiedAt:               now,
+		Note:                     create.Note,
+	}
+	s.clusterVersions = append(s.clusterVersions, clusterVersion)
+	writeJSON(w, http.StatusCreated, s.clusterVersionResponse(clusterVersion))
+}
+
+func (s *Server) updateClusterVersion(w http.ResponseWriter, r *http.Request) {
+	clusterVersion := s.lookupClusterVersion(w, r)
+	if clusterVersion == nil {
+		return
+	}
+	update := acloudapi.AdminUpdateClusterVersionRequest{}
+	if !readJSON(w, r, &update) {
+		return
+	}
+	clusterVersion.Available = update.Available
+	clusterVersion.ModifiedAt = s.now()
+	writeJSON(w, http.StatusOK, s.clusterVersionResponse(clusterVersion))
+}
+
+func (s *Server) deleteClusterVersion(w http.ResponseWriter, r *http.Request) {
+	clusterVersion := s.lookupClusterVersion(w, r)
+	if clusterVersion == nil {
+		return
+	}
+	if clusterCount := s.clusterVersionResponse(clusterVersion).ClusterCount; clusterCount > 0 {
+		writeError(w, http.StatusConflict, fmt.Sprintf("cluster version %s is used by %d clusters", clusterVersion.Version, clusterCount))
+		return
+	}
+	deletedAt := s.now()
+	clusterVersion.DeletedAt = &deletedAt
+	clusterVersion.Available = false
+	w.WriteHeader(http.StatusNoContent)
+}
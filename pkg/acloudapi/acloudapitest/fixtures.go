@@ -0,0 +1,511 @@
+package acloudapitest
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/avisi-cloud/go-client/pkg/acloudapi"
+)
+
+// The Add methods seed the state of the fake API server. Missing identities, IDs, slugs and timestamps are generated,
+// and the stored resource is returned. They panic when a parent resource does not exist or a resource already exists.
+
+// AddOrganisation adds an organisation, of which the user of the API is a member
+func (s *Server) AddOrganisation(organisation acloudapi.Organisation) acloudapi.Organisation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if organisation.Slug == "" {
+		organisation.Slug = slugify(organisation.Name)
+	}
+	if s.organisation(organisation.Slug) != nil {
+		panic(fmt.Sprintf("acloudapitest: organisation %q already exists", organisation.Slug))
+	}
+	if organisation.ID == "" {
+		organisation.ID = s.nextIdentity()
+	}
+	if organisation.Name == "" {
+		organisation.Name = organisation.Slug
+	}
+	if organisation.CreatedAt.IsZero() {
+		organisation.CreatedAt = s.now()
+	}
+	s.organisations = append(s.organisations, &organisationState{organisation: organisation})
+	return organisation
+}
+
+// AddEnvironment adds an environment to an organisation
+func (s *Server) AddEnvironment(org string, environment acloudapi.Environment) acloudapi.Environment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	organisation := s.mustOrganisation(org)
+	if environment.Slug == "" {
+		environment.Slug = slugify(environment.Name)
+	}
+	if organisation.environment(environment.Slug) != nil {
+		panic(fmt.Sprintf("acloudapitest: environment %s/%s already exists", org, environment.Slug))
+	}
+	if environment.ID == 0 {
+		environment.ID = s.nextID()
+	}
+	if environment.Name == "" {
+		environment.Name = environment.Slug
+	}
+	environment.OrganisationSlug = org
+	if environment.CreatedAt.IsZero() {
+		environment.CreatedAt = s.now()
+		environment.ModifiedAt = environment.CreatedAt
+	}
+	organisation.environments = append(organisation.environments, &environment)
+	return environment
+}
+
+// AddCluster adds a cluster to the environment EnvironmentSlug of organisation CustomerSlug. Unless set,
+// the cluster is running and fully provisioned.
+func (s *Server) AddCluster(cluster acloudapi.Cluster) acloudapi.Cluster {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	organisation := s.mustOrganisation(cluster.CustomerSlug)
+	environment := organisation.environment(cluster.EnvironmentSlug)
+	if environment == nil {
+		panic(fmt.Sprintf("acloudapitest: environment %s/%s does not exist", cluster.CustomerSlug, cluster.EnvironmentSlug))
+	}
+	if cluster.Slug == "" {
+		cluster.Slug = slugify(cluster.Name)
+	}
+	if organisation.cluster(cluster.EnvironmentSlug, cluster.Slug) != nil {
+		panic(fmt.Sprintf("acloudapitest: cluster %s already exists", cluster.Identifier()))
+	}
+	if cluster.Identity == "" {
+		cluster.Identity = s.nextIdentity()
+	}
+	if cluster.Name == "" {
+		cluster.Name = cluster.Slug
+	}
+	cluster.CustomerIdentity = organisation.organisation.ID
+	cluster.EnvironmentIdentity = strconv.Itoa(environment.ID)
+	if cluster.Status == "" {
+		cluster.Status = acloudapi.ClusterStatusRunning
+	}
+	if cluster.DesiredStatus == "" {
+		cluster.DesiredStatus = acloudapi.ClusterStatusRunning
+	}
+	if cluster.ProvisionStatus == "" {
+		cluster.ProvisionStatus = acloudapi.DONE
+	}
+	if cluster.CreatedAt.IsZero() {
+		cluster.CreatedAt = s.now()
+		cluster.ModifiedAt = cluster.CreatedAt
+	}
+	organisation.clusters = append(organisation.clusters, &clusterState{cluster: cluster})
+	return cluster
+}
+
+// AddNodePool adds a node pool to a cluster. Unless set, the node pool is provisioned.
+func (s *Server) AddNodePool(clusterIdentity string, nodePool acloudapi.NodePool) acloudapi.NodePool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, cluster := s.clusterByIdentity(clusterIdentity)
+	if cluster == nil {
+		panic(fmt.Sprintf("acloudapitest: cluster %q does not exist", clusterIdentity))
+	}
+	if cluster.nodePoolByName(nodePool.Name) != nil {
+		panic(fmt.Sprintf("acloudapitest: node pool %q already exists in cluster %s", nodePool.Name, cluster.cluster.Identifier()))
+	}
+	if nodePool.ID == 0 {
+		nodePool.ID = s.nextID()
+	}
+	if nodePool.Identity == "" {
+		nodePool.Identity = s.nextIdentity()
+	}
+	if nodePool.ProvisionStatus == "" {
+		nodePool.ProvisionStatus = acloudapi.NodePoolStatusProvisioned
+	}
+	if nodePool.CreatedAt.IsZero() {
+		nodePool.CreatedAt = s.now()
+		nodePool.ModifiedAt = nodePool.CreatedAt
+	}
+	nodePool.ClusterIdentity = clusterIdentity
+	nodePool.Cluster = acloudapi.Cluster{}
+	cluster.nodePools = append(cluster.nodePools, &nodePoolState{nodePool: nodePool})
+	return nodePool
+}
+
+// AddCloudProfile adds a cloud profile that can be used by the cloud accounts of an organisation
+func (s *Server) AddCloudProfile(org string, cloudProfile acloudapi.CloudProfile) acloudapi.CloudProfile {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	organisation := s.mustOrganisation(org)
+	if cloudProfile.Identity == "" {
+		cloudProfile.Identity = s.nextIdentity()
+	}
+	organisation.cloudProfiles = append(organisation.cloudProfiles, cloudProfile)
+	return cloudProfile
+}
+
+// AddCloudAccount adds a cloud account to an organisation
+func (s *Server) AddCloudAccount(org string, cloudAccount acloudapi.CloudAccount) acloudapi.CloudAccount {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	organisation := s.mustOrganisation(org)
+	if cloudAccount.Identity == "" {
+		cloudAccount.Identity = s.nextIdentity()
+	}
+	organisation.cloudAccounts = append(organisation.cloudAccounts, &cloudAccountState{cloudAccount: cloudAccount})
+	return cloudAccount
+}
+
+// AddCloudProvider adds a cloud provider, available to all organisations
+func (s *Server) AddCloudProvider(cloudProvider acloudapi.CloudProvider) acloudapi.CloudProvider {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cloudProvider(cloudProvider.Slug) != nil {
+		panic(fmt.Sprintf("acloudapitest: cloud provider %q already exists", cloudProvider.Slug))
+	}
+	if cloudProvider.ID == 0 {
+		cloudProvider.ID = s.nextID()
+	}
+	if cloudProvider.Name == "" {
+		cloudProvider.Name = cloudProvider.Slug
+	}
+	s.cloudProviders = append(s.cloudProviders, &cloudProviderState{cloudProvider: cloudProvider})
+	return cloudProvider
+}
+
+// AddRegion adds a region to a cloud provider
+func (s *Server) AddRegion(cloudProviderSlug string, region acloudapi.Region) acloudapi.Region {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cloudProvider := s.mustCloudProvider(cloudProviderSlug)
+	if region.ID == 0 {
+		region.ID = s.nextID()
+	}
+	if region.Name == "" {
+		region.Name = region.Slug
+	}
+	region.Provider = cloudProviderSlug
+	cloudProvider.regions = append(cloudProvider.regions, &regionState{region: region})
+	return region
+}
+
+// AddAvailabilityZone adds an availability zone to a region of a cloud provider
+func (s *Server) AddAvailabilityZone(cloudProviderSlug, regionSlug string, availabilityZone acloudapi.AvailabilityZone) acloudapi.AvailabilityZone {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	region := s.mustCloudProvider(cloudProviderSlug).region(regionSlug)
+	if region == nil {
+		panic(fmt.Sprintf("acloudapitest: region %s/%s does not exist", cloudProviderSlug, regionSlug))
+	}
+	if availabilityZone.ID == 0 {
+		availabilityZone.ID = s.nextID()
+	}
+	if availabilityZone.Name == "" {
+		availabilityZone.Name = availabilityZone.Slug
+	}
+	region.availabilityZones = append(region.availabilityZones, availabilityZone)
+	return availabilityZone
+}
+
+// AddNodeType adds a node type to a cloud provider
+func (s *Server) AddNodeType(cloudProviderSlug string, nodeType acloudapi.NodeType) acloudapi.NodeType {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cloudProvider := s.mustCloudProvider(cloudProviderSlug)
+	cloudProvider.nodeTypes = append(cloudProvider.nodeTypes, nodeType)
+	return nodeType
+}
+
+// AddUpdateChannel adds an update channel, available to all organisations
+func (s *Server) AddUpdateChannel(updateChannel acloudapi.UpdateChannelResponse) acloudapi.UpdateChannelResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.updateChannel(updateChannel.Name) != nil {
+		panic(fmt.Sprintf("acloudapitest: update channel %q already exists", updateChannel.Name))
+	}
+	s.updateChannels = append(s.updateChannels, updateChannel)
+	return updateChannel
+}
+
+// AddClusterVersion adds a cluster version
+func (s *Server) AddClusterVersion(clusterVersion acloudapi.AdminClusterVersion) acloudapi.AdminClusterVersion {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.clusterVersion(clusterVersion.Version) != nil {
+		panic(fmt.Sprintf("acloudapitest: cluster version %q already exists", clusterVersion.Version))
+	}
+	if clusterVersion.CreatedAt.IsZero() {
+		clusterVersion.CreatedAt = s.now()
+		clusterVersion.ModifiedAt = clusterVersion.CreatedAt
+	}
+	s.clusterVersions = append(s.clusterVersions, &clusterVersion)
+	return clusterVersion
+}
+
+// AddMaintenanceSchedule adds a maintenance schedule to an organisation
+func (s *Server) AddMaintenanceSchedule(org string, maintenanceSchedule acloudapi.MaintenanceSchedule) acloudapi.MaintenanceSchedule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	organisation := s.mustOrganisation(org)
+	if maintenanceSchedule.Identity == "" {
+		maintenanceSchedule.Identity = s.nextIdentity()
+	}
+	organisation.maintenanceSchedules = append(organisation.maintenanceSchedules, &maintenanceSchedule)
+	return maintenanceSchedule
+}
+
+// AddObservabilityTenant adds an observability tenant to an organisation
+func (s *Server) AddObservabilityTenant(org string, tenant acloudapi.ObservabilityTenant) acloudapi.ObservabilityTenant {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	organisation := s.mustOrganisation(org)
+	if tenant.Slug == "" {
+		tenant.Slug = slugify(tenant.Name)
+	}
+	if organisation.observabilityTenant(tenant.Slug) != nil {
+		panic(fmt.Sprintf("acloudapitest: observability tenant %s/%s already exists", org, tenant.Slug))
+	}
+	if tenant.Identity == "" {
+		tenant.Identity = s.nextIdentity()
+	}
+	if tenant.Name == "" {
+		tenant.Name = tenant.Slug
+	}
+	tenant.CustomerSlug = org
+	if tenant.CreatedAt.IsZero() {
+		tenant.CreatedAt = s.now()
+		tenant.ModifiedAt = tenant.CreatedAt
+	}
+	organisation.observabilityTenants = append(organisation.observabilityTenants, &observabilityTenantState{
+		tenant: tenant,
+		alertmanager: acloudapi.ObservabilityAlertmanagerAndPrometheusrulesResponse{
+			Rules:     map[string]string{},
+			Templates: map[string]string{},
+		},
+	})
+	return tenant
+}
+
+// AddSilence adds a silence to an observability tenant
+func (s *Server) AddSilence(org, tenantSlug string, silence acloudapi.Silence) acloudapi.Silence {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tenant := s.mustOrganisation(org).observabilityTenant(tenantSlug)
+	if tenant == nil {
+		panic(fmt.Sprintf("acloudapitest: observability tenant %s/%s does not exist", org, tenantSlug))
+	}
+	if silence.Id == "" {
+		silence.Id = s.nextIdentity()
+	}
+	if silence.Status.State == "" {
+		silence.Status.State = s.silenceState(silence)
+	}
+	tenant.silences = append(tenant.silences, &silence)
+	return silence
+}
+
+// AddScheduledClusterUpgrade adds a scheduled upgrade of a cluster
+func (s *Server) AddScheduledClusterUpgrade(upgrade acloudapi.ScheduledClusterUpgrade) acloudapi.ScheduledClusterUpgrade {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if upgrade.Identity == "" {
+		upgrade.Identity = s.nextIdentity()
+	}
+	if upgrade.Status == "" {
+		upgrade.Status = acloudapi.Scheduled
+	}
+	if upgrade.CreatedAt.IsZero() {
+		upgrade.CreatedAt = s.now()
+		upgrade.ModifiedAt = upgrade.CreatedAt
+	}
+	s.scheduledClusterUpgrades = append(s.scheduledClusterUpgrades, &scheduledClusterUpgradeState{upgrade: upgrade})
+	return upgrade
+}
+
+// SetClusterOIDCConfig sets the OIDC configuration returned for a cluster. By default a configuration is generated.
+func (s *Server) SetClusterOIDCConfig(clusterIdentity string, config acloudapi.ClusterMetadataResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, cluster := s.clusterByIdentity(clusterIdentity)
+	if cluster == nil {
+		panic(fmt.Sprintf("acloudapitest: cluster %q does not exist", clusterIdentity))
+	}
+	cluster.oidcConfig = &config
+}
+
+// Cluster returns the current state of a cluster, without advancing its status transitions
+func (s *Server) Cluster(clusterIdentity string) (acloudapi.Cluster, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, cluster := s.clusterByIdentity(clusterIdentity)
+	if cluster == nil {
+		return acloudapi.Cluster{}, false
+	}
+	return cluster.cluster, true
+}
+
+// NodePools returns the current state of the node pools of a cluster, without advancing their status transitions
+func (s *Server) NodePools(clusterIdentity string) []acloudapi.NodePool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, cluster := s.clusterByIdentity(clusterIdentity)
+	if cluster == nil {
+		return nil
+	}
+	nodePools := make([]acloudapi.NodePool, len(cluster.nodePools))
+	for i, nodePool := range cluster.nodePools {
+		nodePools[i] = nodePool.nodePool
+	}
+	return nodePools
+}
+
+func (s *Server) organisation(org string) *organisationState {
+	for _, organisation := range s.organisations {
+		if organisation.organisation.Slug == org {
+			return organisation
+		}
+	}
+	return nil
+}
+
+func (s *Server) mustOrganisation(org string) *organisationState {
+	organisation := s.organisation(org)
+	if organisation == nil {
+		panic(fmt.Sprintf("acloudapitest: organisation %q does not exist", org))
+	}
+	return organisation
+}
+
+func (s *Server) clusterByIdentity(identity string) (*organisationState, *clusterState) {
+	for _, organisation := range s.organisations {
+		for _, cluster := range organisation.clusters {
+			if cluster.cluster.Identity == identity {
+				return organisation, cluster
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (s *Server) cloudProvider(slug string) *cloudProviderState {
+	for _, cloudProvider := range s.cloudProviders {
+		if cloudProvider.cloudProvider.Slug == slug {
+			return cloudProvider
+		}
+	}
+	return nil
+}
+
+func (s *Server) mustCloudProvider(slug string) *cloudProviderState {
+	cloudProvider := s.cloudProvider(slug)
+	if cloudProvider == nil {
+		panic(fmt.Sprintf("acloudapitest: cloud provider %q does not exist", slug))
+	}
+	return cloudProvider
+}
+
+func (s *Server) updateChannel(name string) *acloudapi.UpdateChannelResponse {
+	for i := range s.updateChannels {
+		if s.updateChannels[i].Name == name {
+			return &s.updateChannels[i]
+		}
+	}
+	return nil
+}
+
+func (s *Server) clusterVersion(version string) *acloudapi.AdminClusterVersion {
+	for _, clusterVersion := range s.clusterVersions {
+		if clusterVersion.Version == version {
+			return clusterVersion
+		}
+	}
+	return nil
+}
+
+func (s *Server) scheduledClusterUpgrade(identity string) (int, *scheduledClusterUpgradeState) {
+	for i, upgrade := range s.scheduledClusterUpgrades {
+		if upgrade.upgrade.Identity == identity {
+			return i, upgrade
+		}
+	}
+	return -1, nil
+}
+
+func (o *organisationState) environment(slug string) *acloudapi.Environment {
+	for _, environment := range o.environments {
+		if environment.Slug == slug {
+			return environment
+		}
+	}
+	return nil
+}
+
+func (o *organisationState) cluster(env, slug string) *clusterState {
+	for _, cluster := range o.clusters {
+		if cluster.cluster.EnvironmentSlug == env && cluster.cluster.Slug == slug {
+			return cluster
+		}
+	}
+	return nil
+}
+
+func (o *organisationState) cloudAccount(identity string) *cloudAccountState {
+	for _, cloudAccount := range o.cloudAccounts {
+		if cloudAccount.cloudAccount.Identity == identity {
+			return cloudAccount
+		}
+	}
+	return nil
+}
+
+func (o *organisationState) cloudProfile(identity string) *acloudapi.CloudProfile {
+	for i := range o.cloudProfiles {
+		if o.cloudProfiles[i].Identity == identity {
+			return &o.cloudProfiles[i]
+		}
+	}
+	return nil
+}
+
+func (o *organisationState) maintenanceSchedule(identity string) *acloudapi.MaintenanceSchedule {
+	for _, maintenanceSchedule := range o.maintenanceSchedules {
+		if maintenanceSchedule.Identity == identity {
+			return maintenanceSchedule
+		}
+	}
+	return nil
+}
+
+func (o *organisationState) observabilityTenant(slug string) *observabilityTenantState {
+	for _, tenant := range o.observabilityTenants {
+		if tenant.tenant.Slug == slug {
+			return tenant
+		}
+	}
+	return nil
+}
+
+func (c *clusterState) nodePoolByName(name string) *nodePoolState {
+	for _, nodePool := range c.nodePools {
+		if nodePool.nodePool.Name == name {
+			return nodePool
+		}
+	}
+	return nil
+}
+
+func (c *clusterState) nodePoolByID(id int) *nodePoolState {
+	for _, nodePool := range c.nodePools {
+		if nodePool.nodePool.ID == id {
+			return nodePool
+		}
+	}
+	return nil
+}
+
+func (c *cloudProviderState) region(slug string) *regionState {
+	for _, region := range c.regions {
+		if region.region.Slug == slug {
+			return region
+		}
+	}
+	return nil
+}
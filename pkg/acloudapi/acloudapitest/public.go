@@ -0,0 +1,1149 @@
+package acloudapitest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/avisi-cloud/go-client/pkg/acloudapi"
+)
+
+func (s *Server) registerPublicAPI() {
+	s.handle("GET /api/v1/memberships", s.listMemberships)
+	s.handle("GET /api/v1/organisations/{org}", s.getOrganisation)
+
+	s.handle("GET /api/v1/orgs/{org}/environments", s.listEnvironments)
+	s.handle("POST /api/v1/orgs/{org}/environments", s.createEnvironment)
+	s.handle("GET /api/v1/orgs/{org}/environments/{env}", s.getEnvironment)
+	s.handle("PATCH /api/v1/orgs/{org}/environments/{env}", s.updateEnvironment)
+	s.handle("DELETE /api/v1/orgs/{org}/environments/{env}", s.deleteEnvironment)
+
+	s.handle("GET /api/v1/orgs/{org}/clusters", s.listClusters)
+	s.handle("GET /api/v1/orgs/{org}/clusters/{env}", s.listClusters)
+	s.handle("POST /api/v1/orgs/{org}/clusters/{env}", s.createCluster)
+	s.handle("GET /api/v1/orgs/{org}/clusters/{env}/{cluster}", s.getCluster)
+	s.handle("PATCH /api/v1/orgs/{org}/clusters/{env}/{cluster}", s.updateCluster)
+	s.handle("GET /api/v1/orgs/{org}/clusters/{env}/{cluster}/oidc-config", s.getClusterOIDCConfig)
+
+	s.handle("GET /api/v1/orgs/{org}/clusters/{env}/{cluster}/pools", s.listNodePools)
+	s.handle("POST /api/v1/orgs/{org}/clusters/{env}/{cluster}/pools", s.createNodePool)
+	s.handle("PUT /api/v1/orgs/{org}/clusters/{env}/{cluster}/pools/{id}", s.updateNodePool)
+	s.handle("DELETE /api/v1/orgs/{org}/clusters/{env}/{cluster}/pools/{id}", s.deleteNodePool)
+	s.handle("POST /api/v1/orgs/{org}/clusters/{env}/{cluster}/pools/{identity}/join-config", s.getNodePoolJoinConfig)
+
+	s.handle("GET /api/v1/orgs/{org}/cloud-accounts", s.listCloudAccounts)
+	s.handle("POST /api/v1/orgs/{org}/cloud-accounts", s.createCloudAccount)
+	s.handle("PATCH /api/v1/orgs/{org}/cloud-accounts/{identity}", s.updateCloudAccount)
+	s.handle("DELETE /api/v1/orgs/{org}/cloud-accounts/{identity}", s.deleteCloudAccount)
+	s.handle("GET /api/v1/orgs/{org}/cloud-accounts/{identity}/credentials", s.listCloudCredentials)
+	s.handle("POST /api/v1/orgs/{org}/cloud-accounts/{identity}/credentials/{type}", s.createCloudCredential)
+	s.handle("DELETE /api/v1/orgs/{org}/cloud-accounts/{identity}/credentials/{credentials}", s.deleteCloudCredential)
+	s.handle("GET /api/v1/orgs/{org}/cloud-profiles", s.listCloudProfiles)
+
+	s.handle("GET /api/v1/orgs/{org}/cloud-providers", s.listCloudProviders)
+	s.handle("GET /api/v1/orgs/{org}/cloud-providers/{provider}/regions", s.listRegions)
+	s.handle("GET /api/v1/orgs/{org}/cloud-providers/{provider}/regions/{region}/availability-zones", s.listAvailabilityZones)
+	s.handle("GET /api/v1/cloud-providers/{provider}/nodetypes", s.listNodeTypes)
+	s.handle("GET /api/v1/orgs/{org}/update-channels", s.listUpdateChannels)
+	s.handle("GET /api/v1/cluster-versions", s.listPublicClusterVersions)
+
+	s.handle("GET /api/v1/organisations/{org}/maintenance-schedule", s.listMaintenanceSchedules)
+	s.handle("POST /api/v1/organisations/{org}/maintenance-schedule", s.createMaintenanceSchedule)
+	s.handle("GET /api/v1/organisations/{org}/maintenance-schedule/{identity}", s.getMaintenanceSchedule)
+	s.handle("PATCH /api/v1/organisations/{org}/maintenance-schedule/{identity}", s.updateMaintenanceSchedule)
+	s.handle("DELETE /api/v1/organisations/{org}/maintenance-schedule/{identity}", s.deleteMaintenanceSchedule)
+
+	s.handle("GET /api/v1/orgs/{org}/monitoring", s.listObservabilityTenants)
+	s.handle("GET /api/v1/orgs/{org}/monitoring/{tenant}", s.getObservabilityTenant)
+	s.handle("GET /api/v1/orgs/{org}/monitoring/{tenant}/alertmanager", s.getAlertmanager)
+	s.handle("POST /api/v1/orgs/{org}/monitoring/{tenant}/alertmanager", s.updateAlertmanager)
+	s.handle("GET /api/v1/orgs/{org}/alerts", s.listAlerts)
+	s.handle("GET /api/v1/orgs/{org}/alerts/{tenant}", s.listAlerts)
+
+	s.handle("GET /api/v1/orgs/{org}/observability/{tenant}/silences", s.listSilences)
+	s.handle("POST /api/v1/orgs/{org}/observability/{tenant}/silences", s.createSilence)
+	s.handle("DELETE /api/v1/orgs/{org}/observability/{tenant}/silences/{id}", s.expireSilence)
+}
+
+// lookupOrganisation returns the organisation of the request, writing a not found response if it does not exist
+func (s *Server) lookupOrganisation(w http.ResponseWriter, r *http.Request) *organisationState {
+	organisation := s.organisation(r.PathValue("org"))
+	if organisation == nil {
+		writeNotFound(w, "organisation", r.PathValue("org"))
+	}
+	return organisation
+}
+
+// lookupCluster returns the cluster of the request, writing a not found response if it does not exist
+func (s *Server) lookupCluster(w http.ResponseWriter, r *http.Request) (*organisationState, *clusterState) {
+	organisation := s.lookupOrganisation(w, r)
+	if organisation == nil {
+		return nil, nil
+	}
+	cluster := organisation.cluster(r.PathValue("env"), r.PathValue("cluster"))
+	if cluster == nil {
+		writeNotFound(w, "cluster", fmt.Sprintf("%s/%s/%s", r.PathValue("org"), r.PathValue("env"), r.PathValue("cluster")))
+	}
+	return organisation, cluster
+}
+
+func (s *Server) listMemberships(w http.ResponseWriter, r *http.Request) {
+	memberships := make([]acloudapi.Membership, len(s.organisations))
+	for i, organisation := range s.organisations {
+		memberships[i] = acloudapi.Membership{
+			ID:   organisation.organisation.ID,
+			Name: organisation.organisation.Name,
+			Slug: organisation.organisation.Slug,
+		}
+	}
+	writePage(w, r, s.opts.PageSize, memberships)
+}
+
+func (s *Server) getOrganisation(w http.ResponseWriter, r *http.Request) {
+	if organisation := s.lookupOrganisation(w, r); organisation != nil {
+		writeJSON(w, http.StatusOK, organisation.organisation)
+	}
+}
+
+// environmentResponse returns an environment including its computed cluster totals
+func (o *organisationState) environmentResponse(environment *acloudapi.Environment) acloudapi.Environment {
+	response := *environment
+	response.TotalClusters, response.TotalCPU, response.TotalMemory = 0, 0, 0
+	for _, cluster := range o.clusters {
+		if cluster.cluster.EnvironmentSlug == environment.Slug {
+			response.TotalClusters++
+			response.TotalCPU += cluster.cluster.CPU
+			response.TotalMemory += cluster.cluster.Memory
+		}
+	}
+	return response
+}
+
+func (s *Server) listEnvironments(w http.ResponseWriter, r *http.Request) {
+	organisation := s.lookupOrganisation(w, r)
+	if organisation == nil {
+		return
+	}
+	environments := make([]acloudapi.Environment, len(organisation.environments))
+	for i, environment := range organisation.environments {
+		environments[i] = organisation.environmentResponse(environment)
+	}
+	writePage(w, r, s.opts.PageSize, environments)
+}
+
+func (s *Server) getEnvironment(w http.ResponseWriter, r *http.Request) {
+	organisation := s.lookupOrganisation(w, r)
+	if organisation == nil {
+		return
+	}
+	environment := organisation.environment(r.PathValue("env"))
+	if environment == nil {
+		writeNotFound(w, "environment", r.PathValue("env"))
+		return
+	}
+	writeJSON(w, http.StatusOK, organisation.environmentResponse(environment))
+}
+
+func (s *Server) createEnvironment(w http.ResponseWriter, r *http.Request) {
+	organisation := s.lookupOrganisation(w, r)
+	if organisation == nil {
+		return
+	}
+	create := acloudapi.CreateEnvironment{}
+	if !readJSON(w, r, &create) {
+		return
+	}
+	slug := slugify(create.Name)
+	if slug == "" {
+		writeFieldError(w, "name", "must not be empty")
+		return
+	}
+	if organisation.environment(slug) != nil {
+		writeError(w, http.StatusConflict, fmt.Sprintf("environment %s already exists", slug))
+		return
+	}
+	now := s.now()
+	environment := &acloudapi.Environment{
+		ID:               s.nextID(),
+		Name:             create.Name,
+		Purpose:          create.Purpose,
+		Type:             create.Type,
+		Description:      create.Description,
+		CreatedAt:        now,
+		ModifiedAt:       now,
+		Slug:             slug,
+		OrganisationSlug: organisation.organisation.Slug,
+	}
+	organisation.environments = append(organisation.environments, environment)
+	writeJSON(w, http.StatusCreated, organisation.environmentResponse(environment))
+}
+
+func (s *Server) updateEnvironment(w http.ResponseWriter, r *http.Request) {
+	organisation := s.lookupOrganisation(w, r)
+	if organisation == nil {
+		return
+	}
+	environment := organisation.environment(r.PathValue("env"))
+	if environment == nil {
+		writeNotFound(w, "environment", r.PathValue("env"))
+		return
+	}
+	update := acloudapi.UpdateEnvironment{}
+	if !readJSON(w, r, &update) {
+		return
+	}
+	if update.Name != "" {
+		environment.Name = update.Name
+	}
+	if update.Purpose != "" {
+		environment.Purpose = update.Purpose
+	}
+	if update.Type != "" {
+		environment.Type = update.Type
+	}
+	if update.Description != "" {
+		environment.Description = update.Description
+	}
+	environment.ModifiedAt = s.now()
+	writeJSON(w, http.StatusOK, organisation.environmentResponse(environment))
+}
+
+func (s *Server) deleteEnvironment(w http.ResponseWriter, r *http.Request) {
+	organisation := s.lookupOrganisation(w, r)
+	if organisation == nil {
+		return
+	}
+	env := r.PathValue("env")
+	if organisation.environment(env) == nil {
+		writeNotFound(w, "environment", env)
+		return
+	}
+	if organisation.environmentResponse(organisation.environment(env)).TotalClusters > 0 {
+		writeError(w, http.StatusConflict, fmt.Sprintf("environment %s still has clusters", env))
+		return
+	}
+	organisation.environments = slices.DeleteFunc(organisation.environments, func(environment *acloudapi.Environment) bool {
+		return environment.Slug == env
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listClusters(w http.ResponseWriter, r *http.Request) {
+	organisation := s.lookupOrganisation(w, r)
+	if organisation == nil {
+		return
+	}
+	env := r.PathValue("env")
+	if env != "" && organisation.environment(env) == nil {
+		writeNotFound(w, "environment", env)
+		return
+	}
+	clusters := []acloudapi.Cluster{}
+	for _, cluster := range organisation.clusters {
+		if env == "" || cluster.cluster.EnvironmentSlug == env {
+			s.observeCluster(cluster)
+			clusters = append(clusters, cluster.cluster)
+		}
+	}
+	writePage(w, r, s.opts.PageSize, clusters)
+}
+
+func (s *Server) getCluster(w http.ResponseWriter, r *http.Request) {
+	if _, cluster := s.lookupCluster(w, r); cluster != nil {
+		s.observeCluster(cluster)
+		writeJSON(w, http.StatusOK, cluster.cluster)
+	}
+}
+
+func (s *Server) createCluster(w http.ResponseWriter, r *http.Request) {
+	organisation := s.lookupOrganisation(w, r)
+	if organisation == nil {
+		return
+	}
+	environment := organisation.environment(r.PathValue("env"))
+	if environment == nil {
+		writeNotFound(w, "environment", r.PathValue("env"))
+		return
+	}
+	create := acloudapi.CreateCluster{}
+	if !readJSON(w, r, &create) {
+		return
+	}
+	slug := slugify(create.Name)
+	if slug == "" {
+		writeFieldError(w, "name", "must not be empty")
+		return
+	}
+	if organisation.cluster(environment.Slug, slug) != nil {
+		writeError(w, http.StatusConflict, fmt.Sprintf("cluster %s/%s already exists", environment.Slug, slug))
+		return
+	}
+
+	now := s.now()
+	cluster := acloudapi.Cluster{
+		Name:                         create.Name,
+		Identity:                     s.nextIdentity(),
+		EnvironmentIdentity:          strconv.Itoa(environment.ID),
+		EnvironmentSlug:              environment.Slug,
+		CustomerIdentity:             organisation.organisation.ID,
+		CustomerSlug:                 organisation.organisation.Slug,
+		Slug:                         slug,
+		CNI:                          create.CNI,
+		Description:                  create.Description,
+		Region:                       create.Region,
+		Version:                      create.Version,
+		AutoUpgrade:                  create.EnableAutoUpgrade,
+		HighlyAvailable:              create.EnableHighAvailability,
+		EnableNetworkEncryption:      create.EnableNetworkEncryption,
+		PodSecurityStandardsProfile:  create.PodSecurityStandardsProfile,
+		EnableMultiAvailAbilityZones: create.EnableMultiAvailabilityZones,
+		EnableNATGateway:             create.EnableNATGateway,
+		CreatedAt:                    now,
+		ModifiedAt:                   now,
+		Addons:                       create.Addons,
+		AutoscalerSettings:           create.AutoscalerSettings,
+	}
+	if create.CloudAccountIdentity != "" {
+		cloudAccount := organisation.cloudAccount(create.CloudAccountIdentity)
+		if cloudAccount == nil {
+			writeFieldError(w, "cloudAccountIdentity", fmt.Sprintf("cloud account %s does not exist", create.CloudAccountIdentity))
+			return
+		}
+		cluster.CloudAccount = &acloudapi.CloudAccountReference{
+			Identity:    cloudAccount.cloudAccount.Identity,
+			DisplayName: cloudAccount.cloudAccount.DisplayName,
+		}
+		cluster.CloudProvider = cloudAccount.cloudAccount.CloudProfile.CloudProvider
+	}
+	if create.UpdateChannel != "" {
+		updateChannel := s.updateChannel(create.UpdateChannel)
+		if updateChannel == nil {
+			writeFieldError(w, "updateChannel", fmt.Sprintf("update channel %s does not exist", create.UpdateChannel))
+			return
+		}
+		cluster.UpdateChannel = updateChannel
+		if cluster.Version == "" {
+			cluster.Version = updateChannel.KubernetesClusterVersion
+		}
+	}
+	if create.MaintenanceScheduleIdentity != "" {
+		maintenanceSchedule := organisation.maintenanceSchedule(create.MaintenanceScheduleIdentity)
+		if maintenanceSchedule == nil {
+			writeFieldError(w, "maintenanceScheduleIdentity", fmt.Sprintf("maintenance schedule %s does not exist", create.MaintenanceScheduleIdentity))
+			return
+		}
+		cluster.MaintenanceSchedule = maintenanceSchedule
+	}
+	for _, entry := range create.IPWhitelist {
+		cluster.IPWhitelist = append(cluster.IPWhitelist, acloudapi.IpWhitelistResponse{Cidr: entry.Cidr, Description: entry.Description})
+	}
+
+	state := &clusterState{cluster: cluster}
+	for _, create := range create.NodePools {
+		nodePool := &nodePoolState{nodePool: acloudapi.NodePool{
+			ID:                    s.nextID(),
+			Identity:              s.nextIdentity(),
+			Name:                  create.Name,
+			AvailabilityZone:      create.AvailabilityZone,
+			NodeSize:              create.NodeSize,
+			AutoScaling:           create.AutoScaling,
+			MinSize:               create.MinSize,
+			MaxSize:               create.MaxSize,
+			UpgradeStrategy:       create.UpgradeStrategy,
+			SecurityUpdatesOnJoin: securityUpdatesOnJoinOrDefault(create.SecurityUpdatesOnJoin),
+			CreatedAt:             now,
+			ModifiedAt:            now,
+			ClusterIdentity:       cluster.Identity,
+		}}
+		s.transitionNodePool(nodePool, acloudapi.NodePoolStatusProvisioning, acloudapi.NodePoolStatusProvisioned)
+		state.nodePools = append(state.nodePools, nodePool)
+	}
+	s.provisionCluster(state)
+	organisation.clusters = append(organisation.clusters, state)
+	writeJSON(w, http.StatusCreated, state.cluster)
+}
+
+func (s *Server) updateCluster(w http.ResponseWriter, r *http.Request) {
+	organisation, cluster := s.lookupCluster(w, r)
+	if cluster == nil {
+		return
+	}
+	update := acloudapi.UpdateCluster{}
+	if !readJSON(w, r, &update) {
+		return
+	}
+
+	// validate the update before applying it, so a rejected update does not change the cluster
+	maintenanceSchedule := cluster.cluster.MaintenanceSchedule
+	if update.MaintenanceScheduleIdentity != nil {
+		maintenanceSchedule = nil
+		if *update.MaintenanceScheduleIdentity != "" {
+			maintenanceSchedule = organisation.maintenanceSchedule(*update.MaintenanceScheduleIdentity)
+			if maintenanceSchedule == nil {
+				writeFieldError(w, "maintenanceScheduleIdentity", fmt.Sprintf("maintenance schedule %s does not exist", *update.MaintenanceScheduleIdentity))
+				return
+			}
+		}
+	}
+	updateChannel := cluster.cluster.UpdateChannel
+	if update.UpdateChannel != nil {
+		updateChannel = s.updateChannel(*update.UpdateChannel)
+		if updateChannel == nil {
+			writeFieldError(w, "updateChannel", fmt.Sprintf("update channel %s does not exist", *update.UpdateChannel))
+			return
+		}
+	}
+	deleteProtection := cluster.cluster.DeleteProtection
+	setIfNotNil(&deleteProtection, update.DeleteProtection)
+	if update.Status != nil {
+		switch *update.Status {
+		case acloudapi.ClusterStatusDeleted:
+			if deleteProtection {
+				writeError(w, http.StatusConflict, fmt.Sprintf("cluster %s has delete protection enabled", cluster.cluster.Identifier()))
+				return
+			}
+		case acloudapi.ClusterStatusStopped, acloudapi.ClusterStatusRunning, "started":
+		default:
+			writeFieldError(w, "status", fmt.Sprintf("unsupported status %s", *update.Status))
+			return
+		}
+	}
+
+	cluster.cluster.MaintenanceSchedule = maintenanceSchedule
+	cluster.cluster.UpdateChannel = updateChannel
+	cluster.cluster.DeleteProtection = deleteProtection
+	if update.Status != nil {
+		switch *update.Status {
+		case acloudapi.ClusterStatusDeleted:
+			s.transitionCluster(cluster, acloudapi.ClusterStatusDeleting, acloudapi.ClusterStatusDeleted, func(c *acloudapi.Cluster) {
+				deletedAt := s.now()
+				c.DeletedAt = &deletedAt
+			})
+		case acloudapi.ClusterStatusStopped:
+			if cluster.cluster.Status != acloudapi.ClusterStatusStopped {
+				s.transitionCluster(cluster, acloudapi.ClusterStatusStopping, acloudapi.ClusterStatusStopped, nil)
+			}
+		default:
+			if cluster.cluster.Status == acloudapi.ClusterStatusStopped {
+				s.transitionCluster(cluster, acloudapi.ClusterStatusStarting, acloudapi.ClusterStatusRunning, nil)
+			}
+		}
+	}
+	if update.Version != nil && *update.Version != cluster.cluster.Version {
+		version := *update.Version
+		s.transitionCluster(cluster, acloudapi.ClusterStatusUpgrading, acloudapi.ClusterStatusRunning, func(c *acloudapi.Cluster) {
+			c.Version = version
+		})
+	}
+
+	c := &cluster.cluster
+	setIfNotNil(&c.CNI, update.CNI)
+	setIfNotNil(&c.EnableNetworkEncryption, update.EnableNetworkEncryption)
+	setIfNotNil(&c.AutoUpgrade, update.EnableAutoUpgrade)
+	setIfNotNil(&c.HighlyAvailable, update.EnableHighAvailability)
+	setIfNotNil(&c.EnablePodSecurityStandards, update.EnablePodSecurityStandards)
+	setIfNotNil(&c.PodSecurityStandardsProfile, update.PodSecurityStandardsProfile)
+	if update.IPWhitelistEntries != nil {
+		ipWhitelist := make([]acloudapi.IpWhitelistResponse, len(update.IPWhitelistEntries))
+		for i, entry := range update.IPWhitelistEntries {
+			ipWhitelist[i] = acloudapi.IpWhitelistResponse{Cidr: entry.Cidr, Description: entry.Description}
+		}
+		c.IPWhitelist = ipWhitelist
+	} else if update.IPWhitelist != nil {
+		// like the API, a whitelist of bare CIDRs has no descriptions
+		ipWhitelist := make([]acloudapi.IpWhitelistResponse, len(update.IPWhitelist))
+		for i, cidr := range update.IPWhitelist {
+			ipWhitelist[i] = acloudapi.IpWhitelistResponse{Cidr: cidr}
+		}
+		c.IPWhitelist = ipWhitelist
+	}
+	for key, addon := range update.Addons {
+		if c.Addons == nil {
+			c.Addons = map[string]acloudapi.APIAddon{}
+		}
+		c.Addons[key] = addon
+	}
+	if update.AutoscalerSettings != nil {
+		c.AutoscalerSettings = update.AutoscalerSettings
+	}
+	c.ModifiedAt = s.now()
+	writeJSON(w, http.StatusOK, cluster.cluster)
+}
+
+func setIfNotNil[T any](field *T, value *T) {
+	if value != nil {
+		*field = *value
+	}
+}
+
+func (s *Server) getClusterOIDCConfig(w http.ResponseWriter, r *http.Request) {
+	_, cluster := s.lookupCluster(w, r)
+	if cluster == nil {
+		return
+	}
+	if cluster.oidcConfig != nil {
+		writeJSON(w, http.StatusOK, cluster.oidcConfig)
+		return
+	}
+	c := cluster.cluster
+	writeJSON(w, http.StatusOK, acloudapi.ClusterMetadataResponse{
+		Endpoint:      fmt.Sprintf("https://%s.%s.%s.example.com:6443", c.Slug, c.EnvironmentSlug, c.CustomerSlug),
+		CACertificate: base64.StdEncoding.EncodeToString([]byte("acloudapitest " + c.Identity)),
+		ClientID:      c.Identity,
+		ClientSecret:  "secret-" + c.Identity,
+		IssuerUrl:     fmt.Sprintf("https://oidc.example.com/%s", c.CustomerSlug),
+	})
+}
+
+func (s *Server) listNodePools(w http.ResponseWriter, r *http.Request) {
+	_, cluster := s.lookupCluster(w, r)
+	if cluster == nil {
+		return
+	}
+	nodePools := make([]acloudapi.NodePool, len(cluster.nodePools))
+	for i, nodePool := range cluster.nodePools {
+		s.observeNodePool(nodePool)
+		nodePools[i] = nodePool.nodePool
+	}
+	writeJSON(w, http.StatusOK, nodePools)
+}
+
+// validateNodePool writes a bad request response and returns false if the node pool is invalid
+func validateNodePool(w http.ResponseWriter, nodePool acloudapi.CreateNodePool) bool {
+	switch {
+	case nodePool.Name == "":
+		writeFieldError(w, "name", "must not be empty")
+	case nodePool.NodeSize == "":
+		writeFieldError(w, "nodeSize", "must not be empty")
+	case nodePool.MinSize < 0 || nodePool.MaxSize < nodePool.MinSize:
+		writeFieldError(w, "maxSize", "must be greater than or equal to minSize")
+	default:
+		return true
+	}
+	return false
+}
+
+func securityUpdatesOnJoinOrDefault(securityUpdatesOnJoin acloudapi.NodePoolSecurityUpdatesOnJoin) acloudapi.NodePoolSecurityUpdatesOnJoin {
+	if securityUpdatesOnJoin == "" {
+		return acloudapi.NodePoolSecurityUpdatesOnJoinOff
+	}
+	return securityUpdatesOnJoin
+}
+
+func (s *Server) createNodePool(w http.ResponseWriter, r *http.Request) {
+	_, cluster := s.lookupCluster(w, r)
+	if cluster == nil {
+		return
+	}
+	create := acloudapi.CreateNodePool{}
+	if !readJSON(w, r, &create) || !validateNodePool(w, create) {
+		return
+	}
+	if cluster.nodePoolByName(create.Name) != nil {
+		writeError(w, http.StatusConflict, fmt.Sprintf("node pool %s already exists", create.Name))
+		return
+	}
+	now := s.now()
+	nodePool := &nodePoolState{nodePool: acloudapi.NodePool{
+		ID:                    s.nextID(),
+		Identity:              s.nextIdentity(),
+		Name:                  create.Name,
+		AvailabilityZone:      create.AvailabilityZone,
+		NodeSize:              create.NodeSize,
+		AutoScaling:           create.AutoScaling,
+		MinSize:               create.MinSize,
+		MaxSize:               create.MaxSize,
+		NodeAutoReplacement:   create.NodeAutoReplacement,
+		EnableNodeReboots:     create.EnableNodeReboots,
+		UpgradeStrategy:       create.UpgradeStrategy,
+		SecurityUpdatesOnJoin: securityUpdatesOnJoinOrDefault(create.SecurityUpdatesOnJoin),
+		Annotations:           create.Annotations,
+		Labels:                create.Labels,
+		Taints:                create.Taints,
+		CreatedAt:             now,
+		ModifiedAt:            now,
+		ClusterIdentity:       cluster.cluster.Identity,
+	}}
+	s.transitionNodePool(nodePool, acloudapi.NodePoolStatusProvisioning, acloudapi.NodePoolStatusProvisioned)
+	cluster.nodePools = append(cluster.nodePools, nodePool)
+	writeJSON(w, http.StatusCreated, nodePool.nodePool)
+}
+
+// lookupNodePool returns the node pool of the request, writing a not found response if it does not exist
+func (s *Server) lookupNodePool(w http.ResponseWriter, r *http.Request) *nodePoolState {
+	_, cluster := s.lookupCluster(w, r)
+	if cluster == nil {
+		return nil
+	}
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeNotFound(w, "node pool", r.PathValue("id"))
+		return nil
+	}
+	nodePool := cluster.nodePoolByID(id)
+	if nodePool == nil {
+		writeNotFound(w, "node pool", r.PathValue("id"))
+	}
+	return nodePool
+}
+
+func (s *Server) updateNodePool(w http.ResponseWriter, r *http.Request) {
+	nodePool := s.lookupNodePool(w, r)
+	if nodePool == nil {
+		return
+	}
+	update := acloudapi.CreateNodePool{}
+	if !readJSON(w, r, &update) || !validateNodePool(w, update) {
+		return
+	}
+	if update.NodeSize != nodePool.nodePool.NodeSize {
+		writeFieldError(w, "nodeSize", "cannot be changed")
+		return
+	}
+	n := &nodePool.nodePool
+	n.AutoScaling = update.AutoScaling
+	n.MinSize = update.MinSize
+	n.MaxSize = update.MaxSize
+	n.NodeAutoReplacement = update.NodeAutoReplacement
+	n.EnableNodeReboots = update.EnableNodeReboots
+	if update.UpgradeStrategy != "" {
+		n.UpgradeStrategy = update.UpgradeStrategy
+	}
+	if update.SecurityUpdatesOnJoin != "" {
+		n.SecurityUpdatesOnJoin = update.SecurityUpdatesOnJoin
+	}
+	n.Annotations = update.Annotations
+	n.Labels = update.Labels
+	n.Taints = update.Taints
+	s.transitionNodePool(nodePool, acloudapi.NodePoolStatusUpdating, acloudapi.NodePoolStatusProvisioned)
+	writeJSON(w, http.StatusOK, nodePool.nodePool)
+}
+
+func (s *Server) deleteNodePool(w http.ResponseWriter, r *http.Request) {
+	nodePool := s.lookupNodePool(w, r)
+	if nodePool == nil {
+		return
+	}
+	s.transitionNodePool(nodePool, acloudapi.NodePoolStatusDeleting, acloudapi.NodePoolStatusDeleted)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getNodePoolJoinConfig(w http.ResponseWriter, r *http.Request) {
+	_, cluster := s.lookupCluster(w, r)
+	if cluster == nil {
+		return
+	}
+	identity := r.PathValue("identity")
+	index := slices.IndexFunc(cluster.nodePools, func(nodePool *nodePoolState) bool {
+		return nodePool.nodePool.Identity == identity
+	})
+	if index < 0 {
+		writeNotFound(w, "node pool", identity)
+		return
+	}
+	encode := func(content string) string {
+		return base64.StdEncoding.EncodeToString([]byte(content))
+	}
+	writeJSON(w, http.StatusOK, acloudapi.NodePoolJoinConfig{
+		Versions:                acloudapi.NodeJoinConfigVersions{Kubernetes: cluster.cluster.Version},
+		CloudInitUserDataBase64: encode("#cloud-config\n"),
+		InstallScriptBase64:     encode("#!/bin/sh\n"),
+		UpgradeScriptBase64:     encode("#!/bin/sh\n"),
+		JoinCommand:             fmt.Sprintf("join --node-pool %s", identity),
+		KubeletConfigBase64:     encode("{}"),
+	})
+}
+
+// lookupCloudAccount returns the cloud account of the request, writing a not found response if it does not exist
+func (s *Server) lookupCloudAccount(w http.ResponseWriter, r *http.Request) (*organisationState, *cloudAccountState) {
+	organisation := s.lookupOrganisation(w, r)
+	if organisation == nil {
+		return nil, nil
+	}
+	cloudAccount := organisation.cloudAccount(r.PathValue("identity"))
+	if cloudAccount == nil {
+		writeNotFound(w, "cloud account", r.PathValue("identity"))
+	}
+	return organisation, cloudAccount
+}
+
+func (s *Server) listCloudAccounts(w http.ResponseWriter, r *http.Request) {
+	organisation := s.lookupOrganisation(w, r)
+	if organisation == nil {
+		return
+	}
+	displayName := r.URL.Query().Get("display-name")
+	cloudProvider := r.URL.Query().Get("cloud-provider-slug")
+	cloudAccounts := []acloudapi.CloudAccount{}
+	for _, cloudAccount := range organisation.cloudAccounts {
+		if displayName != "" && cloudAccount.cloudAccount.DisplayName != displayName {
+			continue
+		}
+		if cloudProvider != "" && cloudAccount.cloudAccount.CloudProfile.CloudProvider != cloudProvider {
+			continue
+		}
+		cloudAccounts = append(cloudAccounts, cloudAccount.cloudAccount)
+	}
+	writePage(w, r, s.opts.PageSize, cloudAccounts)
+}
+
+func (s *Server) createCloudAccount(w http.ResponseWriter, r *http.Request) {
+	organisation := s.lookupOrganisation(w, r)
+	if organisation == nil {
+		return
+	}
+	create := acloudapi.CreateCloudAccount{}
+	if !readJSON(w, r, &create) {
+		return
+	}
+	if create.DisplayName == "" {
+		writeFieldError(w, "displayName", "must not be empty")
+		return
+	}
+	cloudProfile := organisation.cloudProfile(create.CloudProfile)
+	if cloudProfile == nil {
+		writeFieldError(w, "cloudProfile", fmt.Sprintf("cloud profile %s does not exist", create.CloudProfile))
+		return
+	}
+	cloudAccount := &cloudAccountState{cloudAccount: acloudapi.CloudAccount{
+		Identity:     s.nextIdentity(),
+		DisplayName:  create.DisplayName,
+		Metadata:     create.Metadata,
+		CloudProfile: *cloudProfile,
+		Enabled:      true,
+	}}
+	organisation.cloudAccounts = append(organisation.cloudAccounts, cloudAccount)
+	writeJSON(w, http.StatusCreated, cloudAccount.cloudAccount)
+}
+
+func (s *Server) updateCloudAccount(w http.ResponseWriter, r *http.Request) {
+	_, cloudAccount := s.lookupCloudAccount(w, r)
+	if cloudAccount == nil {
+		return
+	}
+	update := acloudapi.UpdateCloudAccount{}
+	if !readJSON(w, r, &update) {
+		return
+	}
+	if update.PrimaryCloudCredentials != "" && !slices.ContainsFunc(cloudAccount.credentials, func(credentials acloudapi.CloudCredential) bool {
+		return credentials.Identity == update.PrimaryCloudCredentials
+	}) {
+		writeFieldError(w, "primaryCloudCredentials", fmt.Sprintf("cloud credentials %s do not exist", update.PrimaryCloudCredentials))
+		return
+	}
+	if update.DisplayName != "" {
+		cloudAccount.cloudAccount.DisplayName = update.DisplayName
+	}
+	cloudAccount.cloudAccount.Enabled = update.Enabled
+	if update.PrimaryCloudCredentials != "" {
+		cloudAccount.setPrimaryCredentials(update.PrimaryCloudCredentials)
+	}
+	writeJSON(w, http.StatusOK, cloudAccount.cloudAccount)
+}
+
+func (c *cloudAccountState) setPrimaryCredentials(identity string) {
+	c.cloudAccount.PrimaryCloudCredentialsIdentity = identity
+	for i := range c.credentials {
+		c.credentials[i].IsPrimary = c.credentials[i].Identity == identity
+	}
+}
+
+func (s *Server) deleteCloudAccount(w http.ResponseWriter, r *http.Request) {
+	organisation, cloudAccount := s.lookupCloudAccount(w, r)
+	if cloudAccount == nil {
+		return
+	}
+	for _, cluster := range organisation.clusters {
+		if cluster.cluster.CloudAccount != nil && cluster.cluster.CloudAccount.Identity == cloudAccount.cloudAccount.Identity {
+			writeError(w, http.StatusConflict, fmt.Sprintf("cloud account is used by cluster %s", cluster.cluster.Identifier()))
+			return
+		}
+	}
+	organisation.cloudAccounts = slices.DeleteFunc(organisation.cloudAccounts, func(c *cloudAccountState) bool {
+		return c == cloudAccount
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listCloudCredentials(w http.ResponseWriter, r *http.Request) {
+	if _, cloudAccount := s.lookupCloudAccount(w, r); cloudAccount != nil {
+		writePage(w, r, s.opts.PageSize, cloudAccount.credentials)
+	}
+}
+
+func (s *Server) createCloudCredential(w http.ResponseWriter, r *http.Request) {
+	_, cloudAccount := s.lookupCloudAccount(w, r)
+	if cloudAccount == nil {
+		return
+	}
+	cloudType := r.PathValue("type")
+	if cloudType != cloudAccount.cloudAccount.CloudProfile.Type {
+		writeFieldError(w, "type", fmt.Sprintf("cloud type %s does not match the cloud account", cloudType))
+		return
+	}
+	// the credentials are decoded as raw JSON, since the fake does not use them
+	create := struct {
+		DisplayName string          `json:"displayName"`
+		Credentials json.RawMessage `json:"credentials"`
+	}{}
+	if !readJSON(w, r, &create) {
+		return
+	}
+	if create.DisplayName == "" {
+		writeFieldError(w, "displayName", "must not be empty")
+		return
+	}
+	credentials := acloudapi.CloudCredential{
+		Identity:             s.nextIdentity(),
+		CloudAccountIdentity: cloudAccount.cloudAccount.Identity,
+		CloudType:            acloudapi.CloudProviderType(cloudType),
+		DisplayName:          create.DisplayName,
+		Metadata:             map[string]string{},
+		CreatedAt:            s.now(),
+	}
+	cloudAccount.credentials = append(cloudAccount.credentials, credentials)
+	if cloudAccount.cloudAccount.PrimaryCloudCredentialsIdentity == "" {
+		cloudAccount.setPrimaryCredentials(credentials.Identity)
+		credentials.IsPrimary = true
+	}
+	writeJSON(w, http.StatusCreated, credentials)
+}
+
+func (s *Server) deleteCloudCredential(w http.ResponseWriter, r *http.Request) {
+	_, cloudAccount := s.lookupCloudAccount(w, r)
+	if cloudAccount == nil {
+		return
+	}
+	identity := r.PathValue("credentials")
+	index := slices.IndexFunc(cloudAccount.credentials, func(credentials acloudapi.CloudCredential) bool {
+		return credentials.Identity == identity
+	})
+	if index < 0 {
+		writeNotFound(w, "cloud credentials", identity)
+		return
+	}
+	cloudAccount.credentials = slices.Delete(cloudAccount.credentials, index, index+1)
+	if cloudAccount.cloudAccount.PrimaryCloudCredentialsIdentity == identity {
+		cloudAccount.cloudAccount.PrimaryCloudCredentialsIdentity = ""
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listCloudProfiles(w http.ResponseWriter, r *http.Request) {
+	if organisation := s.lookupOrganisation(w, r); organisation != nil {
+		writePage(w, r, s.opts.PageSize, organisation.cloudProfiles)
+	}
+}
+
+func (s *Server) listCloudProviders(w http.ResponseWriter, r *http.Request) {
+	if s.lookupOrganisation(w, r) == nil {
+		return
+	}
+	cloudProviders := make([]acloudapi.CloudProvider, len(s.cloudProviders))
+	for i, cloudProvider := range s.cloudProviders {
+		cloudProviders[i] = cloudProvider.cloudProvider
+	}
+	writePage(w, r, s.opts.PageSize, cloudProviders)
+}
+
+// lookupCloudProvider returns the cloud provider of the request, writing a not found response if it does not exist
+func (s *Server) lookupCloudProvider(w http.ResponseWriter, r *http.Request) *cloudProviderState {
+	cloudProvider := s.cloudProvider(r.PathValue("provider"))
+	if cloudProvider == nil {
+		writeNotFound(w, "cloud provider", r.PathValue("provider"))
+	}
+	return cloudProvider
+}
+
+func (s *Server) listRegions(w http.ResponseWriter, r *http.Request) {
+	if s.lookupOrganisation(w, r) == nil {
+		return
+	}
+	cloudProvider := s.lookupCloudProvider(w, r)
+	if cloudProvider == nil {
+		return
+	}
+	regions := make([]acloudapi.Region, len(cloudProvider.regions))
+	for i, region := range cloudProvider.regions {
+		regions[i] = region.region
+	}
+	writePage(w, r, s.opts.PageSize, regions)
+}
+
+func (s *Server) listAvailabilityZones(w http.ResponseWriter, r *http.Request) {
+	if s.lookupOrganisation(w, r) == nil {
+		return
+	}
+	cloudProvider := s.lookupCloudProvider(w, r)
+	if cloudProvider == nil {
+		return
+	}
+	region := cloudProvider.region(r.PathValue("region"))
+	if region == nil {
+		writeNotFound(w, "region", r.PathValue("region"))
+		return
+	}
+	writePage(w, r, s.opts.PageSize, region.availabilityZones)
+}
+
+func (s *Server) listNodeTypes(w http.ResponseWriter, r *http.Request) {
+	if cloudProvider := s.lookupCloudProvider(w, r); cloudProvider != nil {
+		writePage(w, r, s.opts.PageSize, cloudProvider.nodeTypes)
+	}
+}
+
+func (s *Server) listUpdateChannels(w http.ResponseWriter, r *http.Request) {
+	if s.lookupOrganisation(w, r) != nil {
+		writePage(w, r, s.opts.PageSize, s.updateChannels)
+	}
+}
+
+func (s *Server) listPublicClusterVersions(w http.ResponseWriter, r *http.Request) {
+	clusterVersions := []acloudapi.ClusterVersion{}
+	for _, clusterVersion := range s.clusterVersions {
+		if clusterVersion.Available && clusterVersion.DeletedAt == nil {
+			clusterVersions = append(clusterVersions, acloudapi.ClusterVersion{Version: clusterVersion.Version})
+		}
+	}
+	writeJSON(w, http.StatusOK, clusterVersions)
+}
+
+func (s *Server) listMaintenanceSchedules(w http.ResponseWriter, r *http.Request) {
+	organisation := s.lookupOrganisation(w, r)
+	if organisation == nil {
+		return
+	}
+	maintenanceSchedules := make([]acloudapi.MaintenanceSchedule, len(organisation.maintenanceSchedules))
+	for i, maintenanceSchedule := range organisation.maintenanceSchedules {
+		maintenanceSchedules[i] = *maintenanceSchedule
+	}
+	writeJSON(w, http.StatusOK, maintenanceSchedules)
+}
+
+// lookupMaintenanceSchedule returns the maintenance schedule of the request, writing a not found response if it does not exist
+func (s *Server) lookupMaintenanceSchedule(w http.ResponseWriter, r *http.Request) (*organisationState, *acloudapi.MaintenanceSchedule) {
+	organisation := s.lookupOrganisation(w, r)
+	if organisation == nil {
+		return nil, nil
+	}
+	maintenanceSchedule := organisation.maintenanceSchedule(r.PathValue("identity"))
+	if maintenanceSchedule == nil {
+		writeNotFound(w, "maintenance schedule", r.PathValue("identity"))
+	}
+	return organisation, maintenanceSchedule
+}
+
+func (s *Server) getMaintenanceSchedule(w http.ResponseWriter, r *http.Request) {
+	if _, maintenanceSchedule := s.lookupMaintenanceSchedule(w, r); maintenanceSchedule != nil {
+		writeJSON(w, http.StatusOK, maintenanceSchedule)
+	}
+}
+
+func (s *Server) createMaintenanceSchedule(w http.ResponseWriter, r *http.Request) {
+	organisation := s.lookupOrganisation(w, r)
+	if organisation == nil {
+		return
+	}
+	create := acloudapi.CreateMaintenanceSchedule{}
+	if !readJSON(w, r, &create) {
+		return
+	}
+	if create.Name == "" {
+		writeFieldError(w, "name", "must not be empty")
+		return
+	}
+	maintenanceSchedule := &acloudapi.MaintenanceSchedule{
+		Identity:           s.nextIdentity(),
+		Name:               create.Name,
+		MaintenanceWindows: create.Windows,
+	}
+	organisation.maintenanceSchedules = append(organisation.maintenanceSchedules, maintenanceSchedule)
+	writeJSON(w, http.StatusCreated, maintenanceSchedule)
+}
+
+func (s *Server) updateMaintenanceSchedule(w http.ResponseWriter, r *http.Request) {
+	organisation, maintenanceSchedule := s.lookupMaintenanceSchedule(w, r)
+	if maintenanceSchedule == nil {
+		return
+	}
+	update := acloudapi.UpdateMaintenanceSchedule{}
+	if !readJSON(w, r, &update) {
+		return
+	}
+	if update.Name != "" {
+		maintenanceSchedule.Name = update.Name
+	}
+	if update.Windows != nil {
+		maintenanceSchedule.MaintenanceWindows = update.Windows
+	}
+	// clusters reference a copy of the maintenance schedule, as they do in API responses
+	for _, cluster := range organisation.clusters {
+		if cluster.cluster.MaintenanceSchedule != nil && cluster.cluster.MaintenanceSchedule.Identity == maintenanceSchedule.Identity {
+			updated := *maintenanceSchedule
+			cluster.cluster.MaintenanceSchedule = &updated
+		}
+	}
+	writeJSON(w, http.StatusOK, maintenanceSchedule)
+}
+
+func (s *Server) deleteMaintenanceSchedule(w http.ResponseWriter, r *http.Request) {
+	organisation, maintenanceSchedule := s.lookupMaintenanceSchedule(w, r)
+	if maintenanceSchedule == nil {
+		return
+	}
+	for _, cluster := range organisation.clusters {
+		if cluster.cluster.MaintenanceSchedule != nil && cluster.cluster.MaintenanceSchedule.Identity == maintenanceSchedule.Identity {
+			writeError(w, http.StatusConflict, fmt.Sprintf("maintenance schedule is used by cluster %s", cluster.cluster.Identifier()))
+			return
+		}
+	}
+	organisation.maintenanceSchedules = slices.DeleteFunc(organisation.maintenanceSchedules, func(m *acloudapi.MaintenanceSchedule) bool {
+		return m == maintenanceSchedule
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listObservabilityTenants(w http.ResponseWriter, r *http.Request) {
+	organisation := s.lookupOrganisation(w, r)
+	if organisation == nil {
+		return
+	}
+	tenants := make([]acloudapi.ObservabilityTenant, len(organisation.observabilityTenants))
+	for i, tenant := range organisation.observabilityTenants {
+		tenants[i] = tenant.tenant
+	}
+	writePage(w, r, s.opts.PageSize, tenants)
+}
+
+// lookupObservabilityTenant returns the observability tenant of the request, writing a not found response if it does not exist
+func (s *Server) lookupObservabilityTenant(w http.ResponseWriter, r *http.Request) *observabilityTenantState {
+	organisation := s.lookupOrganisation(w, r)
+	if organisation == nil {
+		return nil
+	}
+	tenant := organisation.observabilityTenant(r.PathValue("tenant"))
+	if tenant == nil {
+		writeNotFound(w, "observability tenant", r.PathValue("tenant"))
+	}
+	return tenant
+}
+
+func (s *Server) getObservabilityTenant(w http.ResponseWriter, r *http.Request) {
+	if tenant := s.lookupObservabilityTenant(w, r); tenant != nil {
+		writeJSON(w, http.StatusOK, tenant.tenant)
+	}
+}
+
+func (s *Server) getAlertmanager(w http.ResponseWriter, r *http.Request) {
+	if tenant := s.lookupObservabilityTenant(w, r); tenant != nil {
+		writeJSON(w, http.StatusOK, tenant.alertmanager)
+	}
+}
+
+func (s *Server) updateAlertmanager(w http.ResponseWriter, r *http.Request) {
+	tenant := s.lookupObservabilityTenant(w, r)
+	if tenant == nil {
+		return
+	}
+	update := acloudapi.ObservabilityAlertmanagerAndPrometheusrulesResponse{}
+	if !readJSON(w, r, &update) {
+		return
+	}
+	tenant.alertmanager = update
+	writeJSON(w, http.StatusOK, tenant.alertmanager)
+}
+
+// listAlerts returns no alerts, the fake does not evaluate alerting rules
+func (s *Server) listAlerts(w http.ResponseWriter, r *http.Request) {
+	if r.PathValue("tenant") != "" {
+		if s.lookupObservabilityTenant(w, r) == nil {
+			return
+		}
+	} else if s.lookupOrganisation(w, r) == nil {
+		return
+	}
+	writeJSON(w, http.StatusOK, []acloudapi.ObservabilityAlert{})
+}
+
+// silenceState returns the state of a silence at the current time
+func (s *Server) silenceState(silence acloudapi.Silence) string {
+	now := s.now()
+	switch {
+	case !silence.EndsAt.After(now):
+		return "expired"
+	case silence.StartsAt.After(now):
+		return "pending"
+	default:
+		return "active"
+	}
+}
+
+func (s *Server) listSilences(w http.ResponseWriter, r *http.Request) {
+	tenant := s.lookupObservabilityTenant(w, r)
+	if tenant == nil {
+		return
+	}
+	silences := make([]acloudapi.Silence, len(tenant.silences))
+	for i, silence := range tenant.silences {
+		silence.Status.State = s.silenceState(*silence)
+		silences[i] = *silence
+	}
+	writeJSON(w, http.StatusOK, silences)
+}
+
+func (s *Server) createSilence(w http.ResponseWriter, r *http.Request) {
+	tenant := s.lookupObservabilityTenant(w, r)
+	if tenant == nil {
+		return
+	}
+	create := acloudapi.CreateSilence{}
+	if !readJSON(w, r, &create) {
+		return
+	}
+	switch {
+	case strings.TrimSpace(create.Comment) == "":
+		writeFieldError(w, "comment", "must not be empty")
+		return
+	case len(create.Matchers) == 0:
+		writeFieldError(w, "matchers", "must not be empty")
+		return
+	case !create.EndsAt.After(create.StartsAt):
+		writeFieldError(w, "endsAt", "must be after startsAt")
+		return
+	}
+	silence := &acloudapi.Silence{
+		Id:        s.nextIdentity(),
+		Matchers:  create.Matchers,
+		StartsAt:  create.StartsAt,
+		EndsAt:    create.EndsAt,
+		CreatedBy: "acloudapitest",
+		Comment:   create.Comment,
+	}
+	silence.Status.State = s.silenceState(*silence)
+	tenant.silences = append(tenant.silences, silence)
+	writeJSON(w, http.StatusCreated, silence)
+}
+
+func (s *Server) expireSilence(w http.ResponseWriter, r *http.Request) {
+	tenant := s.lookupObservabilityTenant(w, r)
+	if tenant == nil {
+		return
+	}
+	index := slices.IndexFunc(tenant.silences, func(silence *acloudapi.Silence) bool {
+		return silence.Id == r.PathValue("id")
+	})
+	if index < 0 {
+		writeNotFound(w, "silence", r.PathValue("id"))
+		return
+	}
+	silence := tenant.silences[index]
+	if now := s.now(); silence.EndsAt.After(now) {
+		silence.EndsAt = now
+	}
+	silence.Status.State = s.silenceState(*silence)
+	w.WriteHeader(http.StatusNoContent)
+}
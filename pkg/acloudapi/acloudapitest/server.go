@@ -0,0 +1,362 @@
+// Package acloudapitest provides a stateful, in-memory fake of the Avisi Cloud public and admin API for tests.
+//
+// The fake serves the API over a local TLS test server and can be used with acloudapi.NewClient and
+// acloudapi.NewAdminClient without any network access:
+//
+//	server := acloudapitest.NewServer(acloudapitest.Opts{})
+//	defer server.Close()
+//	server.AddOrganisation(acloudapi.Organisation{Slug: "org1"})
+//	server.AddEnvironment("org1", acloudapi.Environment{Slug: "env1"})
+//
+//	client := server.Client()
+//	cluster, err := client.CreateCluster(ctx, "org1", "env1", acloudapi.CreateCluster{Name: "cluster1"})
+//
+// List endpoints are paged using Opts.PageSize. Resources that are created, updated or deleted through the API go
+// through realistic status transitions, e.g. a new cluster moves through all ClusterProvisionStatus steps before it is
+// running. Every time a resource is returned by the fake, its pending transition advances one step, unless
+// Opts.ManualTransitions is set. Use Advance and Settle to advance transitions from the test. Errors can be injected
+// using InjectFault.
+package acloudapitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/avisi-cloud/go-client/pkg/acloudapi"
+)
+
+// DefaultPageSize is the number of items per page of list endpoints
+const DefaultPageSize = 20
+
+// Opts configures the fake API server
+type Opts struct {
+	// PageSize is the number of items per page of list endpoints, defaults to DefaultPageSize
+	PageSize int
+	// Token is the personal access token or bearer token that requests must be authenticated with.
+	// When empty, requests are not authenticated
+	Token string
+	// ManualTransitions disables advancing status transitions when resources are returned, transitions then only
+	// advance when calling Advance or Settle
+	ManualTransitions bool
+	// Now returns the current time, used for timestamps of resources. Defaults to time.Now
+	Now func() time.Time
+}
+
+// Request is a request received by the fake API server
+type Request struct {
+	Method string
+	Path   string
+	Query  string
+}
+
+// Fault is an error response injected into the fake API server
+type Fault struct {
+	// Method matches the HTTP method of the request, empty matches all methods
+	Method string
+	// Path matches the path of the request using path.Match, e.g. "/api/v1/orgs/*/clusters". Empty matches all paths
+	Path string
+	// StatusCode is the status code of the error response, e.g. http.StatusServiceUnavailable
+	StatusCode int
+	// Message is the message of the error response, defaults to the status text
+	Message string
+	// Header is added to the error response, e.g. a Retry-After header
+	Header http.Header
+	// Times is the number of requests that fail, 0 fails all matching requests until the fault is removed
+	Times int
+}
+
+type fault struct {
+	Fault
+	remaining int
+}
+
+// Server is a stateful, in-memory fake of the Avisi Cloud API
+type Server struct {
+	*httptest.Server
+
+	opts Opts
+	mux  *http.ServeMux
+
+	mu                       sync.Mutex
+	lastID                   int
+	organisations            []*organisationState
+	cloudProviders           []*cloudProviderState
+	updateChannels           []acloudapi.UpdateChannelResponse
+	clusterVersions          []*acloudapi.AdminClusterVersion
+	scheduledClusterUpgrades []*scheduledClusterUpgradeState
+	faults                   []*fault
+	requests                 []Request
+}
+
+type organisationState struct {
+	organisation         acloudapi.Organisation
+	environments         []*acloudapi.Environment
+	clusters             []*clusterState
+	cloudProfiles        []acloudapi.CloudProfile
+	cloudAccounts        []*cloudAccountState
+	maintenanceSchedules []*acloudapi.MaintenanceSchedule
+	observabilityTenants []*observabilityTenantState
+}
+
+type clusterState struct {
+	cluster     acloudapi.Cluster
+	nodePools   []*nodePoolState
+	oidcConfig  *acloudapi.ClusterMetadataResponse
+	transitions []func(cluster *acloudapi.Cluster)
+}
+
+type nodePoolState struct {
+	nodePool    acloudapi.NodePool
+	transitions []func(nodePool *acloudapi.NodePool)
+}
+
+type cloudAccountState struct {
+	cloudAccount acloudapi.CloudAccount
+	credentials  []acloudapi.CloudCredential
+}
+
+type cloudProviderState struct {
+	cloudProvider acloudapi.CloudProvider
+	regions       []*regionState
+	nodeTypes     []acloudapi.NodeType
+}
+
+type regionState struct {
+	region            acloudapi.Region
+	availabilityZones []acloudapi.AvailabilityZone
+}
+
+type observabilityTenantState struct {
+	tenant       acloudapi.ObservabilityTenant
+	alertmanager acloudapi.ObservabilityAlertmanagerAndPrometheusrulesResponse
+	silences     []*acloudapi.Silence
+}
+
+type scheduledClusterUpgradeState struct {
+	upgrade     acloudapi.ScheduledClusterUpgrade
+	transitions []func(upgrade *acloudapi.ScheduledClusterUpgrade)
+}
+
+// NewServer starts a new fake API server. The caller should call Close when finished, to shut it down.
+func NewServer(opts Opts) *Server {
+	if opts.PageSize <= 0 {
+		opts.PageSize = DefaultPageSize
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	s := &Server{
+		opts: opts,
+		mux:  http.NewServeMux(),
+	}
+	s.registerPublicAPI()
+	s.registerAdminAPI()
+	s.Server = httptest.NewTLSServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// ClientOpts returns the acloudapi.ClientOpts to connect to the fake API server. Retries are disabled, so injected
+// faults are returned directly; set RetryPolicy to test retries.
+func (s *Server) ClientOpts() acloudapi.ClientOpts {
+	return acloudapi.ClientOpts{
+		APIUrl:          s.URL,
+		CustomTransport: s.Server.Client().Transport.(*http.Transport),
+		RetryPolicy:     acloudapi.NoRetryPolicy(),
+	}
+}
+
+// Authenticator returns an authenticator using Opts.Token
+func (s *Server) Authenticator() acloudapi.Authenticator {
+	return acloudapi.NewPersonalAccessTokenAuthenticator(s.opts.Token)
+}
+
+// Client returns a new client of the public API, connected to the fake API server
+func (s *Server) Client() acloudapi.Client {
+	return acloudapi.NewClient(s.Authenticator(), s.ClientOpts())
+}
+
+// AdminClient returns a new client of the admin API, connected to the fake API server
+func (s *Server) AdminClient() acloudapi.AdminClient {
+	return acloudapi.NewAdminClient(s.Authenticator(), s.ClientOpts())
+}
+
+// InjectFault makes matching requests fail with an error response. Faults are matched in the order they were injected.
+// The returned function removes the fault.
+func (s *Server) InjectFault(f Fault) (remove func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	injected := &fault{Fault: f, remaining: f.Times}
+	s.faults = append(s.faults, injected)
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.removeFault(injected)
+	}
+}
+
+func (s *Server) removeFault(f *fault) {
+	for i, injected := range s.faults {
+		if injected == f {
+			s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			return
+		}
+	}
+}
+
+// Requests returns all requests received by the fake API server, in order
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{Method: r.Method, Path: r.URL.Path, Query: r.URL.RawQuery})
+	injected := s.matchFault(r)
+	s.mu.Unlock()
+
+	if injected != nil {
+		for name, values := range injected.Header {
+			w.Header()[name] = values
+		}
+		writeError(w, injected.StatusCode, injected.Message)
+		return
+	}
+	if s.opts.Token != "" && !s.authenticated(r) {
+		writeError(w, http.StatusUnauthorized, "")
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) matchFault(r *http.Request) *fault {
+	for _, injected := range s.faults {
+		if injected.Method != "" && injected.Method != r.Method {
+			continue
+		}
+		if injected.Path != "" {
+			if matched, _ := path.Match(injected.Path, r.URL.Path); !matched {
+				continue
+			}
+		}
+		if injected.Times > 0 {
+			injected.remaining--
+			if injected.remaining <= 0 {
+				s.removeFault(injected)
+			}
+		}
+		return injected
+	}
+	return nil
+}
+
+func (s *Server) authenticated(r *http.Request) bool {
+	scheme, token, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+	return (scheme == "Token" || scheme == "Bearer") && token == s.opts.Token
+}
+
+// handle registers a handler that is called with the state lock held, after removing deleted resources
+func (s *Server) handle(pattern string, handler http.HandlerFunc) {
+	s.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.sweep()
+		handler(w, r)
+	})
+}
+
+func (s *Server) now() time.Time {
+	return s.opts.Now().UTC()
+}
+
+// nextID returns a new sequential ID, used for IDs and identities of resources
+func (s *Server) nextID() int {
+	s.lastID++
+	return s.lastID
+}
+
+func (s *Server) nextIdentity() string {
+	return fmt.Sprintf("00000000-0000-4000-8000-%012d", s.nextID())
+}
+
+var nonSlugCharacters = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slugify returns the slug of a name, as generated by the API
+func slugify(name string) string {
+	return strings.Trim(nonSlugCharacters.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, statusCode int, message string, fieldErrors ...acloudapi.FieldError) {
+	if message == "" {
+		message = http.StatusText(statusCode)
+	}
+	writeJSON(w, statusCode, acloudapi.Error{Message: message, Errors: fieldErrors})
+}
+
+func writeNotFound(w http.ResponseWriter, kind, name string) {
+	writeError(w, http.StatusNotFound, fmt.Sprintf("%s %s not found", kind, name))
+}
+
+func writeFieldError(w http.ResponseWriter, field, message string) {
+	writeError(w, http.StatusBadRequest, "validation failed", acloudapi.FieldError{Field: field, Message: message})
+}
+
+// readJSON decodes the request body into v, writing a bad request response and returning false if that fails
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return false
+	}
+	return true
+}
+
+// page is a page of a paged result, as returned by the API
+type page[T any] struct {
+	Content          []T  `json:"content"`
+	Last             bool `json:"last"`
+	First            bool `json:"first"`
+	Empty            bool `json:"empty"`
+	TotalPages       int  `json:"totalPages"`
+	TotalElements    int  `json:"totalElements"`
+	NumberOfElements int  `json:"numberOfElements"`
+	Number           int  `json:"number"`
+	Size             int  `json:"size"`
+}
+
+// writePage writes the page of items requested by the page query parameter
+func writePage[T any](w http.ResponseWriter, r *http.Request, pageSize int, items []T) {
+	number, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || number < 0 {
+		number = 0
+	}
+	totalPages := (len(items) + pageSize - 1) / pageSize
+	start := min(number*pageSize, len(items))
+	end := min(start+pageSize, len(items))
+	content := append(make([]T, 0, end-start), items[start:end]...)
+	writeJSON(w, http.StatusOK, page[T]{
+		Content:          content,
+		Last:             number >= totalPages-1,
+		First:            number == 0,
+		Empty:            len(content) == 0,
+		TotalPages:       totalPages,
+		TotalElements:    len(items),
+		NumberOfElements: len(content),
+		Number:           number,
+		Size:             pageSize,
+	})
+}
@@ -0,0 +1,210 @@
+package acloudapitest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/avisi-cloud/go-client/pkg/acloudapi"
+)
+
+func newTestServer(t *testing.T, opts Opts) *Server {
+	t.Helper()
+	server := NewServer(opts)
+	t.Cleanup(server.Close)
+	server.AddOrganisation(acloudapi.Organisation{Slug: "org1"})
+	server.AddEnvironment("org1", acloudapi.Environment{Slug: "env1"})
+	return server
+}
+
+func isStatus(err error, statusCode int) bool {
+	var apiError *acloudapi.APIError
+	return errors.As(err, &apiError) && apiError.StatusCode == statusCode
+}
+
+func TestClusterLifecycle(t *testing.T) {
+	server := newTestServer(t, Opts{})
+	client := server.Client()
+	ctx := context.Background()
+
+	cluster, err := client.CreateCluster(ctx, "org1", "env1", acloudapi.CreateCluster{
+		Name:      "Cluster 1",
+		NodePools: []acloudapi.NodePools{{Name: "workers", NodeSize: "small", MinSize: 1, MaxSize: 3}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cluster.Slug != "cluster-1" || cluster.Status != acloudapi.ClusterStatusProvisioning || cluster.ProvisionStatus != acloudapi.ACCEPTED {
+		t.Fatalf("unexpected new cluster: slug %q, status %q, provision status %q", cluster.Slug, cluster.Status, cluster.ProvisionStatus)
+	}
+
+	var provisionStatuses []acloudapi.ClusterProvisionStatus
+	for cluster.Status != acloudapi.ClusterStatusRunning {
+		if len(provisionStatuses) > 10 {
+			t.Fatalf("cluster did not become running, provision statuses: %v", provisionStatuses)
+		}
+		if cluster, err = client.GetCluster(ctx, "org1", "env1", "cluster-1"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		provisionStatuses = append(provisionStatuses, cluster.ProvisionStatus)
+	}
+	if len(provisionStatuses) != 5 || provisionStatuses[4] != acloudapi.DONE {
+		t.Fatalf("unexpected provision statuses: %v", provisionStatuses)
+	}
+
+	nodePools, err := client.GetNodePoolsByCluster(ctx, *cluster)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(nodePools) != 1 || nodePools[0].ProvisionStatus != acloudapi.NodePoolStatusProvisioned {
+		t.Fatalf("unexpected node pools: %+v", nodePools)
+	}
+	if _, err := client.UpdateNodePool(ctx, *cluster, nodePools[0].ID, acloudapi.CreateNodePool{Name: "workers", NodeSize: "large", MaxSize: 3}); !isStatus(err, http.StatusBadRequest) {
+		t.Fatalf("expected a bad request when changing the node size, got %v", err)
+	}
+
+	if _, err := client.UpdateCluster(ctx, "org1", "env1", "cluster-1", acloudapi.UpdateCluster{DeleteProtection: acloudapi.True()}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	deleted := acloudapi.ClusterStatusDeleted
+	if err := client.DeleteCluster(ctx, "org1", "env1", "cluster-1", acloudapi.UpdateCluster{Status: &deleted}); !errors.Is(err, acloudapi.ErrConflict) {
+		t.Fatalf("expected ErrConflict for a delete protected cluster, got %v", err)
+	}
+	if err := client.DeleteCluster(ctx, "org1", "env1", "cluster-1", acloudapi.UpdateCluster{Status: &deleted, DeleteProtection: acloudapi.False()}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cluster, err = client.GetCluster(ctx, "org1", "env1", "cluster-1"); err != nil || cluster.Status != acloudapi.ClusterStatusDeleted {
+		t.Fatalf("expected the deleted cluster to be returned once, got %v, %v", cluster, err)
+	}
+	if _, err := client.GetCluster(ctx, "org1", "env1", "cluster-1"); !errors.Is(err, acloudapi.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for a deleted cluster, got %v", err)
+	}
+}
+
+func TestManualTransitions(t *testing.T) {
+	server := newTestServer(t, Opts{ManualTransitions: true})
+	client := server.Client()
+	ctx := context.Background()
+
+	created, err := client.CreateCluster(ctx, "org1", "env1", acloudapi.CreateCluster{Name: "cluster1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for range 3 {
+		cluster, err := client.GetCluster(ctx, "org1", "env1", "cluster1")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cluster.ProvisionStatus != acloudapi.ACCEPTED {
+			t.Fatalf("expected the cluster to remain accepted, got %q", cluster.ProvisionStatus)
+		}
+	}
+	server.Advance()
+	if cluster, _ := server.Cluster(created.Identity); cluster.ProvisionStatus != acloudapi.OIDC_PROVISIONED {
+		t.Fatalf("expected Advance to move the cluster one step, got %q", cluster.ProvisionStatus)
+	}
+	server.Settle()
+	if cluster, _ := server.Cluster(created.Identity); cluster.Status != acloudapi.ClusterStatusRunning || cluster.ProvisionStatus != acloudapi.DONE {
+		t.Fatalf("expected Settle to make the cluster running, got %q, %q", cluster.Status, cluster.ProvisionStatus)
+	}
+}
+
+func TestPaging(t *testing.T) {
+	server := newTestServer(t, Opts{PageSize: 2})
+	for i := range 5 {
+		server.AddCluster(acloudapi.Cluster{CustomerSlug: "org1", EnvironmentSlug: "env1", Slug: fmt.Sprintf("cluster%d", i)})
+	}
+	client := server.Client()
+
+	clusters, err := client.GetClustersByOrg(context.Background(), "org1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(clusters) != 5 {
+		t.Fatalf("expected 5 clusters, got %d", len(clusters))
+	}
+	pageRequests := 0
+	for _, request := range server.Requests() {
+		if request.Path == "/api/v1/orgs/org1/clusters" {
+			pageRequests++
+		}
+	}
+	if pageRequests != 3 {
+		t.Fatalf("expected 3 page requests, got %d", pageRequests)
+	}
+}
+
+func TestInjectFault(t *testing.T) {
+	server := newTestServer(t, Opts{})
+	client := server.Client()
+	ctx := context.Background()
+
+	remove := server.InjectFault(Fault{Method: http.MethodGet, Path: "/api/v1/orgs/*/environments/*", StatusCode: http.StatusNotFound})
+	if _, err := client.GetEnvironment(ctx, "org1", "env1"); !errors.Is(err, acloudapi.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	remove()
+	if _, err := client.GetEnvironment(ctx, "org1", "env1"); err != nil {
+		t.Fatalf("unexpected error after removing the fault: %v", err)
+	}
+
+	server.InjectFault(Fault{StatusCode: http.StatusServiceUnavailable, Times: 1})
+	if _, err := client.GetEnvironments(ctx, "org1"); !isStatus(err, http.StatusServiceUnavailable) {
+		t.Fatalf("expected a 503 APIError, got %v", err)
+	}
+	if _, err := client.GetEnvironments(ctx, "org1"); err != nil {
+		t.Fatalf("expected the fault to be removed after 1 request, got %v", err)
+	}
+}
+
+func TestToken(t *testing.T) {
+	server := newTestServer(t, Opts{Token: "secret"})
+	ctx := context.Background()
+
+	if _, err := server.Client().GetMemberships(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wrongToken := acloudapi.NewClient(acloudapi.NewPersonalAccessTokenAuthenticator("wrong"), server.ClientOpts())
+	if _, err := wrongToken.GetMemberships(ctx); !errors.Is(err, acloudapi.ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized, got %v", err)
+	}
+}
+
+func TestScheduledClusterUpgrades(t *testing.T) {
+	server := newTestServer(t, Opts{})
+	cluster := server.AddCluster(acloudapi.Cluster{CustomerSlug: "org1", EnvironmentSlug: "env1", Slug: "cluster1", Version: "1.30.1"})
+	client := server.AdminClient()
+	ctx := context.Background()
+
+	windowStart := time.Now().Add(time.Hour)
+	upgrade, err := client.CreateScheduledClusterUpgrade(ctx, acloudapi.CreateScheduledClusterUpgradeRequest{
+		ClusterIdentity:  cluster.Identity,
+		WindowStart:      windowStart,
+		WindowEnd:        windowStart.Add(time.Hour),
+		ToClusterVersion: "1.31.0",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if upgrade.Status != acloudapi.Requested || upgrade.FromClusterVersion != "1.30.1" {
+		t.Fatalf("unexpected scheduled upgrade: %+v", upgrade)
+	}
+
+	upgrades, err := client.ListScheduledClusterUpgrades(ctx, acloudapi.ListScheduledClusterUpgradesOpts{Statuses: []acloudapi.ScheduledClusterUpgradeStatus{acloudapi.Scheduled}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(upgrades) != 1 || upgrades[0].Identity != upgrade.Identity {
+		t.Fatalf("expected the upgrade to be scheduled, got %+v", upgrades)
+	}
+
+	if _, err := client.CancelScheduledClusterUpgrade(ctx, upgrade.Identity); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := client.GetScheduledClusterUpgrade(ctx, upgrade.Identity); !errors.Is(err, acloudapi.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for a cancelled upgrade, got %v", err)
+	}
+}
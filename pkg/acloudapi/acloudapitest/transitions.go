@@ -0,0 +1,137 @@
+package acloudapitest
+
+import (
+	"github.com/avisi-cloud/go-client/pkg/acloudapi"
+)
+
+// Advance advances all pending status transitions one step
+func (s *Server) Advance() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep()
+	for _, organisation := range s.organisations {
+		for _, cluster := range organisation.clusters {
+			advance(&cluster.transitions, &cluster.cluster)
+			for _, nodePool := range cluster.nodePools {
+				advance(&nodePool.transitions, &nodePool.nodePool)
+			}
+		}
+	}
+	for _, upgrade := range s.scheduledClusterUpgrades {
+		advance(&upgrade.transitions, &upgrade.upgrade)
+	}
+}
+
+// Settle completes all pending status transitions, e.g. to make all new clusters running
+func (s *Server) Settle() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, organisation := range s.organisations {
+		for _, cluster := range organisation.clusters {
+			for len(cluster.transitions) > 0 {
+				advance(&cluster.transitions, &cluster.cluster)
+			}
+			for _, nodePool := range cluster.nodePools {
+				for len(nodePool.transitions) > 0 {
+					advance(&nodePool.transitions, &nodePool.nodePool)
+				}
+			}
+		}
+	}
+	for _, upgrade := range s.scheduledClusterUpgrades {
+		for len(upgrade.transitions) > 0 {
+			advance(&upgrade.transitions, &upgrade.upgrade)
+		}
+	}
+	s.sweep()
+}
+
+// advance applies the first pending transition to resource
+func advance[T any](transitions *[]func(*T), resource *T) {
+	if len(*transitions) == 0 {
+		return
+	}
+	transition := (*transitions)[0]
+	*transitions = (*transitions)[1:]
+	transition(resource)
+}
+
+// observe advances the pending transition of a resource that is returned by the fake, unless transitions are manual
+func observe[T any](s *Server, transitions *[]func(*T), resource *T) {
+	if !s.opts.ManualTransitions {
+		advance(transitions, resource)
+	}
+}
+
+func (s *Server) observeCluster(cluster *clusterState) {
+	observe(s, &cluster.transitions, &cluster.cluster)
+}
+
+func (s *Server) observeNodePool(nodePool *nodePoolState) {
+	observe(s, &nodePool.transitions, &nodePool.nodePool)
+}
+
+// sweep removes the clusters and node pools that have been deleted. Deleted resources are returned once with
+// their deleted status, so callers waiting for the deletion can observe it, and are removed on the next request.
+func (s *Server) sweep() {
+	for _, organisation := range s.organisations {
+		clusters := organisation.clusters[:0]
+		for _, cluster := range organisation.clusters {
+			if cluster.cluster.Status == acloudapi.ClusterStatusDeleted {
+				continue
+			}
+			nodePools := cluster.nodePools[:0]
+			for _, nodePool := range cluster.nodePools {
+				if nodePool.nodePool.ProvisionStatus != acloudapi.NodePoolStatusDeleted {
+					nodePools = append(nodePools, nodePool)
+				}
+			}
+			cluster.nodePools = nodePools
+			clusters = append(clusters, cluster)
+		}
+		organisation.clusters = clusters
+	}
+}
+
+// provisionCluster moves a new cluster through all provision steps, until it is running
+func (s *Server) provisionCluster(cluster *clusterState) {
+	cluster.cluster.Status = acloudapi.ClusterStatusProvisioning
+	cluster.cluster.DesiredStatus = acloudapi.ClusterStatusRunning
+	cluster.cluster.ProvisionStatus = acloudapi.ACCEPTED
+	for _, provisionStatus := range []acloudapi.ClusterProvisionStatus{
+		acloudapi.OIDC_PROVISIONED,
+		acloudapi.CLUSTER_PROVISIONED,
+		acloudapi.INITIAL_NODE_POOLS_PROVISIONED,
+		acloudapi.INITIAL_ADDONS_PROVISIONED,
+	} {
+		cluster.transitions = append(cluster.transitions, func(c *acloudapi.Cluster) {
+			c.ProvisionStatus = provisionStatus
+		})
+	}
+	cluster.transitions = append(cluster.transitions, func(c *acloudapi.Cluster) {
+		c.ProvisionStatus = acloudapi.DONE
+		c.Status = acloudapi.ClusterStatusRunning
+	})
+}
+
+// transitionCluster moves a cluster through an intermediate status to its desired status
+func (s *Server) transitionCluster(cluster *clusterState, intermediate, desired string, apply func(c *acloudapi.Cluster)) {
+	cluster.cluster.Status = intermediate
+	cluster.cluster.DesiredStatus = desired
+	cluster.transitions = append(cluster.transitions, func(c *acloudapi.Cluster) {
+		c.Status = desired
+		c.ModifiedAt = s.now()
+		if apply != nil {
+			apply(c)
+		}
+	})
+}
+
+// transitionNodePool moves a node pool through an intermediate status to its final status
+func (s *Server) transitionNodePool(nodePool *nodePoolState, intermediate, final string) {
+	nodePool.nodePool.ProvisionStatus = intermediate
+	nodePool.transitions = append(nodePool.transitions, func(n *acloudapi.NodePool) {
+		n.ProvisionStatus = final
+		n.ModifiedAt = s.now()
+	})
+}
@@ -0,0 +1,200 @@
+package acloudapi
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+)
+
+// AddonValueType is the type of the custom value of an addon
+type AddonValueType string
+
+const (
+	AddonValueString AddonValueType = "string"
+	AddonValueBool   AddonValueType = "bool"
+	AddonValueInt    AddonValueType = "int"
+)
+
+// AddonValue describes a custom value accepted by an addon
+type AddonValue struct {
+	Description string
+	Type        AddonValueType
+	// Allowed lists the accepted values, any value of the Type is accepted when empty
+	Allowed []string
+}
+
+// Validate returns an error if value is not accepted
+func (v AddonValue) Validate(value string) error {
+	switch v.Type {
+	case AddonValueBool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("%q is not a boolean", value)
+		}
+	case AddonValueInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("%q is not an integer", value)
+		}
+	}
+	if len(v.Allowed) > 0 && !slices.Contains(v.Allowed, value) {
+		return fmt.Errorf("%q is not one of %v", value, v.Allowed)
+	}
+	return nil
+}
+
+// AddonDefinition describes an addon and the custom values it accepts
+type AddonDefinition struct {
+	Name         string
+	Description  string
+	CustomValues map[string]AddonValue
+}
+
+// AddonCatalogue contains the known addons by name. The API does not describe the addons it offers, so no catalogue of
+// the platform's addons is built in: use GetAddonCatalogue for the addons the clusters of an organisation already use,
+// or provide the addons and custom values a team uses.
+type AddonCatalogue map[string]AddonDefinition
+
+// GetAddonCatalogue returns a catalogue of the addons and custom values that are set on the clusters of the
+// organisation, including disabled addons. Custom values are strings of which any value is accepted, so the catalogue
+// catches misspelled addon names and custom value keys. Add definitions to it to also check values, or to allow addons
+// that are not used by any cluster yet.
+func GetAddonCatalogue(ctx context.Context, client ClusterAPI, organisationSlug string) (AddonCatalogue, error) {
+	clusters, err := client.GetClustersByOrg(ctx, organisationSlug)
+	if err != nil {
+		return nil, err
+	}
+	catalogue := AddonCatalogue{}
+	for _, cluster := range clusters {
+		for name, addon := range cluster.Addons {
+			definition, ok := catalogue[name]
+			if !ok {
+				definition = AddonDefinition{Name: name, CustomValues: map[string]AddonValue{}}
+			}
+			for key := range addon.CustomValues {
+				definition.CustomValues[key] = AddonValue{Type: AddonValueString}
+			}
+			catalogue[name] = definition
+		}
+	}
+	return catalogue, nil
+}
+
+// Validate returns an error if the addon is unknown, or has custom values that are not accepted
+func (c AddonCatalogue) Validate(name string, addon APIAddon) error {
+	definition, ok := c[name]
+	if !ok {
+		return fmt.Errorf("unknown addon %s", name)
+	}
+	for _, key := range slices.Sorted(maps.Keys(addon.CustomValues)) {
+		value, ok := definition.CustomValues[key]
+		if !ok {
+			return fmt.Errorf("unknown custom value %s of addon %s", key, name)
+		}
+		if err := value.Validate(addon.CustomValues[key]); err != nil {
+			return fmt.Errorf("invalid custom value %s of addon %s: %w", key, name, err)
+		}
+	}
+	return nil
+}
+
+// ClusterAddon is an addon of a cluster
+type ClusterAddon struct {
+	Name string
+	APIAddon
+}
+
+// AddonOpts are the options of the cluster addon functions
+type AddonOpts struct {
+	// Catalogue is used to validate the addon before the cluster is updated. Addons are not validated when it is nil.
+	Catalogue AddonCatalogue
+}
+
+func mergeAddonOpts(opts []AddonOpts) AddonOpts {
+	merged := AddonOpts{}
+	for _, opt := range opts {
+		if opt.Catalogue != nil {
+			merged.Catalogue = opt.Catalogue
+		}
+	}
+	return merged
+}
+
+// GetClusterAddons returns the addons of the cluster, ordered by name
+func GetClusterAddons(ctx context.Context, client ClusterAPI, cluster Cluster) ([]ClusterAddon, error) {
+	current, err := client.GetCluster(ctx, cluster.CustomerSlug, cluster.EnvironmentSlug, cluster.Slug)
+	if err != nil {
+		return nil, err
+	}
+	addons := make([]ClusterAddon, 0, len(current.Addons))
+	for _, name := range slices.Sorted(maps.Keys(current.Addons)) {
+		addons = append(addons, ClusterAddon{Name: name, APIAddon: current.Addons[name]})
+	}
+	return addons, nil
+}
+
+// EnableClusterAddon enables the addon on the cluster, keeping its custom values
+func EnableClusterAddon(ctx context.Context, client ClusterAPI, cluster Cluster, name string, opts ...AddonOpts) (*Cluster, error) {
+	return updateClusterAddon(ctx, client, cluster, name, mergeAddonOpts(opts), func(addon *APIAddon) {
+		addon.Enabled = true
+	})
+}
+
+// DisableClusterAddon disables the addon on the cluster, keeping its custom values. The addon is not validated against
+// the catalogue, so an addon of the cluster can always be disabled.
+func DisableClusterAddon(ctx context.Context, client ClusterAPI, cluster Cluster, name string, opts ...AddonOpts) (*Cluster, error) {
+	mergedOpts := mergeAddonOpts(opts)
+	mergedOpts.Catalogue = nil
+	return updateClusterAddon(ctx, client, cluster, name, mergedOpts, func(addon *APIAddon) {
+		addon.Enabled = false
+	})
+}
+
+// SetClusterAddonCustomValues merges the values into the custom values of the addon. Other custom values are kept.
+func SetClusterAddonCustomValues(ctx context.Context, client ClusterAPI, cluster Cluster, name string, values map[string]string, opts ...AddonOpts) (*Cluster, error) {
+	return updateClusterAddon(ctx, client, cluster, name, mergeAddonOpts(opts), func(addon *APIAddon) {
+		if addon.CustomValues == nil {
+			addon.CustomValues = map[string]string{}
+		}
+		maps.Copy(addon.CustomValues, values)
+	})
+}
+
+// UnsetClusterAddonCustomValues removes the custom values from the addon. Other custom values are kept.
+func UnsetClusterAddonCustomValues(ctx context.Context, client ClusterAPI, cluster Cluster, name string, keys []string, opts ...AddonOpts) (*Cluster, error) {
+	mergedOpts := mergeAddonOpts(opts)
+	// removing custom values is always allowed, e.g. to clean up a value that is no longer supported
+	mergedOpts.Catalogue = nil
+	return updateClusterAddon(ctx, client, cluster, name, mergedOpts, func(addon *APIAddon) {
+		for _, key := range keys {
+			delete(addon.CustomValues, key)
+		}
+	})
+}
+
+// updateClusterAddon applies change to the current state of the addon, and updates the cluster if the addon changed
+func updateClusterAddon(ctx context.Context, client ClusterAPI, cluster Cluster, name string, opts AddonOpts, change func(addon *APIAddon)) (*Cluster, error) {
+	if opts.Catalogue != nil {
+		if _, ok := opts.Catalogue[name]; !ok {
+			return nil, fmt.Errorf("unknown addon %s", name)
+		}
+	}
+	current, err := client.GetCluster(ctx, cluster.CustomerSlug, cluster.EnvironmentSlug, cluster.Slug)
+	if err != nil {
+		return nil, err
+	}
+	existing := current.Addons[name]
+	addon := APIAddon{Enabled: existing.Enabled, CustomValues: maps.Clone(existing.CustomValues)}
+	change(&addon)
+	if addon.Enabled == existing.Enabled && maps.Equal(addon.CustomValues, existing.CustomValues) {
+		return current, nil
+	}
+	if opts.Catalogue != nil {
+		if err := opts.Catalogue.Validate(name, addon); err != nil {
+			return nil, err
+		}
+	}
+	return client.UpdateCluster(ctx, cluster.CustomerSlug, cluster.EnvironmentSlug, cluster.Slug, UpdateCluster{
+		Addons: map[string]APIAddon{name: addon},
+	})
+}
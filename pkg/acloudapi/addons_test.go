@@ -0,0 +1,164 @@
+package acloudapi_test
+
+import (
+	"context"
+	"maps"
+	"strings"
+	"testing"
+
+	"github.com/avisi-cloud/go-client/pkg/acloudapi"
+	"github.com/avisi-cloud/go-client/pkg/acloudapi/acloudapitest"
+)
+
+var testAddonCatalogue = acloudapi.AddonCatalogue{
+	"ingress-nginx": {
+		Name: "ingress-nginx",
+		CustomValues: map[string]acloudapi.AddonValue{
+			"replicas":              {Type: acloudapi.AddonValueInt},
+			"externalTrafficPolicy": {Type: acloudapi.AddonValueString, Allowed: []string{"Cluster", "Local"}},
+			"proxyProtocol":         {Type: acloudapi.AddonValueBool},
+		},
+	},
+	"metrics-server": {Name: "metrics-server"},
+}
+
+func TestAddonCatalogueValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		addon   acloudapi.APIAddon
+		wantErr string
+	}{
+		{name: "ingress-nginx", addon: acloudapi.APIAddon{Enabled: true, CustomValues: map[string]string{"replicas": "3", "proxyProtocol": "true", "externalTrafficPolicy": "Local"}}},
+		{name: "metrics-server", addon: acloudapi.APIAddon{Enabled: true}},
+		{name: "ingres-nginx", wantErr: "unknown addon ingres-nginx"},
+		{name: "ingress-nginx", addon: acloudapi.APIAddon{CustomValues: map[string]string{"replica": "3"}}, wantErr: "unknown custom value replica of addon ingress-nginx"},
+		{name: "ingress-nginx", addon: acloudapi.APIAddon{CustomValues: map[string]string{"replicas": "three"}}, wantErr: `invalid custom value replicas of addon ingress-nginx: "three" is not an integer`},
+		{name: "ingress-nginx", addon: acloudapi.APIAddon{CustomValues: map[string]string{"proxyProtocol": "yes"}}, wantErr: `"yes" is not a boolean`},
+		{name: "ingress-nginx", addon: acloudapi.APIAddon{CustomValues: map[string]string{"externalTrafficPolicy": "Random"}}, wantErr: `"Random" is not one of [Cluster Local]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := testAddonCatalogue.Validate(tt.name, tt.addon)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("expected error %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestClusterAddons(t *testing.T) {
+	server := acloudapitest.NewServer(acloudapitest.Opts{})
+	defer server.Close()
+	server.AddOrganisation(acloudapi.Organisation{Slug: "org1"})
+	server.AddEnvironment("org1", acloudapi.Environment{Slug: "env1"})
+	cluster := server.AddCluster(acloudapi.Cluster{
+		CustomerSlug:    "org1",
+		EnvironmentSlug: "env1",
+		Slug:            "web",
+		Addons: map[string]acloudapi.APIAddon{
+			"ingress-nginx": {Enabled: false, CustomValues: map[string]string{"replicas": "2"}},
+			"legacy-addon":  {Enabled: true},
+		},
+	})
+	ctx := context.Background()
+	client := server.Client()
+	opts := acloudapi.AddonOpts{Catalogue: testAddonCatalogue}
+
+	assertAddon := func(name string, enabled bool, customValues map[string]string) {
+		t.Helper()
+		current, _ := server.Cluster(cluster.Identity)
+		addon, ok := current.Addons[name]
+		if !ok || addon.Enabled != enabled || !maps.Equal(addon.CustomValues, customValues) {
+			t.Fatalf("unexpected addon %s: %+v", name, current.Addons)
+		}
+	}
+
+	if _, err := acloudapi.EnableClusterAddon(ctx, client, cluster, "ingress-nginx", opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertAddon("ingress-nginx", true, map[string]string{"replicas": "2"})
+
+	if _, err := acloudapi.SetClusterAddonCustomValues(ctx, client, cluster, "ingress-nginx", map[string]string{"proxyProtocol": "true", "replicas": "3"}, opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertAddon("ingress-nginx", true, map[string]string{"replicas": "3", "proxyProtocol": "true"})
+
+	if _, err := acloudapi.SetClusterAddonCustomValues(ctx, client, cluster, "ingress-nginx", map[string]string{"replicas": "many"}, opts); err == nil {
+		t.Fatal("expected an error for an invalid custom value")
+	}
+	if _, err := acloudapi.EnableClusterAddon(ctx, client, cluster, "metrics-servr", opts); err == nil || !strings.Contains(err.Error(), "unknown addon metrics-servr") {
+		t.Fatalf("expected an error for an unknown addon, got %v", err)
+	}
+	// without a catalogue, addons are not validated
+	if _, err := acloudapi.SetClusterAddonCustomValues(ctx, client, cluster, "legacy-addon", map[string]string{"mode": "strict"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertAddon("legacy-addon", true, map[string]string{"mode": "strict"})
+
+	if _, err := acloudapi.UnsetClusterAddonCustomValues(ctx, client, cluster, "ingress-nginx", []string{"replicas"}, opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertAddon("ingress-nginx", true, map[string]string{"proxyProtocol": "true"})
+
+	// an addon of the cluster that is not in the catalogue can be disabled
+	if _, err := acloudapi.DisableClusterAddon(ctx, client, cluster, "legacy-addon", opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertAddon("legacy-addon", false, map[string]string{"mode": "strict"})
+
+	addons, err := acloudapi.GetClusterAddons(ctx, client, cluster)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(addons) != 2 || addons[0].Name != "ingress-nginx" || !addons[0].Enabled || addons[1].Name != "legacy-addon" {
+		t.Fatalf("unexpected addons %+v", addons)
+	}
+}
+
+func TestGetAddonCatalogue(t *testing.T) {
+	server := acloudapitest.NewServer(acloudapitest.Opts{})
+	defer server.Close()
+	server.AddOrganisation(acloudapi.Organisation{Slug: "org1"})
+	server.AddEnvironment("org1", acloudapi.Environment{Slug: "env1"})
+	server.AddCluster(acloudapi.Cluster{
+		CustomerSlug:    "org1",
+		EnvironmentSlug: "env1",
+		Slug:            "web",
+		Addons: map[string]acloudapi.APIAddon{
+			"ingress-nginx": {Enabled: true, CustomValues: map[string]string{"replicas": "2"}},
+		},
+	})
+	cluster := server.AddCluster(acloudapi.Cluster{
+		CustomerSlug:    "org1",
+		EnvironmentSlug: "env1",
+		Slug:            "batch",
+		Addons: map[string]acloudapi.APIAddon{
+			"ingress-nginx":  {Enabled: false, CustomValues: map[string]string{"proxyProtocol": "true"}},
+			"metrics-server": {Enabled: true},
+		},
+	})
+	ctx := context.Background()
+	client := server.Client()
+
+	catalogue, err := acloudapi.GetAddonCatalogue(ctx, client, "org1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(catalogue) != 2 || len(catalogue["ingress-nginx"].CustomValues) != 2 || len(catalogue["metrics-server"].CustomValues) != 0 {
+		t.Fatalf("unexpected catalogue %+v", catalogue)
+	}
+
+	opts := acloudapi.AddonOpts{Catalogue: catalogue}
+	if _, err := acloudapi.SetClusterAddonCustomValues(ctx, client, cluster, "ingress-nginx", map[string]string{"replicas": "3"}, opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := acloudapi.SetClusterAddonCustomValues(ctx, client, cluster, "ingress-nginx", map[string]string{"replica": "3"}, opts); err == nil || !strings.Contains(err.Error(), "unknown custom value replica") {
+		t.Fatalf("expected an error for an unknown custom value, got %v", err)
+	}
+	if _, err := acloudapi.EnableClusterAddon(ctx, client, cluster, "metrics-servr", opts); err == nil || !strings.Contains(err.Error(), "unknown addon metrics-servr") {
+		t.Fatalf("expected an error for an unknown addon, got %v", err)
+	}
+}
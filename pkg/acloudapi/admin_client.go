@@ -2,6 +2,7 @@ package acloudapi
 
 import (
 	"context"
+	"iter"
 
 	"github.com/go-resty/resty/v2"
 )
@@ -9,6 +10,7 @@ import (
 type AdminClusterAPI interface {
 	GetCluster(ctx context.Context, clusterIdentity string, opts ...GetClusterOpts) (*Cluster, error)
 	ListClusters(ctx context.Context, opts ...GetClusterOpts) ([]Cluster, error)
+	ListClustersIter(ctx context.Context, opts ...GetClusterOpts) iter.Seq2[Cluster, error]
 	UpdateCluster(ctx context.Context, request AdminUpdateClusterRequest) (*Cluster, error)
 }
 
@@ -54,7 +56,11 @@ type adminClientImpl struct {
 }
 
 func NewAdminClient(authenticator Authenticator, opts ClientOpts) AdminClient {
-	return &adminClientImpl{
+	client := &adminClientImpl{
 		NewRestyClient(authenticator, opts),
 	}
+	if opts.Instrumentation != nil {
+		return &instrumentedAdminClient{client: client, instrumentation: opts.Instrumentation}
+	}
+	return client
 }
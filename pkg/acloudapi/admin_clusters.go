@@ -3,6 +3,7 @@ package acloudapi
 import (
 	"context"
 	"fmt"
+	"iter"
 )
 
 func (c *adminClientImpl) GetCluster(ctx context.Context, clusterIdentity string, opts ...GetClusterOpts) (*Cluster, error) {
@@ -36,6 +37,13 @@ func (c *adminClientImpl) ListClusters(ctx context.Context, opts ...GetClusterOp
 	return content, nil
 }
 
+// ListClustersIter returns an iterator over all clusters, requesting one page at a time
+func (c *adminClientImpl) ListClustersIter(ctx context.Context, opts ...GetClusterOpts) iter.Seq2[Cluster, error] {
+	queryParams := GetClusterOptsToQueryParams(opts, GetClusterOpts{IncludeDetails: True(), ShowCompute: True(), HideDeleted: True()})
+	clusters := IteratePaged[Cluster](ctx, c.RestyClient, fmt.Sprintf("/admin/v1/clusters%s", OptionalQueryParams(queryParams)))
+	return fixClusters(clusters, func(cluster *Cluster) string { return cluster.CustomerSlug })
+}
+
 func (c *adminClientImpl) UpdateCluster(ctx context.Context, request AdminUpdateClusterRequest) (*Cluster, error) {
 	cluster := Cluster{}
 	response, err := c.R().
@@ -129,6 +129,27 @@ const (
 	DONE                           ClusterProvisionStatus = "DONE"
 )
 
+// Cluster.Status and Cluster.DesiredStatus values
+const (
+	ClusterStatusProvisioning = "provisioning"
+	ClusterStatusRunning      = "running"
+	ClusterStatusUpgrading    = "upgrading"
+	ClusterStatusStopping     = "stopping"
+	ClusterStatusStopped      = "stopped"
+	ClusterStatusStarting     = "starting"
+	ClusterStatusDeleting     = "deleting"
+	ClusterStatusDeleted      = "deleted"
+)
+
+// NodePool.ProvisionStatus values
+const (
+	NodePoolStatusProvisioning = "PROVISIONING"
+	NodePoolStatusUpdating     = "UPDATING"
+	NodePoolStatusProvisioned  = "PROVISIONED"
+	NodePoolStatusDeleting     = "DELETING"
+	NodePoolStatusDeleted      = "DELETED"
+)
+
 // APIAddon represents an API addon.
 type APIAddon struct {
 	Enabled      bool              `json:"enabled" yaml:"Enabled"`
@@ -189,10 +210,13 @@ type UpdateCluster struct {
 	EnableAutoUpgrade       *bool   `json:"enableAutoUpgrade,omitempty" yaml:"EnableAutoUpgrade,omitempty"`
 	EnableHighAvailability  *bool   `json:"enableHighAvailability,omitempty" yaml:"EnableHighAvailability,omitempty"`
 	// Deprecated: replaced by PodSecurityStandardsProfile which offers support for selecting a specific default PSS profile. This setting does not do anything since Kubernetes v1.23
-	EnablePodSecurityStandards  *bool                   `json:"enablePodSecurityStandards,omitempty" yaml:"EnablePodSecurityStandards,omitempty"`
-	PodSecurityStandardsProfile *string                 `json:"podSecurityStandardsProfile,omitempty" yaml:"PodSecurityStandardsProfile,omitempty"`
-	DeleteProtection            *bool                   `json:"deleteProtection,omitempty" yaml:"DeleteProtection,omitempty"`
-	IPWhitelist                 []string                `json:"ipWhitelist,omitempty" yaml:"IpWhitelist,omitempty"`
+	EnablePodSecurityStandards  *bool    `json:"enablePodSecurityStandards,omitempty" yaml:"EnablePodSecurityStandards,omitempty"`
+	PodSecurityStandardsProfile *string  `json:"podSecurityStandardsProfile,omitempty" yaml:"PodSecurityStandardsProfile,omitempty"`
+	DeleteProtection            *bool    `json:"deleteProtection,omitempty" yaml:"DeleteProtection,omitempty"`
+	IPWhitelist                 []string `json:"ipWhitelist,omitempty" yaml:"IpWhitelist,omitempty"`
+	// IPWhitelistEntries replaces the IP whitelist including the descriptions of the entries, and takes precedence over
+	// IPWhitelist. It is sent in the same format as CreateCluster.IPWhitelist.
+	IPWhitelistEntries          []IPWhitelistEntry      `json:"-" yaml:"IpWhitelistEntries,omitempty"`
 	Addons                      map[string]APIAddon     `json:"addons,omitempty" yaml:"Addons,omitempty"`
 	AutoscalerSettings          *AutoscalerSettings     `json:"clusterAutoscalerSettings,omitempty" yaml:"ClusterAutoscalerSettings,omitempty"`
 	MaintenanceScheduleIdentity *string                 `json:"maintenanceScheduleIdentity,omitempty" yaml:"MaintenanceScheduleIdentity,omitempty"`
@@ -1,6 +1,9 @@
 package acloudapi
 
 import (
+	"context"
+	"net/http"
+
 	"github.com/go-resty/resty/v2"
 )
 
@@ -8,6 +11,37 @@ type Authenticator interface {
 	Authenticate(c *resty.Client, r *resty.Request) error
 }
 
+// RefreshableAuthenticator is an Authenticator with credentials that can be refreshed. When the API responds with
+// 401 Unauthorized, the credentials are refreshed and the request is retried once.
+type RefreshableAuthenticator interface {
+	Authenticator
+	Refresh(ctx context.Context) error
+}
+
+type reauthenticatedKey struct{}
+
+// addReauthenticateRetry retries a request once after refreshing the credentials of the authenticator, when the API
+// responds with 401 Unauthorized
+func addReauthenticateRetry(client *resty.Client, authenticator RefreshableAuthenticator) {
+	if client.RetryCount < 1 {
+		client.SetRetryCount(1)
+	}
+	client.AddRetryCondition(func(response *resty.Response, err error) bool {
+		if err != nil || response == nil || response.StatusCode() != http.StatusUnauthorized {
+			return false
+		}
+		ctx := response.Request.Context()
+		if ctx.Value(reauthenticatedKey{}) != nil {
+			return false
+		}
+		if err := authenticator.Refresh(ctx); err != nil {
+			return false
+		}
+		response.Request.SetContext(context.WithValue(ctx, reauthenticatedKey{}, true))
+		return true
+	})
+}
+
 type personalAccessTokenAuthenticator struct {
 	token string
 }
@@ -2,6 +2,7 @@ package acloudapi
 
 import (
 	"context"
+	"iter"
 
 	"github.com/go-resty/resty/v2"
 )
@@ -10,6 +11,7 @@ type ClusterAPI interface {
 	GetClusters(ctx context.Context, opts ...GetClusterOpts) ([]Cluster, error)
 	GetClustersByOrg(ctx context.Context, organisationSlug string, opts ...GetClusterOpts) ([]Cluster, error)
 	GetClustersByOrgAndEnv(ctx context.Context, organisationSlug, environmentSlug string, opts ...GetClusterOpts) ([]Cluster, error)
+	ListClustersIter(ctx context.Context, organisationSlug string, opts ...GetClusterOpts) iter.Seq2[Cluster, error]
 
 	GetCluster(ctx context.Context, organisationSlug, environmentSlug, cluster string, opts ...GetClusterOpts) (*Cluster, error)
 	GetClusterOIDCConfig(ctx context.Context, organisationSlug, environmentSlug, clusterSlug string) (*ClusterMetadataResponse, error)
@@ -40,6 +42,7 @@ type EnvironmentsAPI interface {
 	UpdateEnvironment(ctx context.Context, updateEnvironment UpdateEnvironment, org, env string) (*Environment, error)
 	DeleteEnvironment(ctx context.Context, org, env string) error
 	GetEnvironments(ctx context.Context, organisationSlug string) ([]Environment, error)
+	ListEnvironmentsIter(ctx context.Context, organisationSlug string) iter.Seq2[Environment, error]
 }
 
 type NodePoolsAPI interface {
@@ -123,7 +126,11 @@ type clientImpl struct {
 }
 
 func NewClient(authenticator Authenticator, opts ClientOpts) Client {
-	return &clientImpl{
+	client := &clientImpl{
 		NewRestyClient(authenticator, opts),
 	}
+	if opts.Instrumentation != nil {
+		return &instrumentedClient{client: client, instrumentation: opts.Instrumentation}
+	}
+	return client
 }
@@ -0,0 +1,230 @@
+package acloudapi
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/netip"
+	"slices"
+)
+
+// CreateClusterValidationAPI is the part of the Client used by ValidateCreateCluster
+type CreateClusterValidationAPI interface {
+	CloudAccountAPI
+	CloudProvidersAPI
+	UpdateChannelAPI
+}
+
+// CreateClusterValidationOpts are the options of CreateCluster.Validate and ValidateCreateCluster
+type CreateClusterValidationOpts struct {
+	// AddonCatalogue is used to validate the addons of the request. Addons are not validated when it is nil.
+	AddonCatalogue AddonCatalogue
+}
+
+func mergeCreateClusterValidationOpts(opts []CreateClusterValidationOpts) CreateClusterValidationOpts {
+	merged := CreateClusterValidationOpts{}
+	for _, opt := range opts {
+		if opt.AddonCatalogue != nil {
+			merged.AddonCatalogue = opt.AddonCatalogue
+		}
+	}
+	return merged
+}
+
+// Validate checks the request without contacting the API: the name, version, subnets, IP whitelist and node pools, and
+// the addons when CreateClusterValidationOpts.AddonCatalogue is set. All problems are returned at once as a
+// *ValidationError.
+func (c CreateCluster) Validate(opts ...CreateClusterValidationOpts) error {
+	validationError := &ValidationError{}
+	c.validate(validationError, mergeCreateClusterValidationOpts(opts))
+	return validationError.errorOrNil()
+}
+
+func (c CreateCluster) validate(validationError *ValidationError, opts CreateClusterValidationOpts) {
+	if c.Name == "" {
+		validationError.add("name", "must not be empty")
+	}
+	if c.Version != "" {
+		if _, err := ParseVersion(c.Version); err != nil {
+			validationError.add("version", "%s", err)
+		}
+	}
+
+	serviceSubnet, serviceSubnetValid := validateSubnet(validationError, "serviceSubnet", c.ServiceSubnet)
+	clusterPodSubnet, clusterPodSubnetValid := validateSubnet(validationError, "clusterPodSubnet", c.ClusterPodSubnet)
+	if serviceSubnetValid && clusterPodSubnetValid && serviceSubnet.Overlaps(clusterPodSubnet) {
+		validationError.add("clusterPodSubnet", "%s overlaps with service subnet %s", clusterPodSubnet, serviceSubnet)
+	}
+
+	cidrs := map[netip.Prefix]bool{}
+	for i, entry := range c.IPWhitelist {
+		field := fmt.Sprintf("ipWhitelist[%d].cidr", i)
+		prefix, err := netip.ParsePrefix(entry.Cidr)
+		if err != nil {
+			validationError.add(field, "invalid CIDR %q", entry.Cidr)
+			continue
+		}
+		if prefix != prefix.Masked() {
+			validationError.add(field, "%s has host bits set, use %s", prefix, prefix.Masked())
+			continue
+		}
+		if cidrs[prefix] {
+			validationError.add(field, "duplicate CIDR %s", prefix)
+		}
+		cidrs[prefix] = true
+	}
+
+	names := map[string]bool{}
+	for i, nodePool := range c.NodePools {
+		field := fmt.Sprintf("nodePools[%d]", i)
+		if nodePool.Name == "" {
+			validationError.add(field+".name", "must not be empty")
+		} else if names[nodePool.Name] {
+			validationError.add(field+".name", "duplicate node pool %s", nodePool.Name)
+		}
+		names[nodePool.Name] = true
+		if nodePool.NodeSize == "" {
+			validationError.add(field+".nodeSize", "must not be empty")
+		}
+		if nodePool.MinSize < 0 {
+			validationError.add(field+".minSize", "must not be negative")
+		}
+		if nodePool.MaxSize < nodePool.MinSize {
+			validationError.add(field+".maxSize", "must not be less than minSize %d", nodePool.MinSize)
+		}
+		if nodePool.UpgradeStrategy != "" && !slices.Contains(AllNodePoolUpgradeStrategies, nodePool.UpgradeStrategy) {
+			validationError.add(field+".upgradeStrategy", "unknown upgrade strategy %s", nodePool.UpgradeStrategy)
+		}
+		if nodePool.SecurityUpdatesOnJoin != "" && !slices.Contains(AllNodePoolSecurityUpdatesOnJoin, nodePool.SecurityUpdatesOnJoin) {
+			validationError.add(field+".securityUpdatesOnJoin", "unknown value %s", nodePool.SecurityUpdatesOnJoin)
+		}
+	}
+
+	if opts.AddonCatalogue != nil {
+		for _, name := range slices.Sorted(maps.Keys(c.Addons)) {
+			if err := opts.AddonCatalogue.Validate(name, c.Addons[name]); err != nil {
+				validationError.add("addons."+name, "%s", err)
+			}
+		}
+	}
+}
+
+// validateSubnet parses an optional subnet, and returns false if it is empty or invalid
+func validateSubnet(validationError *ValidationError, field, subnet string) (netip.Prefix, bool) {
+	if subnet == "" {
+		return netip.Prefix{}, false
+	}
+	prefix, err := netip.ParsePrefix(subnet)
+	if err != nil {
+		validationError.add(field, "invalid CIDR %q", subnet)
+		return netip.Prefix{}, false
+	}
+	return prefix.Masked(), true
+}
+
+// ValidateCreateCluster checks the request like CreateCluster.Validate, and additionally checks it against the
+// organisation: the cloud account must exist and be enabled, the region must be available to it, the availability
+// zones of the node pools must be in the region, multiple availability zones require a region with more than one
+// zone, the node sizes must be node types of the cloud provider and the update channel must be available.
+//
+// All problems are returned at once as a *ValidationError. Errors of the API calls are returned as is.
+func ValidateCreateCluster(ctx context.Context, client CreateClusterValidationAPI, organisationSlug string, create CreateCluster, opts ...CreateClusterValidationOpts) error {
+	validationError := &ValidationError{}
+	create.validate(validationError, mergeCreateClusterValidationOpts(opts))
+
+	if create.UpdateChannel != "" {
+		updateChannels, err := client.GetUpdateChannels(ctx, organisationSlug)
+		if err != nil {
+			return fmt.Errorf("failed to get update channels: %w", err)
+		}
+		index := slices.IndexFunc(updateChannels, func(updateChannel UpdateChannelResponse) bool { return updateChannel.Name == create.UpdateChannel })
+		if index < 0 {
+			validationError.add("updateChannel", "update channel %s does not exist", create.UpdateChannel)
+		} else if !updateChannels[index].Available {
+			validationError.add("updateChannel", "update channel %s is not available", create.UpdateChannel)
+		}
+	}
+
+	if create.CloudAccountIdentity == "" {
+		return validationError.errorOrNil()
+	}
+	cloudAccounts, err := client.GetCloudAccounts(ctx, organisationSlug)
+	if err != nil {
+		return fmt.Errorf("failed to get cloud accounts: %w", err)
+	}
+	index := slices.IndexFunc(cloudAccounts, func(cloudAccount CloudAccount) bool { return cloudAccount.Identity == create.CloudAccountIdentity })
+	if index < 0 {
+		validationError.add("cloudAccountIdentity", "cloud account %s does not exist", create.CloudAccountIdentity)
+		return validationError.errorOrNil()
+	}
+	cloudAccount := cloudAccounts[index]
+	if !cloudAccount.Enabled {
+		validationError.add("cloudAccountIdentity", "cloud account %s is disabled", cloudAccount.DisplayName)
+	}
+	cloudProvider := cloudAccount.CloudProfile.CloudProvider
+
+	if err := validateRegion(ctx, client, validationError, organisationSlug, cloudAccount, create); err != nil {
+		return err
+	}
+
+	if slices.ContainsFunc(create.NodePools, func(nodePool NodePools) bool { return nodePool.NodeSize != "" }) {
+		nodeTypes, err := client.GetNodeTypes(ctx, cloudProvider)
+		if err != nil {
+			return fmt.Errorf("failed to get node types of cloud provider %s: %w", cloudProvider, err)
+		}
+		for i, nodePool := range create.NodePools {
+			if nodePool.NodeSize == "" {
+				continue
+			}
+			if !slices.ContainsFunc(nodeTypes, func(nodeType NodeType) bool { return nodeType.Type == nodePool.NodeSize }) {
+				validationError.add(fmt.Sprintf("nodePools[%d].nodeSize", i), "unknown node size %s for cloud provider %s", nodePool.NodeSize, cloudProvider)
+			}
+		}
+	}
+	return validationError.errorOrNil()
+}
+
+// validateRegion checks the region and the availability zones of the request
+func validateRegion(ctx context.Context, client CreateClusterValidationAPI, validationError *ValidationError, organisationSlug string, cloudAccount CloudAccount, create CreateCluster) error {
+	if create.Region == "" {
+		validationError.add("region", "must not be empty")
+		return nil
+	}
+	cloudProvider := cloudAccount.CloudProfile.CloudProvider
+	regions, err := client.GetRegions(ctx, organisationSlug, cloudProvider)
+	if err != nil {
+		return fmt.Errorf("failed to get regions of cloud provider %s: %w", cloudProvider, err)
+	}
+	index := slices.IndexFunc(regions, func(region Region) bool { return region.Slug == create.Region })
+	switch {
+	case index < 0:
+		validationError.add("region", "region %s does not exist for cloud provider %s", create.Region, cloudProvider)
+		return nil
+	case !regions[index].Available:
+		validationError.add("region", "region %s is not available", create.Region)
+	case len(cloudAccount.CloudProfile.Regions) > 0 && !slices.Contains(cloudAccount.CloudProfile.Regions, create.Region):
+		validationError.add("region", "region %s is not supported by cloud profile %s", create.Region, cloudAccount.CloudProfile.DisplayName)
+	}
+
+	if !create.EnableMultiAvailabilityZones && !slices.ContainsFunc(create.NodePools, func(nodePool NodePools) bool { return nodePool.AvailabilityZone != "" }) {
+		return nil
+	}
+	availabilityZones, err := client.GetAvailabilityZones(ctx, organisationSlug, cloudProvider, create.Region)
+	if err != nil {
+		return fmt.Errorf("failed to get availability zones of region %s: %w", create.Region, err)
+	}
+	if create.EnableMultiAvailabilityZones && len(availabilityZones) < 2 {
+		validationError.add("enableMultiAvailabilityZones", "region %s has %d availability zone(s)", create.Region, len(availabilityZones))
+	}
+	for i, nodePool := range create.NodePools {
+		if nodePool.AvailabilityZone == "" {
+			continue
+		}
+		if !slices.ContainsFunc(availabilityZones, func(availabilityZone AvailabilityZone) bool {
+			return availabilityZone.Slug == nodePool.AvailabilityZone
+		}) {
+			validationError.add(fmt.Sprintf("nodePools[%d].availabilityZone", i), "availability zone %s is not in region %s", nodePool.AvailabilityZone, create.Region)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package acloudapi_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/avisi-cloud/go-client/pkg/acloudapi"
+	"github.com/avisi-cloud/go-client/pkg/acloudapi/acloudapitest"
+)
+
+func TestCreateClusterValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		create acloudapi.CreateCluster
+		opts   acloudapi.CreateClusterValidationOpts
+		want   []string
+	}{
+		{
+			name: "valid",
+			create: acloudapi.CreateCluster{
+				Name:             "cluster1",
+				Version:          "1.31.2",
+				ServiceSubnet:    "10.96.0.0/12",
+				ClusterPodSubnet: "10.244.0.0/16",
+				IPWhitelist:      []acloudapi.IPWhitelistEntry{{Cidr: "192.0.2.0/24"}, {Cidr: "2001:db8::/32"}},
+				NodePools:        []acloudapi.NodePools{{Name: "workers", NodeSize: "small", MinSize: 1, MaxSize: 3}},
+			},
+		},
+		{
+			name: "invalid",
+			create: acloudapi.CreateCluster{
+				Version:          "latest",
+				ServiceSubnet:    "10.96.0.0/12",
+				ClusterPodSubnet: "10.100.0.0/16",
+				IPWhitelist:      []acloudapi.IPWhitelistEntry{{Cidr: "192.0.2.0/24"}, {Cidr: "192.0.2.1/24"}, {Cidr: "192.0.2.0/24"}, {Cidr: "office"}},
+				NodePools: []acloudapi.NodePools{
+					{Name: "workers", NodeSize: "small", MinSize: 3, MaxSize: 1, UpgradeStrategy: "RECREATE"},
+					{Name: "workers"},
+				},
+				Addons: map[string]acloudapi.APIAddon{
+					"metrics-server": {Enabled: true},
+					"metrics-servr":  {Enabled: true},
+				},
+			},
+			opts: acloudapi.CreateClusterValidationOpts{AddonCatalogue: testAddonCatalogue},
+			want: []string{
+				"name: must not be empty",
+				`version: invalid version "latest": "latest" is not a number`,
+				"clusterPodSubnet: 10.100.0.0/16 overlaps with service subnet 10.96.0.0/12",
+				"ipWhitelist[1].cidr: 192.0.2.1/24 has host bits set, use 192.0.2.0/24",
+				"ipWhitelist[2].cidr: duplicate CIDR 192.0.2.0/24",
+				`ipWhitelist[3].cidr: invalid CIDR "office"`,
+				"nodePools[0].maxSize: must not be less than minSize 3",
+				"nodePools[0].upgradeStrategy: unknown upgrade strategy RECREATE",
+				"nodePools[1].name: duplicate node pool workers",
+				"nodePools[1].nodeSize: must not be empty",
+				"addons.metrics-servr: unknown addon metrics-servr",
+			},
+		},
+		{
+			name: "addons without a catalogue",
+			create: acloudapi.CreateCluster{
+				Name:   "cluster1",
+				Addons: map[string]acloudapi.APIAddon{"any-addon": {Enabled: true, CustomValues: map[string]string{"any": "value"}}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertValidationError(t, tt.create.Validate(tt.opts), tt.want)
+		})
+	}
+}
+
+func TestValidateCreateCluster(t *testing.T) {
+	server := acloudapitest.NewServer(acloudapitest.Opts{})
+	defer server.Close()
+	server.AddOrganisation(acloudapi.Organisation{Slug: "org1"})
+	server.AddCloudProvider(acloudapi.CloudProvider{Slug: "aws", Available: true})
+	server.AddRegion("aws", acloudapi.Region{Slug: "eu-west-1", Available: true})
+	server.AddRegion("aws", acloudapi.Region{Slug: "eu-south-1", Available: true})
+	server.AddRegion("aws", acloudapi.Region{Slug: "us-east-1", Available: true})
+	server.AddAvailabilityZone("aws", "eu-west-1", acloudapi.AvailabilityZone{Slug: "eu-west-1a"})
+	server.AddAvailabilityZone("aws", "eu-west-1", acloudapi.AvailabilityZone{Slug: "eu-west-1b"})
+	server.AddAvailabilityZone("aws", "eu-south-1", acloudapi.AvailabilityZone{Slug: "eu-south-1a"})
+	server.AddNodeType("aws", acloudapi.NodeType{Type: "t3.large", CPU: 2, Memory: 8})
+	server.AddUpdateChannel(acloudapi.UpdateChannelResponse{Name: "stable", Available: true, KubernetesClusterVersion: "1.31"})
+	server.AddUpdateChannel(acloudapi.UpdateChannelResponse{Name: "legacy", KubernetesClusterVersion: "1.27"})
+	cloudAccount := server.AddCloudAccount("org1", acloudapi.CloudAccount{
+		DisplayName:  "production",
+		Enabled:      true,
+		CloudProfile: acloudapi.CloudProfile{DisplayName: "aws-eu", CloudProvider: "aws", Regions: []string{"eu-west-1", "eu-south-1"}},
+	})
+	ctx := context.Background()
+	client := server.Client()
+
+	tests := []struct {
+		name   string
+		create acloudapi.CreateCluster
+		opts   acloudapi.CreateClusterValidationOpts
+		want   []string
+	}{
+		{
+			name: "valid",
+			create: acloudapi.CreateCluster{
+				Name:                         "cluster1",
+				CloudAccountIdentity:         cloudAccount.Identity,
+				Region:                       "eu-west-1",
+				UpdateChannel:                "stable",
+				EnableMultiAvailabilityZones: true,
+				NodePools:                    []acloudapi.NodePools{{Name: "workers", NodeSize: "t3.large", MinSize: 1, MaxSize: 3, AvailabilityZone: "eu-west-1b"}},
+			},
+		},
+		{
+			name: "invalid",
+			create: acloudapi.CreateCluster{
+				Name:                         "cluster1",
+				CloudAccountIdentity:         cloudAccount.Identity,
+				Region:                       "eu-south-1",
+				UpdateChannel:                "legacy",
+				EnableMultiAvailabilityZones: true,
+				NodePools:                    []acloudapi.NodePools{{Name: "workers", NodeSize: "m5.large", MinSize: 1, MaxSize: 3, AvailabilityZone: "eu-west-1a"}},
+			},
+			want: []string{
+				"updateChannel: update channel legacy is not available",
+				"enableMultiAvailabilityZones: region eu-south-1 has 1 availability zone(s)",
+				"nodePools[0].availabilityZone: availability zone eu-west-1a is not in region eu-south-1",
+				"nodePools[0].nodeSize: unknown node size m5.large for cloud provider aws",
+			},
+		},
+		{
+			name:   "region outside of the cloud profile",
+			create: acloudapi.CreateCluster{Name: "cluster1", CloudAccountIdentity: cloudAccount.Identity, Region: "us-east-1", UpdateChannel: "beta"},
+			want: []string{
+				"updateChannel: update channel beta does not exist",
+				"region: region us-east-1 is not supported by cloud profile aws-eu",
+			},
+		},
+		{
+			name:   "unknown cloud account",
+			create: acloudapi.CreateCluster{Name: "cluster1", CloudAccountIdentity: "unknown", Region: "eu-west-1"},
+			want:   []string{"cloudAccountIdentity: cloud account unknown does not exist"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertValidationError(t, acloudapi.ValidateCreateCluster(ctx, client, "org1", tt.create), tt.want)
+		})
+	}
+
+	err := acloudapi.ValidateCreateCluster(ctx, client, "unknown", acloudapi.CreateCluster{Name: "cluster1", UpdateChannel: "stable"})
+	if !errors.Is(err, acloudapi.ErrNotFound) {
+		t.Fatalf("expected the API error, got %v", err)
+	}
+}
+
+func assertValidationError(t *testing.T, err error, want []string) {
+	t.Helper()
+	if len(want) == 0 {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return
+	}
+	var validationError *acloudapi.ValidationError
+	if !errors.As(err, &validationError) {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	got := make([]string, len(validationError.FieldErrors))
+	for i, fieldError := range validationError.FieldErrors {
+		got[i] = fieldError.String()
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("unexpected field errors:\n%q\nwant:\n%q", got, want)
+	}
+}
@@ -3,54 +3,55 @@ package acloudapi
 import (
 	"context"
 	"fmt"
+	"iter"
 	"sort"
 	"strings"
-	"sync"
 )
 
 type clustersResponse struct {
-	clusters []Cluster
-	err      error
+	organisationSlug string
+	clusters         []Cluster
+	err              error
 }
 
+// GetClusters returns the clusters of all organisations the user is a member of.
+//
+// By default, an error for any of the organisations fails the whole call. When AllowPartialResults is set, the clusters
+// of all organisations that succeeded are returned together with a *PartialResultError listing the failed organisations.
 func (c *clientImpl) GetClusters(ctx context.Context, opts ...GetClusterOpts) ([]Cluster, error) {
 	memberships, err := c.GetMemberships(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get memberships/organisations: %v", err)
+		return nil, fmt.Errorf("failed to get memberships/organisations: %w", err)
 	}
 
-	numberOfRequests := len(memberships)
-	responseChan := make(chan clustersResponse, numberOfRequests)
-	wg := sync.WaitGroup{}
-	for _, membership := range memberships {
-		wg.Add(1)
-		go func(mbs Membership) {
-			clustersByOrg, err := c.GetClustersByOrg(ctx, mbs.Slug, opts...)
-			resp := clustersResponse{
-				clusters: clustersByOrg,
-			}
-			if err != nil {
-				resp.err = fmt.Errorf("failed to get clusters for organisation %s: %w", mbs.Slug, err)
-			}
-			responseChan <- resp
-			wg.Done()
-		}(membership)
-	}
+	responses := make([]clustersResponse, len(memberships))
+	runConcurrently(len(memberships), c.fanOutConcurrency(), func(i int) {
+		mbs := memberships[i]
+		clustersByOrg, err := c.GetClustersByOrg(ctx, mbs.Slug, opts...)
+		responses[i] = clustersResponse{
+			organisationSlug: mbs.Slug,
+			clusters:         clustersByOrg,
+			err:              err,
+		}
+	})
 
-	wg.Wait()
-	close(responseChan)
+	allowPartialResults := mergeGetClusterOpts(opts, GetClusterOpts{AllowPartialResults: False()}).AllowPartialResults
+	partialResultError := &PartialResultError{}
 	clusters := make([]Cluster, 0)
-	for i := 0; i < numberOfRequests; i++ {
-		response := <-responseChan
+	for _, response := range responses {
 		if response.err != nil {
-			return nil, response.err // returning first error
+			if !*allowPartialResults {
+				return nil, fmt.Errorf("failed to get clusters for organisation %s: %w", response.organisationSlug, response.err) // returning first error
+			}
+			partialResultError.add(response.err, PartialFailure{OrganisationSlug: response.organisationSlug})
+			continue
 		}
 
 		clusters = append(clusters, response.clusters...)
 	}
 	SortClusters(clusters)
 
-	return clusters, nil
+	return clusters, partialResultError.errorOrNil()
 }
 
 func (c *clientImpl) GetClustersByOrg(ctx context.Context, org string, opts ...GetClusterOpts) ([]Cluster, error) {
@@ -62,6 +63,14 @@ func (c *clientImpl) GetClustersByOrg(ctx context.Context, org string, opts ...G
 	return c.marshalClustersFromPagedResult(org, pagedResult)
 }
 
+// ListClustersIter returns an iterator over all clusters of an organisation, requesting one page at a time.
+// Unlike GetClustersByOrg, the clusters are returned in the order of the API.
+func (c *clientImpl) ListClustersIter(ctx context.Context, org string, opts ...GetClusterOpts) iter.Seq2[Cluster, error] {
+	queryParams := GetClusterOptsToQueryParams(opts, GetClusterOpts{ShowCompute: True()})
+	clusters := IteratePaged[Cluster](ctx, c.RestyClient, fmt.Sprintf("/api/v1/orgs/%s/clusters%s", org, OptionalQueryParams(queryParams)))
+	return fixClusters(clusters, func(cluster *Cluster) string { return org })
+}
+
 func (c *clientImpl) GetClustersByOrgAndEnv(ctx context.Context, org, env string, opts ...GetClusterOpts) ([]Cluster, error) {
 	queryParams := GetClusterOptsToQueryParams(opts, GetClusterOpts{ShowCompute: True()})
 	pagedResult, err := c.GetPaged(ctx, fmt.Sprintf("/api/v1/orgs/%s/clusters/%s%s", org, env, OptionalQueryParams(queryParams)))
@@ -179,6 +188,20 @@ func FixCluster(cluster *Cluster, org string) {
 	cluster.DesiredStatus = FixStatus(cluster.DesiredStatus) // temporary map 'started' to 'running'
 }
 
+// fixClusters applies FixCluster to every cluster of the iterator, using org to get the organisation of the cluster
+func fixClusters(clusters iter.Seq2[Cluster, error], org func(cluster *Cluster) string) iter.Seq2[Cluster, error] {
+	return func(yield func(Cluster, error) bool) {
+		for cluster, err := range clusters {
+			if err == nil {
+				FixCluster(&cluster, org(&cluster))
+			}
+			if !yield(cluster, err) {
+				return
+			}
+		}
+	}
+}
+
 func FixStatus(status string) string {
 	if status == "started" {
 		return "running"
@@ -190,6 +213,9 @@ type GetClusterOpts struct {
 	IncludeDetails *bool
 	ShowCompute    *bool
 	HideDeleted    *bool // admin-api only
+	// AllowPartialResults returns the clusters of all organisations that succeeded together with a *PartialResultError,
+	// instead of failing on the first error. Only used by GetClusters
+	AllowPartialResults *bool
 }
 
 func OptionalQueryParams(queryParams string) string {
@@ -215,6 +241,9 @@ func mergeGetClusterOpts(opts []GetClusterOpts, defaults GetClusterOpts) GetClus
 		if opt.HideDeleted != nil {
 			merged.HideDeleted = opt.HideDeleted
 		}
+		if opt.AllowPartialResults != nil {
+			merged.AllowPartialResults = opt.AllowPartialResults
+		}
 	}
 	return setDefaults(merged, defaults)
 }
@@ -229,6 +258,9 @@ func setDefaults(merged GetClusterOpts, defaults GetClusterOpts) GetClusterOpts
 	if merged.HideDeleted == nil {
 		merged.HideDeleted = defaults.HideDeleted
 	}
+	if merged.AllowPartialResults == nil {
+		merged.AllowPartialResults = defaults.AllowPartialResults
+	}
 	return merged
 }
 
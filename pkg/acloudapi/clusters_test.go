@@ -2,6 +2,7 @@ package acloudapi
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -135,4 +136,47 @@ func TestGetClustersErrorMessage(t *testing.T) {
 	if !strings.Contains(err.Error(), want) {
 		t.Fatalf("unexpected error: %v", err)
 	}
+	var apiError *APIError
+	if !errors.As(err, &apiError) || apiError.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected wrapped *APIError, got %v", err)
+	}
+}
+
+func TestGetClustersAllowPartialResults(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/memberships", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"content":[{"slug":"org1"},{"slug":"org2"}],"last":true}`)
+	})
+	mux.HandleFunc("/api/v1/orgs/org1/clusters", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"content":[{"slug":"cluster1"}],"last":true}`)
+	})
+	mux.HandleFunc("/api/v1/orgs/org2/clusters", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"message":"denied"}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c := &clientImpl{RestyClient: NewRestyClient(nil, ClientOpts{APIUrl: server.URL})}
+	clusters, err := c.GetClusters(context.Background(), GetClusterOpts{AllowPartialResults: True()})
+	if len(clusters) != 1 || clusters[0].Identifier() != "org1//cluster1" {
+		t.Fatalf("unexpected clusters: %+v", clusters)
+	}
+	var partialResultError *PartialResultError
+	if !errors.As(err, &partialResultError) {
+		t.Fatalf("expected *PartialResultError, got %v", err)
+	}
+	if len(partialResultError.Failures) != 1 || partialResultError.Failures[0].OrganisationSlug != "org2" {
+		t.Fatalf("unexpected failures: %+v", partialResultError.Failures)
+	}
+	if !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected error to match ErrForbidden, got %v", err)
+	}
+
+	if _, err := c.GetClusters(context.Background()); err == nil || errors.As(err, &partialResultError) {
+		t.Fatalf("expected first error without AllowPartialResults, got %v", err)
+	}
 }
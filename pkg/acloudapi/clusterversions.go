@@ -2,7 +2,7 @@ package acloudapi
 
 import (
 	"context"
-	"sort"
+	"slices"
 )
 
 func (c *clientImpl) GetClusterVersions(ctx context.Context) ([]ClusterVersion, error) {
@@ -15,9 +15,9 @@ func (c *clientImpl) GetClusterVersions(ctx context.Context) ([]ClusterVersion,
 		return nil, err
 	}
 
-	// sort result
-	sort.SliceStable(clusterVersions, func(i, j int) bool {
-		return clusterVersions[i].Version < clusterVersions[j].Version
+	// sort result, lowest version first
+	slices.SortStableFunc(clusterVersions, func(a, b ClusterVersion) int {
+		return CompareVersions(a.Version, b.Version)
 	})
 	return clusterVersions, nil
 }
@@ -0,0 +1,324 @@
+package acloudapi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// EnvConfigFile overrides the location of the config file
+	EnvConfigFile = "ACLOUD_CONFIG"
+	// EnvProfile selects the profile of the config file
+	EnvProfile = "ACLOUD_PROFILE"
+	// EnvAPIUrl sets the URL of the API
+	EnvAPIUrl = "ACLOUD_URL"
+	// EnvPersonalAccessToken sets the personal access token
+	EnvPersonalAccessToken = "ACLOUD_PAT"
+	// EnvOrganisation sets the default organisation
+	EnvOrganisation = "ACLOUD_ORGANISATION"
+	// EnvTokenURL sets the OAuth2 token URL, used for client-credentials authentication
+	EnvTokenURL = "ACLOUD_TOKEN_URL"
+	// EnvClientID sets the OAuth2 client ID, used for client-credentials authentication
+	EnvClientID = "ACLOUD_CLIENT_ID"
+	// EnvClientSecret sets the OAuth2 client secret, used for client-credentials authentication
+	EnvClientSecret = "ACLOUD_CLIENT_SECRET"
+
+	// DefaultProfile is the profile used when no profile has been selected
+	DefaultProfile = "default"
+)
+
+// ConfigFile is the config file containing one or more named profiles, by default located at ~/.acloud/config.yaml:
+//
+//	currentProfile: production
+//	profiles:
+//	  production:
+//	    apiUrl: https://api.avisi.cloud
+//	    token: <personal-access-token>
+//	    organisation: my-organisation
+//	  ci:
+//	    apiUrl: https://api.avisi.cloud
+//	    clientCredentials:
+//	      tokenUrl: https://example.com/oauth2/token
+//	      clientId: ci
+//	      clientSecret: <client-secret>
+type ConfigFile struct {
+	CurrentProfile string             `yaml:"currentProfile,omitempty"`
+	Profiles       map[string]Profile `yaml:"profiles"`
+}
+
+// Profile is a named set of settings in the ConfigFile
+type Profile struct {
+	APIUrl            string                    `yaml:"apiUrl,omitempty"`
+	Token             string                    `yaml:"token,omitempty"`
+	ClientCredentials *ProfileClientCredentials `yaml:"clientCredentials,omitempty"`
+	Organisation      string                    `yaml:"organisation,omitempty"`
+	UserAgent         string                    `yaml:"userAgent,omitempty"`
+}
+
+// ProfileClientCredentials configures OAuth2 client-credentials authentication for a Profile
+type ProfileClientCredentials struct {
+	TokenURL     string   `yaml:"tokenUrl"`
+	ClientID     string   `yaml:"clientId"`
+	ClientSecret string   `yaml:"clientSecret"`
+	Scopes       []string `yaml:"scopes,omitempty"`
+}
+
+// LoadClientConfigOpts are the options of LoadClientConfig. Every option that is set takes precedence over
+// the environment variables and the config file.
+type LoadClientConfigOpts struct {
+	// ConfigFile is the path of the config file, defaults to $ACLOUD_CONFIG or ~/.acloud/config.yaml
+	ConfigFile string
+	// Profile is the name of the profile to use, defaults to $ACLOUD_PROFILE, the currentProfile of the config file or "default"
+	Profile string
+	// Organisation is the default organisation
+	Organisation string
+	// ClientOpts are used as the base of the returned ClientOpts. APIUrl and UserAgent are only overridden when empty.
+	ClientOpts ClientOpts
+	// LookupEnv is used to look up environment variables, defaults to os.LookupEnv
+	LookupEnv func(key string) (string, bool)
+}
+
+// ClientConfig is the result of LoadClientConfig, used to create a client:
+//
+//	config, err := acloudapi.LoadClientConfig()
+//	client := acloudapi.NewClient(config.Authenticator, config.ClientOpts)
+type ClientConfig struct {
+	// Profile is the name of the profile that has been used, empty if no profile was found
+	Profile string
+	// Organisation is the default organisation, may be empty
+	Organisation string
+	// ClientOpts are the options for NewClient and NewAdminClient
+	ClientOpts ClientOpts
+	// Authenticator is the authenticator for NewClient and NewAdminClient
+	Authenticator Authenticator
+}
+
+// ConfigError is returned by LoadClientConfig when the configuration is invalid
+type ConfigError struct {
+	// Source is the config file or "environment"
+	Source string
+	// Key is the offending key, for example "profiles.production.apiUrl" or "ACLOUD_URL"
+	Key     string
+	Message string
+}
+
+func (e *ConfigError) Error() string {
+	if e.Key == "" {
+		return fmt.Sprintf("invalid configuration in %s: %s", e.Source, e.Message)
+	}
+	return fmt.Sprintf("invalid configuration %s in %s: %s", e.Key, e.Source, e.Message)
+}
+
+const sourceEnvironment = "environment"
+
+// LoadClientConfig builds the ClientOpts and Authenticator from the environment variables and the config file.
+//
+// Settings are resolved in the following order, the first one that is set wins:
+//  1. LoadClientConfigOpts
+//  2. environment variables (ACLOUD_URL, ACLOUD_PAT, ACLOUD_ORGANISATION, ACLOUD_TOKEN_URL, ACLOUD_CLIENT_ID and ACLOUD_CLIENT_SECRET)
+//  3. the selected profile of the config file
+//  4. defaults, such as DefaultPublicAPIUrl
+//
+// Credentials are never mixed between sources: a personal access token or client credentials from the environment
+// replace the credentials of the profile.
+func LoadClientConfig(opts ...LoadClientConfigOpts) (*ClientConfig, error) {
+	loadOpts := LoadClientConfigOpts{}
+	if len(opts) > 0 {
+		loadOpts = opts[len(opts)-1]
+	}
+	lookupEnv := loadOpts.LookupEnv
+	if lookupEnv == nil {
+		lookupEnv = os.LookupEnv
+	}
+	getEnv := func(key string) string {
+		value, _ := lookupEnv(key)
+		return value
+	}
+
+	configFilePath, explicitConfigFile := firstNonEmpty(loadOpts.ConfigFile, getEnv(EnvConfigFile)), true
+	if configFilePath == "" {
+		explicitConfigFile = false
+		home, err := os.UserHomeDir()
+		if err == nil {
+			configFilePath = filepath.Join(home, ".acloud", "config.yaml")
+		}
+	}
+	configFile, err := readConfigFile(configFilePath, explicitConfigFile)
+	if err != nil {
+		return nil, err
+	}
+
+	profileName := firstNonEmpty(loadOpts.Profile, getEnv(EnvProfile))
+	explicitProfile := profileName != ""
+	if !explicitProfile {
+		profileName = firstNonEmpty(configFile.CurrentProfile, DefaultProfile)
+	}
+	profile, found := configFile.Profiles[profileName]
+	if !found {
+		if explicitProfile || configFile.CurrentProfile != "" {
+			return nil, &ConfigError{Source: configFilePath, Key: "profiles." + profileName, Message: "profile does not exist"}
+		}
+		profileName = ""
+	}
+	if err := profile.validate(configFilePath, "profiles."+profileName); err != nil {
+		return nil, err
+	}
+
+	config := &ClientConfig{
+		Profile:      profileName,
+		Organisation: firstNonEmpty(loadOpts.Organisation, getEnv(EnvOrganisation), profile.Organisation),
+		ClientOpts:   loadOpts.ClientOpts,
+	}
+	config.ClientOpts.UserAgent = firstNonEmpty(config.ClientOpts.UserAgent, profile.UserAgent)
+
+	envAPIUrl := getEnv(EnvAPIUrl)
+	if envAPIUrl != "" {
+		if err := validateAPIUrl(envAPIUrl); err != nil {
+			return nil, &ConfigError{Source: sourceEnvironment, Key: EnvAPIUrl, Message: err.Error()}
+		}
+	}
+	config.ClientOpts.APIUrl = firstNonEmpty(config.ClientOpts.APIUrl, envAPIUrl, profile.APIUrl, DefaultPublicAPIUrl)
+
+	config.Authenticator, err = authenticatorFromEnvironment(getEnv)
+	if err != nil {
+		return nil, err
+	}
+	if config.Authenticator == nil {
+		config.Authenticator = profile.authenticator()
+	}
+	if config.Authenticator == nil {
+		if profileName == "" {
+			return nil, &ConfigError{Source: sourceEnvironment, Key: EnvPersonalAccessToken, Message: "no credentials configured"}
+		}
+		return nil, &ConfigError{Source: configFile.path, Key: "profiles." + profileName + ".token", Message: "no credentials configured, set a token or clientCredentials"}
+	}
+	return config, nil
+}
+
+type loadedConfigFile struct {
+	ConfigFile
+	path string
+}
+
+// readConfigFile reads the config file. A missing config file is only an error when it has been configured explicitly.
+func readConfigFile(path string, explicit bool) (*loadedConfigFile, error) {
+	if path == "" {
+		return &loadedConfigFile{}, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && errors.Is(err, fs.ErrNotExist) {
+			return &loadedConfigFile{}, nil
+		}
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	configFile := &loadedConfigFile{path: path}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&configFile.ConfigFile); err != nil && !errors.Is(err, io.EOF) {
+		return nil, &ConfigError{Source: path, Key: "", Message: err.Error()}
+	}
+	return configFile, nil
+}
+
+func (p Profile) validate(source, key string) error {
+	if p.APIUrl != "" {
+		if err := validateAPIUrl(p.APIUrl); err != nil {
+			return &ConfigError{Source: source, Key: key + ".apiUrl", Message: err.Error()}
+		}
+	}
+	if p.Token != "" && p.ClientCredentials != nil {
+		return &ConfigError{Source: source, Key: key + ".clientCredentials", Message: "token and clientCredentials are mutually exclusive"}
+	}
+	if p.ClientCredentials != nil {
+		if p.ClientCredentials.TokenURL == "" {
+			return &ConfigError{Source: source, Key: key + ".clientCredentials.tokenUrl", Message: "is required"}
+		}
+		if err := validateAPIUrl(p.ClientCredentials.TokenURL); err != nil {
+			return &ConfigError{Source: source, Key: key + ".clientCredentials.tokenUrl", Message: err.Error()}
+		}
+		if p.ClientCredentials.ClientID == "" {
+			return &ConfigError{Source: source, Key: key + ".clientCredentials.clientId", Message: "is required"}
+		}
+		if p.ClientCredentials.ClientSecret == "" {
+			return &ConfigError{Source: source, Key: key + ".clientCredentials.clientSecret", Message: "is required"}
+		}
+	}
+	return nil
+}
+
+func (p Profile) authenticator() Authenticator {
+	if p.Token != "" {
+		return NewPersonalAccessTokenAuthenticator(p.Token)
+	}
+	if p.ClientCredentials != nil {
+		return NewClientCredentialsAuthenticator(ClientCredentialsConfig{
+			TokenURL:     p.ClientCredentials.TokenURL,
+			ClientID:     p.ClientCredentials.ClientID,
+			ClientSecret: p.ClientCredentials.ClientSecret,
+			Scopes:       p.ClientCredentials.Scopes,
+		})
+	}
+	return nil
+}
+
+func authenticatorFromEnvironment(getEnv func(key string) string) (Authenticator, error) {
+	token := getEnv(EnvPersonalAccessToken)
+	tokenURL, clientID, clientSecret := getEnv(EnvTokenURL), getEnv(EnvClientID), getEnv(EnvClientSecret)
+	hasClientCredentials := tokenURL != "" || clientID != "" || clientSecret != ""
+
+	if token != "" && hasClientCredentials {
+		return nil, &ConfigError{Source: sourceEnvironment, Key: EnvPersonalAccessToken, Message: fmt.Sprintf("%s and %s are mutually exclusive", EnvPersonalAccessToken, EnvClientID)}
+	}
+	if token != "" {
+		return NewPersonalAccessTokenAuthenticator(token), nil
+	}
+	if !hasClientCredentials {
+		return nil, nil
+	}
+	for _, key := range []string{EnvTokenURL, EnvClientID, EnvClientSecret} {
+		if getEnv(key) == "" {
+			return nil, &ConfigError{Source: sourceEnvironment, Key: key, Message: "is required for client-credentials authentication"}
+		}
+	}
+	if err := validateAPIUrl(tokenURL); err != nil {
+		return nil, &ConfigError{Source: sourceEnvironment, Key: EnvTokenURL, Message: err.Error()}
+	}
+	return NewClientCredentialsAuthenticator(ClientCredentialsConfig{
+		TokenURL:     tokenURL,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+	}), nil
+}
+
+func validateAPIUrl(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("invalid URL %q: scheme must be http or https", rawURL)
+	}
+	if parsed.Host == "" {
+		return fmt.Errorf("invalid URL %q: host is missing", rawURL)
+	}
+	return nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
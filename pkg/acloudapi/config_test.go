@@ -0,0 +1,212 @@
+package acloudapi
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testConfigFile = `currentProfile: production
+profiles:
+  production:
+    apiUrl: https://api.example.com
+    token: production-token
+    organisation: org1
+  staging:
+    apiUrl: https://staging.example.com
+    clientCredentials:
+      tokenUrl: https://staging.example.com/oauth2/token
+      clientId: ci
+      clientSecret: secret
+`
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	return path
+}
+
+func lookupEnvFrom(env map[string]string) func(key string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+}
+
+func TestLoadClientConfig(t *testing.T) {
+	configFile := writeConfigFile(t, testConfigFile)
+
+	tests := []struct {
+		name              string
+		opts              LoadClientConfigOpts
+		env               map[string]string
+		wantProfile       string
+		wantAPIUrl        string
+		wantOrganisation  string
+		wantAuthenticator Authenticator
+		wantErr           bool
+		wantErrKey        string
+	}{
+		{
+			name:              "current profile",
+			opts:              LoadClientConfigOpts{ConfigFile: configFile},
+			wantProfile:       "production",
+			wantAPIUrl:        "https://api.example.com",
+			wantOrganisation:  "org1",
+			wantAuthenticator: &personalAccessTokenAuthenticator{token: "production-token"},
+		},
+		{
+			name:             "profile from environment",
+			opts:             LoadClientConfigOpts{ConfigFile: configFile},
+			env:              map[string]string{EnvProfile: "staging"},
+			wantProfile:      "staging",
+			wantAPIUrl:       "https://staging.example.com",
+			wantOrganisation: "",
+		},
+		{
+			name:              "environment overrides profile",
+			opts:              LoadClientConfigOpts{ConfigFile: configFile},
+			env:               map[string]string{EnvAPIUrl: "https://env.example.com", EnvPersonalAccessToken: "env-token", EnvOrganisation: "org2"},
+			wantProfile:       "production",
+			wantAPIUrl:        "https://env.example.com",
+			wantOrganisation:  "org2",
+			wantAuthenticator: &personalAccessTokenAuthenticator{token: "env-token"},
+		},
+		{
+			name:              "options override environment",
+			opts:              LoadClientConfigOpts{ConfigFile: configFile, Organisation: "org3", ClientOpts: ClientOpts{APIUrl: "https://opts.example.com"}},
+			env:               map[string]string{EnvAPIUrl: "https://env.example.com", EnvOrganisation: "org2"},
+			wantProfile:       "production",
+			wantAPIUrl:        "https://opts.example.com",
+			wantOrganisation:  "org3",
+			wantAuthenticator: &personalAccessTokenAuthenticator{token: "production-token"},
+		},
+		{
+			name:              "environment only",
+			opts:              LoadClientConfigOpts{ConfigFile: writeConfigFile(t, "")},
+			env:               map[string]string{EnvPersonalAccessToken: "env-token"},
+			wantProfile:       "",
+			wantAPIUrl:        DefaultPublicAPIUrl,
+			wantAuthenticator: &personalAccessTokenAuthenticator{token: "env-token"},
+		},
+		{
+			name:    "missing config file",
+			opts:    LoadClientConfigOpts{ConfigFile: filepath.Join(t.TempDir(), "missing.yaml")},
+			env:     map[string]string{EnvPersonalAccessToken: "env-token"},
+			wantErr: true,
+		},
+		{
+			name:       "unknown profile",
+			opts:       LoadClientConfigOpts{ConfigFile: configFile, Profile: "unknown"},
+			wantErrKey: "profiles.unknown",
+		},
+		{
+			name:       "invalid url in environment",
+			opts:       LoadClientConfigOpts{ConfigFile: configFile},
+			env:        map[string]string{EnvAPIUrl: "api.example.com"},
+			wantErrKey: EnvAPIUrl,
+		},
+		{
+			name:       "incomplete client credentials in environment",
+			opts:       LoadClientConfigOpts{ConfigFile: configFile},
+			env:        map[string]string{EnvClientID: "ci", EnvTokenURL: "https://example.com/token"},
+			wantErrKey: EnvClientSecret,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.LookupEnv = lookupEnvFrom(tt.env)
+			config, err := LoadClientConfig(tt.opts)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if tt.wantErrKey != "" {
+				var configError *ConfigError
+				if !errors.As(err, &configError) || configError.Key != tt.wantErrKey {
+					t.Fatalf("expected ConfigError for key %s, got %v", tt.wantErrKey, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if config.Profile != tt.wantProfile {
+				t.Errorf("Profile = %q, want %q", config.Profile, tt.wantProfile)
+			}
+			if config.ClientOpts.APIUrl != tt.wantAPIUrl {
+				t.Errorf("APIUrl = %q, want %q", config.ClientOpts.APIUrl, tt.wantAPIUrl)
+			}
+			if config.Organisation != tt.wantOrganisation {
+				t.Errorf("Organisation = %q, want %q", config.Organisation, tt.wantOrganisation)
+			}
+			if tt.wantAuthenticator != nil {
+				if got, ok := config.Authenticator.(*personalAccessTokenAuthenticator); !ok || *got != *tt.wantAuthenticator.(*personalAccessTokenAuthenticator) {
+					t.Errorf("Authenticator = %#v, want %#v", config.Authenticator, tt.wantAuthenticator)
+				}
+			} else if _, ok := config.Authenticator.(*tokenSourceAuthenticator); !ok {
+				t.Errorf("expected client-credentials authenticator, got %#v", config.Authenticator)
+			}
+		})
+	}
+}
+
+func TestLoadClientConfigInvalidConfigFile(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		wantErrKey string
+		wantErr    string
+	}{
+		{
+			name:       "invalid api url",
+			content:    "profiles:\n  default:\n    apiUrl: ftp://example.com\n    token: t\n",
+			wantErrKey: "profiles.default.apiUrl",
+		},
+		{
+			name:       "token and client credentials",
+			content:    "profiles:\n  default:\n    token: t\n    clientCredentials:\n      tokenUrl: https://example.com\n      clientId: c\n      clientSecret: s\n",
+			wantErrKey: "profiles.default.clientCredentials",
+		},
+		{
+			name:       "missing client secret",
+			content:    "profiles:\n  default:\n    clientCredentials:\n      tokenUrl: https://example.com\n      clientId: c\n",
+			wantErrKey: "profiles.default.clientCredentials.clientSecret",
+		},
+		{
+			name:       "missing credentials",
+			content:    "profiles:\n  default:\n    apiUrl: https://example.com\n",
+			wantErrKey: "profiles.default.token",
+		},
+		{
+			name:    "unknown key",
+			content: "profiles:\n  default:\n    tokn: t\n",
+			wantErr: "line 3: field tokn not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadClientConfig(LoadClientConfigOpts{
+				ConfigFile: writeConfigFile(t, tt.content),
+				LookupEnv:  lookupEnvFrom(nil),
+			})
+			var configError *ConfigError
+			if !errors.As(err, &configError) {
+				t.Fatalf("expected ConfigError, got %v", err)
+			}
+			if configError.Key != tt.wantErrKey {
+				t.Errorf("Key = %q, want %q", configError.Key, tt.wantErrKey)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error %q does not contain %q", err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package acloudapi
 import (
 	"context"
 	"fmt"
+	"iter"
 )
 
 func (c *clientImpl) GetEnvironments(ctx context.Context, org string) ([]Environment, error) {
@@ -13,6 +14,11 @@ func (c *clientImpl) GetEnvironments(ctx context.Context, org string) ([]Environ
 	return MarshalPagedResultContent[Environment](pagedResult)
 }
 
+// ListEnvironmentsIter returns an iterator over all environments of an organisation, requesting one page at a time
+func (c *clientImpl) ListEnvironmentsIter(ctx context.Context, org string) iter.Seq2[Environment, error] {
+	return IteratePaged[Environment](ctx, c.RestyClient, fmt.Sprintf("/api/v1/orgs/%s/environments?show-compute=true", org))
+}
+
 func (c *clientImpl) GetEnvironment(ctx context.Context, org, env string) (*Environment, error) {
 	environment := Environment{}
 	response, err := c.R().
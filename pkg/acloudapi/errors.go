@@ -0,0 +1,98 @@
+package acloudapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrNotFound is matched by an APIError with status 404 Not Found
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is matched by an APIError with status 409 Conflict
+	ErrConflict = errors.New("conflict")
+	// ErrUnauthorized is matched by an APIError with status 401 Unauthorized
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden is matched by an APIError with status 403 Forbidden
+	ErrForbidden = errors.New("forbidden")
+	// ErrRateLimited is matched by an APIError with status 429 Too Many Requests
+	ErrRateLimited = errors.New("rate limited")
+)
+
+// APIError is returned for every non-2xx response of the Avisi Cloud API.
+// Use errors.Is with one of the sentinel errors (ErrNotFound, ErrConflict, ...)
+// or errors.As to get access to the details of the failed request.
+type APIError struct {
+	// StatusCode is the HTTP status code of the response
+	StatusCode int
+	// Message is the decoded Error.Message of the response body, or the raw body if it could not be decoded
+	Message string
+	// FieldErrors contains the field-level validation errors, if any were returned
+	FieldErrors []FieldError
+	// Method is the HTTP method of the failed request
+	Method string
+	// Path is the path (including query parameters) of the failed request
+	Path string
+	// RequestID is the request ID as returned by the server, if any
+	RequestID string
+}
+
+func (e *APIError) Error() string {
+	message := e.Message
+	if message == "" {
+		message = http.StatusText(e.StatusCode)
+	}
+	if len(e.FieldErrors) > 0 {
+		fieldErrors := make([]string, len(e.FieldErrors))
+		for i, fieldError := range e.FieldErrors {
+			fieldErrors[i] = fieldError.String()
+		}
+		message = fmt.Sprintf("%s (%s)", message, strings.Join(fieldErrors, ", "))
+	}
+	return fmt.Sprintf("%d: %s", e.StatusCode, message)
+}
+
+// Is reports whether the APIError matches one of the sentinel errors based on its StatusCode
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// ValidationError is returned when a request is rejected by a local validation before it is sent to the API. It lists
+// all problems of the request at once, in the same format as the field errors of an APIError.
+type ValidationError struct {
+	FieldErrors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	fieldErrors := make([]string, len(e.FieldErrors))
+	for i, fieldError := range e.FieldErrors {
+		fieldErrors[i] = fieldError.String()
+	}
+	return fmt.Sprintf("invalid request: %s", strings.Join(fieldErrors, ", "))
+}
+
+// add records a problem of a field
+func (e *ValidationError) add(field, format string, args ...any) {
+	e.FieldErrors = append(e.FieldErrors, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// errorOrNil returns the ValidationError if any problems were recorded, or nil otherwise
+func (e *ValidationError) errorOrNil() error {
+	if len(e.FieldErrors) == 0 {
+		return nil
+	}
+	return e
+}
@@ -0,0 +1,53 @@
+package acloudapi
+
+import (
+	"context"
+	"iter"
+	"net/http"
+)
+
+// Instrumentation is notified of every API call and request made by a client, to record traces and metrics.
+// See package otelacloudapi for an OpenTelemetry implementation.
+type Instrumentation interface {
+	// StartOperation is called when an API call starts. The operation is named after the API interface and method, e.g.
+	// "ClusterAPI.GetCluster". The returned context is used for all requests of the call, and end is called with
+	// the resulting error (or nil) when the call has finished.
+	StartOperation(ctx context.Context, operation string) (_ context.Context, end func(err error))
+
+	// WrapTransport wraps the transport used for the HTTP requests, including retries, of all operations.
+	// It is only called if ClientOpts.CustomResty is not provided
+	WrapTransport(next http.RoundTripper) http.RoundTripper
+}
+
+func instrument[T any](ctx context.Context, instrumentation Instrumentation, operation string, call func(ctx context.Context) (T, error)) (T, error) {
+	ctx, end := instrumentation.StartOperation(ctx, operation)
+	result, err := call(ctx)
+	end(err)
+	return result, err
+}
+
+func instrumentErr(ctx context.Context, instrumentation Instrumentation, operation string, call func(ctx context.Context) error) error {
+	ctx, end := instrumentation.StartOperation(ctx, operation)
+	err := call(ctx)
+	end(err)
+	return err
+}
+
+// instrumentIter starts the operation when the iteration starts, and ends it when the iteration has finished or is stopped
+func instrumentIter[T any](ctx context.Context, instrumentation Instrumentation, operation string, list func(ctx context.Context) iter.Seq2[T, error]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		ctx, end := instrumentation.StartOperation(ctx, operation)
+		var err error
+		defer func() {
+			end(err)
+		}()
+		for item, itemErr := range list(ctx) {
+			if itemErr != nil {
+				err = itemErr
+			}
+			if !yield(item, itemErr) {
+				return
+			}
+		}
+	}
+}
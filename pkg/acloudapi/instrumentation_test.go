@@ -0,0 +1,87 @@
+package acloudapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+)
+
+type recordingInstrumentation struct {
+	mu         sync.Mutex
+	operations []string
+	errors     []error
+	requests   int
+}
+
+func (i *recordingInstrumentation) StartOperation(ctx context.Context, operation string) (context.Context, func(err error)) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.operations = append(i.operations, operation)
+	return ctx, func(err error) {
+		i.mu.Lock()
+		defer i.mu.Unlock()
+		i.errors = append(i.errors, err)
+	}
+}
+
+func (i *recordingInstrumentation) WrapTransport(next http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(request *http.Request) (*http.Response, error) {
+		i.mu.Lock()
+		i.requests++
+		i.mu.Unlock()
+		return next.RoundTrip(request)
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+func TestInstrumentedClient(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/orgs/org1/environments", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"content":[{"slug":"env1"},{"slug":"env2"}],"last":true}`)
+	})
+	mux.HandleFunc("/api/v1/orgs/org1/environments/env1", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	instrumentation := &recordingInstrumentation{}
+	client := NewClient(nil, ClientOpts{APIUrl: server.URL, RetryPolicy: NoRetryPolicy(), Instrumentation: instrumentation})
+
+	ctx := context.Background()
+	if _, err := client.GetEnvironment(ctx, "org1", "env1"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	for environment, err := range client.ListEnvironmentsIter(ctx, "org1") {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if environment.Slug == "env1" {
+			break
+		}
+	}
+
+	if want := []string{"EnvironmentsAPI.GetEnvironment", "EnvironmentsAPI.ListEnvironmentsIter"}; !slices.Equal(instrumentation.operations, want) {
+		t.Fatalf("operations = %v, want %v", instrumentation.operations, want)
+	}
+	if len(instrumentation.errors) != 2 || !errors.Is(instrumentation.errors[0], ErrNotFound) || instrumentation.errors[1] != nil {
+		t.Fatalf("unexpected operation errors: %v", instrumentation.errors)
+	}
+	if instrumentation.requests != 2 {
+		t.Fatalf("requests = %d, want 2", instrumentation.requests)
+	}
+	if _, ok := NewAdminClient(nil, ClientOpts{Instrumentation: instrumentation}).(*instrumentedAdminClient); !ok {
+		t.Fatal("expected an instrumented admin client")
+	}
+}
@@ -0,0 +1,484 @@
+package acloudapi
+
+import (
+	"context"
+	"iter"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// instrumentedClient wraps a Client, reporting every call to the Instrumentation as an operation named after the API interface and method
+type instrumentedClient struct {
+	client          Client
+	instrumentation Instrumentation
+}
+
+var _ Client = &instrumentedClient{}
+
+func (c *instrumentedClient) Resty() *resty.Client {
+	return c.client.Resty()
+}
+
+// fanOutConcurrency returns the fan-out concurrency of the wrapped client, used by GetNodePoolsByClusters
+func (c *instrumentedClient) fanOutConcurrency() int {
+	if fanOutClient, ok := c.client.(interface{ fanOutConcurrency() int }); ok {
+		return fanOutClient.fanOutConcurrency()
+	}
+	return DefaultFanOutConcurrency
+}
+
+func (c *instrumentedClient) GetClusters(ctx context.Context, opts ...GetClusterOpts) ([]Cluster, error) {
+	return instrument(ctx, c.instrumentation, "ClusterAPI.GetClusters", func(ctx context.Context) ([]Cluster, error) {
+		return c.client.GetClusters(ctx, opts...)
+	})
+}
+
+func (c *instrumentedClient) GetClustersByOrg(ctx context.Context, organisationSlug string, opts ...GetClusterOpts) ([]Cluster, error) {
+	return instrument(ctx, c.instrumentation, "ClusterAPI.GetClustersByOrg", func(ctx context.Context) ([]Cluster, error) {
+		return c.client.GetClustersByOrg(ctx, organisationSlug, opts...)
+	})
+}
+
+func (c *instrumentedClient) GetClustersByOrgAndEnv(ctx context.Context, organisationSlug, environmentSlug string, opts ...GetClusterOpts) ([]Cluster, error) {
+	return instrument(ctx, c.instrumentation, "ClusterAPI.GetClustersByOrgAndEnv", func(ctx context.Context) ([]Cluster, error) {
+		return c.client.GetClustersByOrgAndEnv(ctx, organisationSlug, environmentSlug, opts...)
+	})
+}
+
+func (c *instrumentedClient) ListClustersIter(ctx context.Context, organisationSlug string, opts ...GetClusterOpts) iter.Seq2[Cluster, error] {
+	return instrumentIter(ctx, c.instrumentation, "ClusterAPI.ListClustersIter", func(ctx context.Context) iter.Seq2[Cluster, error] {
+		return c.client.ListClustersIter(ctx, organisationSlug, opts...)
+	})
+}
+
+func (c *instrumentedClient) GetCluster(ctx context.Context, organisationSlug, environmentSlug, cluster string, opts ...GetClusterOpts) (*Cluster, error) {
+	return instrument(ctx, c.instrumentation, "ClusterAPI.GetCluster", func(ctx context.Context) (*Cluster, error) {
+		return c.client.GetCluster(ctx, organisationSlug, environmentSlug, cluster, opts...)
+	})
+}
+
+func (c *instrumentedClient) GetClusterOIDCConfig(ctx context.Context, organisationSlug, environmentSlug, clusterSlug string) (*ClusterMetadataResponse, error) {
+	return instrument(ctx, c.instrumentation, "ClusterAPI.GetClusterOIDCConfig", func(ctx context.Context) (*ClusterMetadataResponse, error) {
+		return c.client.GetClusterOIDCConfig(ctx, organisationSlug, environmentSlug, clusterSlug)
+	})
+}
+
+func (c *instrumentedClient) CreateCluster(ctx context.Context, organisationSlug, environmentSlug string, create CreateCluster) (*Cluster, error) {
+	return instrument(ctx, c.instrumentation, "ClusterAPI.CreateCluster", func(ctx context.Context) (*Cluster, error) {
+		return c.client.CreateCluster(ctx, organisationSlug, environmentSlug, create)
+	})
+}
+
+func (c *instrumentedClient) UpdateCluster(ctx context.Context, organisationSlug, environmentSlug, clusterSlug string, update UpdateCluster) (*Cluster, error) {
+	return instrument(ctx, c.instrumentation, "ClusterAPI.UpdateCluster", func(ctx context.Context) (*Cluster, error) {
+		return c.client.UpdateCluster(ctx, organisationSlug, environmentSlug, clusterSlug, update)
+	})
+}
+
+func (c *instrumentedClient) DeleteCluster(ctx context.Context, organisationSlug, environmentSlug, clusterSlug string, update UpdateCluster) error {
+	return instrumentErr(ctx, c.instrumentation, "ClusterAPI.DeleteCluster", func(ctx context.Context) error {
+		return c.client.DeleteCluster(ctx, organisationSlug, environmentSlug, clusterSlug, update)
+	})
+}
+
+func (c *instrumentedClient) GetClusterVersions(ctx context.Context) ([]ClusterVersion, error) {
+	return instrument(ctx, c.instrumentation, "ClusterVersionAPI.GetClusterVersions", func(ctx context.Context) ([]ClusterVersion, error) {
+		return c.client.GetClusterVersions(ctx)
+	})
+}
+
+func (c *instrumentedClient) GetCloudProviders(ctx context.Context, organisationSlug string) ([]CloudProvider, error) {
+	return instrument(ctx, c.instrumentation, "CloudProvidersAPI.GetCloudProviders", func(ctx context.Context) ([]CloudProvider, error) {
+		return c.client.GetCloudProviders(ctx, organisationSlug)
+	})
+}
+
+func (c *instrumentedClient) GetRegions(ctx context.Context, organisationSlug, cloudProviderSlug string) ([]Region, error) {
+	return instrument(ctx, c.instrumentation, "CloudProvidersAPI.GetRegions", func(ctx context.Context) ([]Region, error) {
+		return c.client.GetRegions(ctx, organisationSlug, cloudProviderSlug)
+	})
+}
+
+func (c *instrumentedClient) GetAvailabilityZones(ctx context.Context, organisationSlug, cloudProviderSlug, regionSlug string) ([]AvailabilityZone, error) {
+	return instrument(ctx, c.instrumentation, "CloudProvidersAPI.GetAvailabilityZones", func(ctx context.Context) ([]AvailabilityZone, error) {
+		return c.client.GetAvailabilityZones(ctx, organisationSlug, cloudProviderSlug, regionSlug)
+	})
+}
+
+func (c *instrumentedClient) GetNodeTypes(ctx context.Context, cloudProviderSlug string) ([]NodeType, error) {
+	return instrument(ctx, c.instrumentation, "CloudProvidersAPI.GetNodeTypes", func(ctx context.Context) ([]NodeType, error) {
+		return c.client.GetNodeTypes(ctx, cloudProviderSlug)
+	})
+}
+
+func (c *instrumentedClient) GetEnvironment(ctx context.Context, org, env string) (*Environment, error) {
+	return instrument(ctx, c.instrumentation, "EnvironmentsAPI.GetEnvironment", func(ctx context.Context) (*Environment, error) {
+		return c.client.GetEnvironment(ctx, org, env)
+	})
+}
+
+func (c *instrumentedClient) CreateEnvironment(ctx context.Context, createEnvironment CreateEnvironment, org string) (*Environment, error) {
+	return instrument(ctx, c.instrumentation, "EnvironmentsAPI.CreateEnvironment", func(ctx context.Context) (*Environment, error) {
+		return c.client.CreateEnvironment(ctx, createEnvironment, org)
+	})
+}
+
+func (c *instrumentedClient) UpdateEnvironment(ctx context.Context, updateEnvironment UpdateEnvironment, org, env string) (*Environment, error) {
+	return instrument(ctx, c.instrumentation, "EnvironmentsAPI.UpdateEnvironment", func(ctx context.Context) (*Environment, error) {
+		return c.client.UpdateEnvironment(ctx, updateEnvironment, org, env)
+	})
+}
+
+func (c *instrumentedClient) DeleteEnvironment(ctx context.Context, org, env string) error {
+	return instrumentErr(ctx, c.instrumentation, "EnvironmentsAPI.DeleteEnvironment", func(ctx context.Context) error {
+		return c.client.DeleteEnvironment(ctx, org, env)
+	})
+}
+
+func (c *instrumentedClient) GetEnvironments(ctx context.Context, organisationSlug string) ([]Environment, error) {
+	return instrument(ctx, c.instrumentation, "EnvironmentsAPI.GetEnvironments", func(ctx context.Context) ([]Environment, error) {
+		return c.client.GetEnvironments(ctx, organisationSlug)
+	})
+}
+
+func (c *instrumentedClient) ListEnvironmentsIter(ctx context.Context, organisationSlug string) iter.Seq2[Environment, error] {
+	return instrumentIter(ctx, c.instrumentation, "EnvironmentsAPI.ListEnvironmentsIter", func(ctx context.Context) iter.Seq2[Environment, error] {
+		return c.client.ListEnvironmentsIter(ctx, organisationSlug)
+	})
+}
+
+func (c *instrumentedClient) GetNodePools(ctx context.Context) ([]NodePool, error) {
+	return instrument(ctx, c.instrumentation, "NodePoolsAPI.GetNodePools", func(ctx context.Context) ([]NodePool, error) {
+		return c.client.GetNodePools(ctx)
+	})
+}
+
+func (c *instrumentedClient) GetNodePoolsByOrg(ctx context.Context, organisationSlug string) ([]NodePool, error) {
+	return instrument(ctx, c.instrumentation, "NodePoolsAPI.GetNodePoolsByOrg", func(ctx context.Context) ([]NodePool, error) {
+		return c.client.GetNodePoolsByOrg(ctx, organisationSlug)
+	})
+}
+
+func (c *instrumentedClient) GetNodePoolsByCluster(ctx context.Context, cluster Cluster) ([]NodePool, error) {
+	return instrument(ctx, c.instrumentation, "NodePoolsAPI.GetNodePoolsByCluster", func(ctx context.Context) ([]NodePool, error) {
+		return c.client.GetNodePoolsByCluster(ctx, cluster)
+	})
+}
+
+func (c *instrumentedClient) GetNodePoolsByClusters(ctx context.Context, clusters []Cluster) ([]NodePool, error) {
+	return instrument(ctx, c.instrumentation, "NodePoolsAPI.GetNodePoolsByClusters", func(ctx context.Context) ([]NodePool, error) {
+		return c.client.GetNodePoolsByClusters(ctx, clusters)
+	})
+}
+
+func (c *instrumentedClient) GetNodePoolJoinConfig(ctx context.Context, cluster Cluster, nodePool NodePool) (*NodePoolJoinConfig, error) {
+	return instrument(ctx, c.instrumentation, "NodePoolsAPI.GetNodePoolJoinConfig", func(ctx context.Context) (*NodePoolJoinConfig, error) {
+		return c.client.GetNodePoolJoinConfig(ctx, cluster, nodePool)
+	})
+}
+
+func (c *instrumentedClient) CreateNodePool(ctx context.Context, cluster Cluster, create CreateNodePool) (*NodePool, error) {
+	return instrument(ctx, c.instrumentation, "NodePoolsAPI.CreateNodePool", func(ctx context.Context) (*NodePool, error) {
+		return c.client.CreateNodePool(ctx, cluster, create)
+	})
+}
+
+func (c *instrumentedClient) UpdateNodePool(ctx context.Context, cluster Cluster, nodePoolID int, update CreateNodePool) (*NodePool, error) {
+	return instrument(ctx, c.instrumentation, "NodePoolsAPI.UpdateNodePool", func(ctx context.Context) (*NodePool, error) {
+		return c.client.UpdateNodePool(ctx, cluster, nodePoolID, update)
+	})
+}
+
+func (c *instrumentedClient) DeleteNodePool(ctx context.Context, cluster Cluster, nodePoolID int) error {
+	return instrumentErr(ctx, c.instrumentation, "NodePoolsAPI.DeleteNodePool", func(ctx context.Context) error {
+		return c.client.DeleteNodePool(ctx, cluster, nodePoolID)
+	})
+}
+
+func (c *instrumentedClient) GetCloudAccounts(ctx context.Context, org string) ([]CloudAccount, error) {
+	return instrument(ctx, c.instrumentation, "CloudAccountsAPI.GetCloudAccounts", func(ctx context.Context) ([]CloudAccount, error) {
+		return c.client.GetCloudAccounts(ctx, org)
+	})
+}
+
+func (c *instrumentedClient) CreateCloudAccount(ctx context.Context, org string, createCloudAccount CreateCloudAccount) (*CloudAccount, error) {
+	return instrument(ctx, c.instrumentation, "CloudAccountsAPI.CreateCloudAccount", func(ctx context.Context) (*CloudAccount, error) {
+		return c.client.CreateCloudAccount(ctx, org, createCloudAccount)
+	})
+}
+
+func (c *instrumentedClient) UpdateCloudAccount(ctx context.Context, org, cloudAccount string, updateCloudAccount UpdateCloudAccount) (*CloudAccount, error) {
+	return instrument(ctx, c.instrumentation, "CloudAccountsAPI.UpdateCloudAccount", func(ctx context.Context) (*CloudAccount, error) {
+		return c.client.UpdateCloudAccount(ctx, org, cloudAccount, updateCloudAccount)
+	})
+}
+
+func (c *instrumentedClient) DeleteCloudAccount(ctx context.Context, org, cloudAccount string) error {
+	return instrumentErr(ctx, c.instrumentation, "CloudAccountsAPI.DeleteCloudAccount", func(ctx context.Context) error {
+		return c.client.DeleteCloudAccount(ctx, org, cloudAccount)
+	})
+}
+
+func (c *instrumentedClient) FindCloudAccountByName(ctx context.Context, org, name, cloudProvider string) (*CloudAccount, error) {
+	return instrument(ctx, c.instrumentation, "CloudAccountsAPI.FindCloudAccountByName", func(ctx context.Context) (*CloudAccount, error) {
+		return c.client.FindCloudAccountByName(ctx, org, name, cloudProvider)
+	})
+}
+
+func (c *instrumentedClient) GetCloudProfiles(ctx context.Context, org string) ([]CloudProfile, error) {
+	return instrument(ctx, c.instrumentation, "CloudAccountsAPI.GetCloudProfiles", func(ctx context.Context) ([]CloudProfile, error) {
+		return c.client.GetCloudProfiles(ctx, org)
+	})
+}
+
+func (c *instrumentedClient) GetCloudCredentials(ctx context.Context, org, cloudAccountIdentity string) ([]CloudCredential, error) {
+	return instrument(ctx, c.instrumentation, "CloudAccountsAPI.GetCloudCredentials", func(ctx context.Context) ([]CloudCredential, error) {
+		return c.client.GetCloudCredentials(ctx, org, cloudAccountIdentity)
+	})
+}
+
+func (c *instrumentedClient) CreateCloudCredential(ctx context.Context, org string, cloudAccount CloudAccount, create CreateCloudCredential) (*CloudCredential, error) {
+	return instrument(ctx, c.instrumentation, "CloudAccountsAPI.CreateCloudCredential", func(ctx context.Context) (*CloudCredential, error) {
+		return c.client.CreateCloudCredential(ctx, org, cloudAccount, create)
+	})
+}
+
+func (c *instrumentedClient) DeleteCloudCredential(ctx context.Context, org, cloudAccountIdentity, cloudCredentialIdentity string) error {
+	return instrumentErr(ctx, c.instrumentation, "CloudAccountsAPI.DeleteCloudCredential", func(ctx context.Context) error {
+		return c.client.DeleteCloudCredential(ctx, org, cloudAccountIdentity, cloudCredentialIdentity)
+	})
+}
+
+func (c *instrumentedClient) GetMemberships(ctx context.Context) ([]Membership, error) {
+	return instrument(ctx, c.instrumentation, "MembershipsAPI.GetMemberships", func(ctx context.Context) ([]Membership, error) {
+		return c.client.GetMemberships(ctx)
+	})
+}
+
+func (c *instrumentedClient) GetUpdateChannels(ctx context.Context, org string) ([]UpdateChannelResponse, error) {
+	return instrument(ctx, c.instrumentation, "UpdateChannelAPI.GetUpdateChannels", func(ctx context.Context) ([]UpdateChannelResponse, error) {
+		return c.client.GetUpdateChannels(ctx, org)
+	})
+}
+
+func (c *instrumentedClient) GetObservabilityTenants(ctx context.Context, org string) ([]ObservabilityTenant, error) {
+	return instrument(ctx, c.instrumentation, "ObservabilityAPI.GetObservabilityTenants", func(ctx context.Context) ([]ObservabilityTenant, error) {
+		return c.client.GetObservabilityTenants(ctx, org)
+	})
+}
+
+func (c *instrumentedClient) GetObservabilityTenantBySlug(ctx context.Context, org, slug string) (*ObservabilityTenant, error) {
+	return instrument(ctx, c.instrumentation, "ObservabilityAPI.GetObservabilityTenantBySlug", func(ctx context.Context) (*ObservabilityTenant, error) {
+		return c.client.GetObservabilityTenantBySlug(ctx, org, slug)
+	})
+}
+
+func (c *instrumentedClient) GetObservabilityOrganisationAlerts(ctx context.Context, org string) ([]ObservabilityAlert, error) {
+	return instrument(ctx, c.instrumentation, "ObservabilityAPI.GetObservabilityOrganisationAlerts", func(ctx context.Context) ([]ObservabilityAlert, error) {
+		return c.client.GetObservabilityOrganisationAlerts(ctx, org)
+	})
+}
+
+func (c *instrumentedClient) GetObservabilityTenantAlerts(ctx context.Context, org, slug string) ([]ObservabilityAlert, error) {
+	return instrument(ctx, c.instrumentation, "ObservabilityAPI.GetObservabilityTenantAlerts", func(ctx context.Context) ([]ObservabilityAlert, error) {
+		return c.client.GetObservabilityTenantAlerts(ctx, org, slug)
+	})
+}
+
+func (c *instrumentedClient) GetObservabilityTenantAlertmanagerConfiguration(ctx context.Context, org, slug string) (*ObservabilityAlertmanager, error) {
+	return instrument(ctx, c.instrumentation, "ObservabilityAPI.GetObservabilityTenantAlertmanagerConfiguration", func(ctx context.Context) (*ObservabilityAlertmanager, error) {
+		return c.client.GetObservabilityTenantAlertmanagerConfiguration(ctx, org, slug)
+	})
+}
+
+func (c *instrumentedClient) AddObservabilityTenantPrometheusRules(ctx context.Context, org, slug string, rules []PrometheusRules, force bool) error {
+	return instrumentErr(ctx, c.instrumentation, "ObservabilityAPI.AddObservabilityTenantPrometheusRules", func(ctx context.Context) error {
+		return c.client.AddObservabilityTenantPrometheusRules(ctx, org, slug, rules, force)
+	})
+}
+
+func (c *instrumentedClient) OverwriteObservabilityTenantPrometheusRules(ctx context.Context, org, slug string, rules []PrometheusRules) error {
+	return instrumentErr(ctx, c.instrumentation, "ObservabilityAPI.OverwriteObservabilityTenantPrometheusRules", func(ctx context.Context) error {
+		return c.client.OverwriteObservabilityTenantPrometheusRules(ctx, org, slug, rules)
+	})
+}
+
+func (c *instrumentedClient) DeleteObservabilityTenantPrometheusRules(ctx context.Context, org, slug string, names []string) error {
+	return instrumentErr(ctx, c.instrumentation, "ObservabilityAPI.DeleteObservabilityTenantPrometheusRules", func(ctx context.Context) error {
+		return c.client.DeleteObservabilityTenantPrometheusRules(ctx, org, slug, names)
+	})
+}
+
+func (c *instrumentedClient) GetSilences(ctx context.Context, org, observabilityTenantSlug string) ([]Silence, error) {
+	return instrument(ctx, c.instrumentation, "ObservabilityAPI.GetSilences", func(ctx context.Context) ([]Silence, error) {
+		return c.client.GetSilences(ctx, org, observabilityTenantSlug)
+	})
+}
+
+func (c *instrumentedClient) CreateSilence(ctx context.Context, createSilence CreateSilence, org, observabilityTenantSlug string) (*Silence, error) {
+	return instrument(ctx, c.instrumentation, "ObservabilityAPI.CreateSilence", func(ctx context.Context) (*Silence, error) {
+		return c.client.CreateSilence(ctx, createSilence, org, observabilityTenantSlug)
+	})
+}
+
+func (c *instrumentedClient) ExpireSilence(ctx context.Context, org, observabilityTenantSlug, silenceID string) error {
+	return instrumentErr(ctx, c.instrumentation, "ObservabilityAPI.ExpireSilence", func(ctx context.Context) error {
+		return c.client.ExpireSilence(ctx, org, observabilityTenantSlug, silenceID)
+	})
+}
+
+func (c *instrumentedClient) GetOrganisation(ctx context.Context, organisationSlug string) (*Organisation, error) {
+	return instrument(ctx, c.instrumentation, "OrganisationAPI.GetOrganisation", func(ctx context.Context) (*Organisation, error) {
+		return c.client.GetOrganisation(ctx, organisationSlug)
+	})
+}
+
+func (c *instrumentedClient) GetMaintenanceSchedules(ctx context.Context, org string) ([]MaintenanceSchedule, error) {
+	return instrument(ctx, c.instrumentation, "MaintenanceAPI.GetMaintenanceSchedules", func(ctx context.Context) ([]MaintenanceSchedule, error) {
+		return c.client.GetMaintenanceSchedules(ctx, org)
+	})
+}
+
+func (c *instrumentedClient) GetMaintenanceSchedule(ctx context.Context, org, maintenanceScheduleID string) (*MaintenanceSchedule, error) {
+	return instrument(ctx, c.instrumentation, "MaintenanceAPI.GetMaintenanceSchedule", func(ctx context.Context) (*MaintenanceSchedule, error) {
+		return c.client.GetMaintenanceSchedule(ctx, org, maintenanceScheduleID)
+	})
+}
+
+func (c *instrumentedClient) CreateMaintenanceSchedule(ctx context.Context, org string, createMaintenanceSchedule CreateMaintenanceSchedule) (*MaintenanceSchedule, error) {
+	return instrument(ctx, c.instrumentation, "MaintenanceAPI.CreateMaintenanceSchedule", func(ctx context.Context) (*MaintenanceSchedule, error) {
+		return c.client.CreateMaintenanceSchedule(ctx, org, createMaintenanceSchedule)
+	})
+}
+
+func (c *instrumentedClient) DeleteMaintenanceSchedule(ctx context.Context, org, maintenanceScheduleID string) error {
+	return instrumentErr(ctx, c.instrumentation, "MaintenanceAPI.DeleteMaintenanceSchedule", func(ctx context.Context) error {
+		return c.client.DeleteMaintenanceSchedule(ctx, org, maintenanceScheduleID)
+	})
+}
+
+func (c *instrumentedClient) UpdateMaintenanceSchedule(ctx context.Context, org, maintenanceScheduleID string, updateMaintenanceSchedule UpdateMaintenanceSchedule) (*MaintenanceSchedule, error) {
+	return instrument(ctx, c.instrumentation, "MaintenanceAPI.UpdateMaintenanceSchedule", func(ctx context.Context) (*MaintenanceSchedule, error) {
+		return c.client.UpdateMaintenanceSchedule(ctx, org, maintenanceScheduleID, updateMaintenanceSchedule)
+	})
+}
+
+// instrumentedAdminClient wraps an AdminClient, reporting every call to the Instrumentation as an operation named after the API interface and method
+type instrumentedAdminClient struct {
+	client          AdminClient
+	instrumentation Instrumentation
+}
+
+var _ AdminClient = &instrumentedAdminClient{}
+
+func (c *instrumentedAdminClient) Resty() *resty.Client {
+	return c.client.Resty()
+}
+
+func (c *instrumentedAdminClient) GetCluster(ctx context.Context, clusterIdentity string, opts ...GetClusterOpts) (*Cluster, error) {
+	return instrument(ctx, c.instrumentation, "AdminClusterAPI.GetCluster", func(ctx context.Context) (*Cluster, error) {
+		return c.client.GetCluster(ctx, clusterIdentity, opts...)
+	})
+}
+
+func (c *instrumentedAdminClient) ListClusters(ctx context.Context, opts ...GetClusterOpts) ([]Cluster, error) {
+	return instrument(ctx, c.instrumentation, "AdminClusterAPI.ListClusters", func(ctx context.Context) ([]Cluster, error) {
+		return c.client.ListClusters(ctx, opts...)
+	})
+}
+
+func (c *instrumentedAdminClient) ListClustersIter(ctx context.Context, opts ...GetClusterOpts) iter.Seq2[Cluster, error] {
+	return instrumentIter(ctx, c.instrumentation, "AdminClusterAPI.ListClustersIter", func(ctx context.Context) iter.Seq2[Cluster, error] {
+		return c.client.ListClustersIter(ctx, opts...)
+	})
+}
+
+func (c *instrumentedAdminClient) UpdateCluster(ctx context.Context, request AdminUpdateClusterRequest) (*Cluster, error) {
+	return instrument(ctx, c.instrumentation, "AdminClusterAPI.UpdateCluster", func(ctx context.Context) (*Cluster, error) {
+		return c.client.UpdateCluster(ctx, request)
+	})
+}
+
+func (c *instrumentedAdminClient) GetOrganisation(ctx context.Context, organisationIdentity string) (*AdminOrganisation, error) {
+	return instrument(ctx, c.instrumentation, "AdminOrganisationAPI.GetOrganisation", func(ctx context.Context) (*AdminOrganisation, error) {
+		return c.client.GetOrganisation(ctx, organisationIdentity)
+	})
+}
+
+func (c *instrumentedAdminClient) ListScheduledClusterUpgrades(ctx context.Context, opts ...ListScheduledClusterUpgradesOpts) ([]ScheduledClusterUpgrade, error) {
+	return instrument(ctx, c.instrumentation, "AdminScheduledClusterUpgradesAPI.ListScheduledClusterUpgrades", func(ctx context.Context) ([]ScheduledClusterUpgrade, error) {
+		return c.client.ListScheduledClusterUpgrades(ctx, opts...)
+	})
+}
+
+func (c *instrumentedAdminClient) GetScheduledClusterUpgrade(ctx context.Context, identity string) (*ScheduledClusterUpgrade, error) {
+	return instrument(ctx, c.instrumentation, "AdminScheduledClusterUpgradesAPI.GetScheduledClusterUpgrade", func(ctx context.Context) (*ScheduledClusterUpgrade, error) {
+		return c.client.GetScheduledClusterUpgrade(ctx, identity)
+	})
+}
+
+func (c *instrumentedAdminClient) CancelScheduledClusterUpgrade(ctx context.Context, identity string) (*ScheduledClusterUpgrade, error) {
+	return instrument(ctx, c.instrumentation, "AdminScheduledClusterUpgradesAPI.CancelScheduledClusterUpgrade", func(ctx context.Context) (*ScheduledClusterUpgrade, error) {
+		return c.client.CancelScheduledClusterUpgrade(ctx, identity)
+	})
+}
+
+func (c *instrumentedAdminClient) CreateScheduledClusterUpgrade(ctx context.Context, request CreateScheduledClusterUpgradeRequest) (*ScheduledClusterUpgrade, error) {
+	return instrument(ctx, c.instrumentation, "AdminScheduledClusterUpgradesAPI.CreateScheduledClusterUpgrade", func(ctx context.Context) (*ScheduledClusterUpgrade, error) {
+		return c.client.CreateScheduledClusterUpgrade(ctx, request)
+	})
+}
+
+func (c *instrumentedAdminClient) UpdateScheduledClusterUpgrade(ctx context.Context, request UpdateScheduledClusterUpgradeRequest) (*ScheduledClusterUpgrade, error) {
+	return instrument(ctx, c.instrumentation, "AdminScheduledClusterUpgradesAPI.UpdateScheduledClusterUpgrade", func(ctx context.Context) (*ScheduledClusterUpgrade, error) {
+		return c.client.UpdateScheduledClusterUpgrade(ctx, request)
+	})
+}
+
+func (c *instrumentedAdminClient) ListUpdateChannels(ctx context.Context) ([]UpdateChannelResponse, error) {
+	return instrument(ctx, c.instrumentation, "AdminUpdateChannelsAPI.ListUpdateChannels", func(ctx context.Context) ([]UpdateChannelResponse, error) {
+		return c.client.ListUpdateChannels(ctx)
+	})
+}
+
+func (c *instrumentedAdminClient) ListClusterVersions(ctx context.Context) ([]AdminClusterVersion, error) {
+	return instrument(ctx, c.instrumentation, "AdminClusterVersionsAPI.ListClusterVersions", func(ctx context.Context) ([]AdminClusterVersion, error) {
+		return c.client.ListClusterVersions(ctx)
+	})
+}
+
+func (c *instrumentedAdminClient) ListAvailableClusterVersions(ctx context.Context) ([]AdminClusterVersion, error) {
+	return instrument(ctx, c.instrumentation, "AdminClusterVersionsAPI.ListAvailableClusterVersions", func(ctx context.Context) ([]AdminClusterVersion, error) {
+		return c.client.ListAvailableClusterVersions(ctx)
+	})
+}
+
+func (c *instrumentedAdminClient) ListHistoryClusterVersions(ctx context.Context) ([]AdminClusterVersion, error) {
+	return instrument(ctx, c.instrumentation, "AdminClusterVersionsAPI.ListHistoryClusterVersions", func(ctx context.Context) ([]AdminClusterVersion, error) {
+		return c.client.ListHistoryClusterVersions(ctx)
+	})
+}
+
+func (c *instrumentedAdminClient) GetClusterVersion(ctx context.Context, version string) (*AdminClusterVersion, error) {
+	return instrument(ctx, c.instrumentation, "AdminClusterVersionsAPI.GetClusterVersion", func(ctx context.Context) (*AdminClusterVersion, error) {
+		return c.client.GetClusterVersion(ctx, version)
+	})
+}
+
+func (c *instrumentedAdminClient) UpdateClusterVersion(ctx context.Context, version string, request AdminUpdateClusterVersionRequest) (*AdminClusterVersion, error) {
+	return instrument(ctx, c.instrumentation, "AdminClusterVersionsAPI.UpdateClusterVersion", func(ctx context.Context) (*AdminClusterVersion, error) {
+		return c.client.UpdateClusterVersion(ctx, version, request)
+	})
+}
+
+func (c *instrumentedAdminClient) CreateClusterVersion(ctx context.Context, request AdminCreateClusterVersionRequest) (*AdminClusterVersion, error) {
+	return instrument(ctx, c.instrumentation, "AdminClusterVersionsAPI.CreateClusterVersion", func(ctx context.Context) (*AdminClusterVersion, error) {
+		return c.client.CreateClusterVersion(ctx, request)
+	})
+}
+
+func (c *instrumentedAdminClient) DeleteClusterVersion(ctx context.Context, version string) error {
+	return instrumentErr(ctx, c.instrumentation, "AdminClusterVersionsAPI.DeleteClusterVersion", func(ctx context.Context) error {
+		return c.client.DeleteClusterVersion(ctx, version)
+	})
+}
@@ -0,0 +1,275 @@
+package acloudapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+)
+
+// NormaliseCIDR returns the canonical form of a CIDR: a single IP address becomes a /32 (or /128 for IPv6) and host
+// bits are cleared, e.g. "192.0.2.1" becomes "192.0.2.1/32" and "192.0.2.1/24" becomes "192.0.2.0/24"
+func NormaliseCIDR(cidr string) (string, error) {
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		addr, addrErr := netip.ParseAddr(cidr)
+		if addrErr != nil {
+			return "", fmt.Errorf("invalid CIDR %q", cidr)
+		}
+		prefix = netip.PrefixFrom(addr, addr.BitLen())
+	}
+	return prefix.Masked().String(), nil
+}
+
+// MarshalJSON sends IPWhitelistEntries as the IP whitelist when it is set
+func (u UpdateCluster) MarshalJSON() ([]byte, error) {
+	type updateCluster UpdateCluster
+	if u.IPWhitelistEntries == nil {
+		return json.Marshal(updateCluster(u))
+	}
+	return json.Marshal(struct {
+		updateCluster
+		IPWhitelist []IPWhitelistEntry `json:"ipWhitelist"`
+	}{updateCluster(u), u.IPWhitelistEntries})
+}
+
+// UnmarshalJSON accepts an IP whitelist of CIDRs, which is decoded into IPWhitelist, or of entries with a description,
+// which is decoded into IPWhitelistEntries
+func (u *UpdateCluster) UnmarshalJSON(data []byte) error {
+	type updateCluster UpdateCluster
+	request := struct {
+		*updateCluster
+		IPWhitelist json.RawMessage `json:"ipWhitelist"`
+	}{updateCluster: (*updateCluster)(u)}
+	if err := json.Unmarshal(data, &request); err != nil {
+		return err
+	}
+	if len(request.IPWhitelist) == 0 || string(request.IPWhitelist) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(request.IPWhitelist, &u.IPWhitelist); err == nil {
+		return nil
+	}
+	u.IPWhitelist = nil
+	return json.Unmarshal(request.IPWhitelist, &u.IPWhitelistEntries)
+}
+
+// AddClusterIPWhitelistEntries adds the CIDRs to the IP whitelist of the cluster. The CIDRs are normalised, and CIDRs
+// that are already whitelisted are skipped. The change is applied to the current whitelist of the cluster, which is
+// read again after the update: when it differs from the expected whitelist, it was modified concurrently and an error
+// wrapping ErrConflict is returned. Existing entries keep their description, new entries are added without one.
+func AddClusterIPWhitelistEntries(ctx context.Context, client ClusterAPI, cluster Cluster, cidrs ...string) (*Cluster, error) {
+	additions, err := normaliseCIDRs(cidrs)
+	if err != nil {
+		return nil, err
+	}
+	return updateClusterIPWhitelist(ctx, client, cluster, func(current *Cluster) ([]string, error) {
+		ipWhitelist := ipWhitelistCIDRs(current.IPWhitelist)
+		for _, cidr := range additions {
+			if !slices.ContainsFunc(current.IPWhitelist, func(entry IpWhitelistResponse) bool { return sameCIDR(entry.Cidr, cidr) }) {
+				ipWhitelist = append(ipWhitelist, cidr)
+			}
+		}
+		return ipWhitelist, nil
+	})
+}
+
+// RemoveClusterIPWhitelistEntries removes the CIDRs from the IP whitelist of the cluster, comparing the normalised
+// CIDRs. CIDRs that are not whitelisted are ignored. Like AddClusterIPWhitelistEntries the change is applied to the
+// current whitelist of the cluster, and an error wrapping ErrConflict is returned when the whitelist read again after
+// the update differs from the expected whitelist. The API does not support emptying the whitelist on update, so removing all entries
+// returns an error.
+func RemoveClusterIPWhitelistEntries(ctx context.Context, client ClusterAPI, cluster Cluster, cidrs ...string) (*Cluster, error) {
+	removals, err := normaliseCIDRs(cidrs)
+	if err != nil {
+		return nil, err
+	}
+	return updateClusterIPWhitelist(ctx, client, cluster, func(current *Cluster) ([]string, error) {
+		var ipWhitelist []string
+		for _, entry := range current.IPWhitelist {
+			if !slices.ContainsFunc(removals, func(cidr string) bool { return sameCIDR(entry.Cidr, cidr) }) {
+				ipWhitelist = append(ipWhitelist, entry.Cidr)
+			}
+		}
+		return ipWhitelist, nil
+	})
+}
+
+// SetClusterIPWhitelist replaces the IP whitelist of the cluster with the normalised and de-duplicated CIDRs. Entries
+// that remain whitelisted keep their description.
+//
+// The cluster is expected to be the state the new whitelist is based on: when the current whitelist of the cluster
+// differs from cluster.IPWhitelist before the update, or from the new whitelist after the update, it was modified
+// concurrently and an error wrapping ErrConflict is returned.
+func SetClusterIPWhitelist(ctx context.Context, client ClusterAPI, cluster Cluster, cidrs []string) (*Cluster, error) {
+	normalised, err := normaliseCIDRs(cidrs)
+	if err != nil {
+		return nil, err
+	}
+	return updateClusterIPWhitelist(ctx, client, cluster, func(current *Cluster) ([]string, error) {
+		if !sameIPWhitelist(ipWhitelistCIDRs(cluster.IPWhitelist), ipWhitelistCIDRs(current.IPWhitelist)) {
+			return nil, fmt.Errorf("%w: the IP whitelist of cluster %s was modified concurrently", ErrConflict, cluster.Identifier())
+		}
+		// keep the existing notation of whitelisted CIDRs, so they are matched with their description
+		ipWhitelist := make([]string, len(normalised))
+		for i, cidr := range normalised {
+			ipWhitelist[i] = cidr
+			if index := slices.IndexFunc(current.IPWhitelist, func(entry IpWhitelistResponse) bool { return sameCIDR(entry.Cidr, cidr) }); index >= 0 {
+				ipWhitelist[i] = current.IPWhitelist[index].Cidr
+			}
+		}
+		return ipWhitelist, nil
+	})
+}
+
+// AddEnvironmentIPWhitelistEntries adds the CIDRs to the IP whitelist of every cluster in the environment, see
+// AddClusterIPWhitelistEntries. The updated clusters are returned, together with the errors of all failed clusters.
+func AddEnvironmentIPWhitelistEntries(ctx context.Context, client ClusterAPI, org, env string, cidrs ...string) ([]Cluster, error) {
+	return updateEnvironmentIPWhitelists(ctx, client, org, env, func(cluster Cluster) (*Cluster, error) {
+		return AddClusterIPWhitelistEntries(ctx, client, cluster, cidrs...)
+	})
+}
+
+// RemoveEnvironmentIPWhitelistEntries removes the CIDRs from the IP whitelist of every cluster in the environment, see
+// RemoveClusterIPWhitelistEntries. The updated clusters are returned, together with the errors of all failed clusters.
+func RemoveEnvironmentIPWhitelistEntries(ctx context.Context, client ClusterAPI, org, env string, cidrs ...string) ([]Cluster, error) {
+	return updateEnvironmentIPWhitelists(ctx, client, org, env, func(cluster Cluster) (*Cluster, error) {
+		return RemoveClusterIPWhitelistEntries(ctx, client, cluster, cidrs...)
+	})
+}
+
+// SetEnvironmentIPWhitelist replaces the IP whitelist of every cluster in the environment, see SetClusterIPWhitelist.
+// A cluster whose whitelist is modified between listing and updating the clusters fails with an error wrapping
+// ErrConflict. The updated clusters are returned, together with the errors of all failed clusters.
+func SetEnvironmentIPWhitelist(ctx context.Context, client ClusterAPI, org, env string, cidrs []string) ([]Cluster, error) {
+	return updateEnvironmentIPWhitelists(ctx, client, org, env, func(cluster Cluster) (*Cluster, error) {
+		return SetClusterIPWhitelist(ctx, client, cluster, cidrs)
+	})
+}
+
+// updateClusterIPWhitelist gets the current state of the cluster, and updates its IP whitelist to the one returned by
+// change. The cluster is not updated when the whitelist is unchanged.
+//
+// The API has no precondition for updates, so concurrent modifications are detected on a best-effort basis: the
+// cluster is read again right before the update, to not overwrite entries added or removed by others since it was
+// first read, and after the update, to detect an update of others that replaced ours. A modification between the last
+// read and the update is still overwritten.
+func updateClusterIPWhitelist(ctx context.Context, client ClusterAPI, cluster Cluster, change func(current *Cluster) ([]string, error)) (*Cluster, error) {
+	current, err := client.GetCluster(ctx, cluster.CustomerSlug, cluster.EnvironmentSlug, cluster.Slug)
+	if err != nil {
+		return nil, err
+	}
+	ipWhitelist, err := change(current)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Equal(ipWhitelist, ipWhitelistCIDRs(current.IPWhitelist)) {
+		return current, nil
+	}
+	if len(ipWhitelist) == 0 {
+		return nil, fmt.Errorf("cannot remove all entries from the IP whitelist of cluster %s", cluster.Identifier())
+	}
+	latest, err := client.GetCluster(ctx, cluster.CustomerSlug, cluster.EnvironmentSlug, cluster.Slug)
+	if err != nil {
+		return nil, err
+	}
+	if !sameIPWhitelist(ipWhitelistCIDRs(current.IPWhitelist), ipWhitelistCIDRs(latest.IPWhitelist)) {
+		return nil, fmt.Errorf("%w: the IP whitelist of cluster %s was modified concurrently", ErrConflict, cluster.Identifier())
+	}
+	// a whitelist of bare CIDRs drops all descriptions, so the entries are sent with their current description
+	entries := make([]IPWhitelistEntry, len(ipWhitelist))
+	for i, cidr := range ipWhitelist {
+		entries[i] = IPWhitelistEntry{Cidr: cidr}
+		if index := slices.IndexFunc(current.IPWhitelist, func(entry IpWhitelistResponse) bool { return entry.Cidr == cidr }); index >= 0 {
+			entries[i].Description = current.IPWhitelist[index].Description
+		}
+	}
+	if _, err := client.UpdateCluster(ctx, cluster.CustomerSlug, cluster.EnvironmentSlug, cluster.Slug, UpdateCluster{IPWhitelistEntries: entries}); err != nil {
+		return nil, err
+	}
+	updated, err := client.GetCluster(ctx, cluster.CustomerSlug, cluster.EnvironmentSlug, cluster.Slug)
+	if err != nil {
+		return nil, err
+	}
+	if !sameIPWhitelist(ipWhitelist, ipWhitelistCIDRs(updated.IPWhitelist)) {
+		return nil, fmt.Errorf("%w: the IP whitelist of cluster %s was modified concurrently", ErrConflict, cluster.Identifier())
+	}
+	return updated, nil
+}
+
+// updateEnvironmentIPWhitelists applies update to all clusters of the environment that are not being deleted
+func updateEnvironmentIPWhitelists(ctx context.Context, client ClusterAPI, org, env string, update func(cluster Cluster) (*Cluster, error)) ([]Cluster, error) {
+	clusters, err := client.GetClustersByOrgAndEnv(ctx, org, env)
+	if err != nil {
+		return nil, err
+	}
+	var updated []Cluster
+	var errs []error
+	for _, cluster := range clusters {
+		if cluster.Status == ClusterStatusDeleting || cluster.Status == ClusterStatusDeleted {
+			continue
+		}
+		result, err := update(cluster)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to update the IP whitelist of cluster %s: %w", cluster.Identifier(), err))
+			continue
+		}
+		updated = append(updated, *result)
+	}
+	return updated, errors.Join(errs...)
+}
+
+// normaliseCIDRs normalises the CIDRs and removes duplicates
+func normaliseCIDRs(cidrs []string) ([]string, error) {
+	var normalised []string
+	var errs []error
+	for _, cidr := range cidrs {
+		n, err := NormaliseCIDR(cidr)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !slices.Contains(normalised, n) {
+			normalised = append(normalised, n)
+		}
+	}
+	return normalised, errors.Join(errs...)
+}
+
+func ipWhitelistCIDRs(ipWhitelist []IpWhitelistResponse) []string {
+	cidrs := make([]string, len(ipWhitelist))
+	for i, entry := range ipWhitelist {
+		cidrs[i] = entry.Cidr
+	}
+	return cidrs
+}
+
+// sameCIDR returns true if both CIDRs are equal after normalisation
+func sameCIDR(a, b string) bool {
+	normalisedA, errA := NormaliseCIDR(a)
+	normalisedB, errB := NormaliseCIDR(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return normalisedA == normalisedB
+}
+
+// sameIPWhitelist returns true if both whitelists contain the same CIDRs, regardless of order and notation
+func sameIPWhitelist(a, b []string) bool {
+	normalise := func(cidrs []string) []string {
+		normalised := make([]string, 0, len(cidrs))
+		for _, cidr := range cidrs {
+			if n, err := NormaliseCIDR(cidr); err == nil {
+				cidr = n
+			}
+			if !slices.Contains(normalised, cidr) {
+				normalised = append(normalised, cidr)
+			}
+		}
+		slices.Sort(normalised)
+		return normalised
+	}
+	return slices.Equal(normalise(a), normalise(b))
+}
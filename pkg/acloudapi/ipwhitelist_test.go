@@ -0,0 +1,228 @@
+package acloudapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/avisi-cloud/go-client/pkg/acloudapi"
+	"github.com/avisi-cloud/go-client/pkg/acloudapi/acloudapitest"
+)
+
+func TestNormaliseCIDR(t *testing.T) {
+	tests := []struct {
+		cidr    string
+		want    string
+		wantErr bool
+	}{
+		{cidr: "192.0.2.0/24", want: "192.0.2.0/24"},
+		{cidr: "192.0.2.17/24", want: "192.0.2.0/24"},
+		{cidr: "192.0.2.17", want: "192.0.2.17/32"},
+		{cidr: "2001:DB8::1", want: "2001:db8::1/128"},
+		{cidr: "office", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cidr, func(t *testing.T) {
+			got, err := acloudapi.NormaliseCIDR(tt.cidr)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Fatalf("NormaliseCIDR(%q) = %q, %v, want %q", tt.cidr, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateClusterIPWhitelistJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		update acloudapi.UpdateCluster
+		want   string
+	}{
+		{name: "cidrs", update: acloudapi.UpdateCluster{IPWhitelist: []string{"192.0.2.0/24"}}, want: `{"ipWhitelist":["192.0.2.0/24"]}`},
+		{
+			name:   "entries",
+			update: acloudapi.UpdateCluster{IPWhitelist: []string{"10.0.0.0/8"}, IPWhitelistEntries: []acloudapi.IPWhitelistEntry{{Cidr: "192.0.2.0/24", Description: "office"}}},
+			want:   `{"ipWhitelist":[{"cidr":"192.0.2.0/24","description":"office"}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.update)
+			if err != nil || string(data) != tt.want {
+				t.Fatalf("unexpected JSON %s, %v, want %s", data, err, tt.want)
+			}
+			var decoded acloudapi.UpdateCluster
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if reencoded, _ := json.Marshal(decoded); string(reencoded) != tt.want {
+				t.Fatalf("unexpected decoded update %+v", decoded)
+			}
+		})
+	}
+}
+
+func TestClusterIPWhitelist(t *testing.T) {
+	server := acloudapitest.NewServer(acloudapitest.Opts{})
+	defer server.Close()
+	server.AddOrganisation(acloudapi.Organisation{Slug: "org1"})
+	server.AddEnvironment("org1", acloudapi.Environment{Slug: "env1"})
+	ctx := context.Background()
+	client := server.Client()
+	cluster, err := client.CreateCluster(ctx, "org1", "env1", acloudapi.CreateCluster{
+		Name:        "cluster1",
+		IPWhitelist: []acloudapi.IPWhitelistEntry{{Cidr: "192.0.2.0/24", Description: "office"}, {Cidr: "198.51.100.7/32", Description: "vpn"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	updated, err := acloudapi.AddClusterIPWhitelistEntries(ctx, client, *cluster, "203.0.113.9", "192.0.2.1/24", "203.0.113.9/32")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertIPWhitelist(t, updated, "192.0.2.0/24 office", "198.51.100.7/32 vpn", "203.0.113.9/32 ")
+
+	updated, err = acloudapi.RemoveClusterIPWhitelistEntries(ctx, client, *cluster, "198.51.100.7", "10.0.0.0/8")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertIPWhitelist(t, updated, "192.0.2.0/24 office", "203.0.113.9/32 ")
+
+	// cluster still has the whitelist it was created with
+	if _, err := acloudapi.SetClusterIPWhitelist(ctx, client, *cluster, []string{"192.0.2.0/24"}); !errors.Is(err, acloudapi.ErrConflict) {
+		t.Fatalf("expected a conflict, got %v", err)
+	}
+	updated, err = acloudapi.SetClusterIPWhitelist(ctx, client, *updated, []string{"10.0.0.1/8", "192.0.2.0/24", "10.0.0.0/8"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertIPWhitelist(t, updated, "10.0.0.0/8 ", "192.0.2.0/24 office")
+
+	if _, err := acloudapi.RemoveClusterIPWhitelistEntries(ctx, client, *updated, "10.0.0.0/8", "192.0.2.0/24"); err == nil {
+		t.Fatal("expected an error when removing all entries")
+	}
+	if _, err := acloudapi.AddClusterIPWhitelistEntries(ctx, client, *updated, "office"); err == nil {
+		t.Fatal("expected an error for an invalid CIDR")
+	}
+}
+
+// concurrentClient replaces the IP whitelist of the cluster right after every update, as if it was updated concurrently
+type concurrentClient struct {
+	acloudapi.Client
+	ipWhitelist []string
+}
+
+func (c concurrentClient) UpdateCluster(ctx context.Context, org, env, clusterSlug string, update acloudapi.UpdateCluster) (*acloudapi.Cluster, error) {
+	if _, err := c.Client.UpdateCluster(ctx, org, env, clusterSlug, update); err != nil {
+		return nil, err
+	}
+	return c.Client.UpdateCluster(ctx, org, env, clusterSlug, acloudapi.UpdateCluster{IPWhitelist: c.ipWhitelist})
+}
+
+func TestClusterIPWhitelistConcurrentUpdate(t *testing.T) {
+	server := acloudapitest.NewServer(acloudapitest.Opts{})
+	defer server.Close()
+	server.AddOrganisation(acloudapi.Organisation{Slug: "org1"})
+	server.AddEnvironment("org1", acloudapi.Environment{Slug: "env1"})
+	cluster := server.AddCluster(acloudapi.Cluster{CustomerSlug: "org1", EnvironmentSlug: "env1", Slug: "cluster1", IPWhitelist: []acloudapi.IpWhitelistResponse{{Cidr: "192.0.2.0/24"}}})
+	ctx := context.Background()
+	client := concurrentClient{Client: server.Client(), ipWhitelist: []string{"10.0.0.0/8", "172.16.0.0/12"}}
+
+	if _, err := acloudapi.AddClusterIPWhitelistEntries(ctx, client, cluster, "203.0.113.9"); !errors.Is(err, acloudapi.ErrConflict) {
+		t.Fatalf("expected a conflict, got %v", err)
+	}
+	if _, err := acloudapi.RemoveClusterIPWhitelistEntries(ctx, client, cluster, "172.16.0.0/12"); !errors.Is(err, acloudapi.ErrConflict) {
+		t.Fatalf("expected a conflict, got %v", err)
+	}
+	current, err := client.GetCluster(ctx, "org1", "env1", "cluster1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := acloudapi.SetClusterIPWhitelist(ctx, client, *current, []string{"192.0.2.0/24"}); !errors.Is(err, acloudapi.ErrConflict) {
+		t.Fatalf("expected a conflict, got %v", err)
+	}
+}
+
+// racingClient adds an entry to the IP whitelist of the cluster right after it is first read, as if a teammate added
+// it before our update
+type racingClient struct {
+	acloudapi.Client
+	reads *int
+}
+
+func (c racingClient) GetCluster(ctx context.Context, org, env, clusterSlug string, opts ...acloudapi.GetClusterOpts) (*acloudapi.Cluster, error) {
+	cluster, err := c.Client.GetCluster(ctx, org, env, clusterSlug, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if *c.reads++; *c.reads == 1 {
+		var ipWhitelist []string
+		for _, entry := range cluster.IPWhitelist {
+			ipWhitelist = append(ipWhitelist, entry.Cidr)
+		}
+		ipWhitelist = append(ipWhitelist, "198.51.100.7/32")
+		if _, err := c.Client.UpdateCluster(ctx, org, env, clusterSlug, acloudapi.UpdateCluster{IPWhitelist: ipWhitelist}); err != nil {
+			return nil, err
+		}
+	}
+	return cluster, nil
+}
+
+func TestClusterIPWhitelistUpdateBeforeOurs(t *testing.T) {
+	server := acloudapitest.NewServer(acloudapitest.Opts{})
+	defer server.Close()
+	server.AddOrganisation(acloudapi.Organisation{Slug: "org1"})
+	server.AddEnvironment("org1", acloudapi.Environment{Slug: "env1"})
+	cluster := server.AddCluster(acloudapi.Cluster{CustomerSlug: "org1", EnvironmentSlug: "env1", Slug: "cluster1", IPWhitelist: []acloudapi.IpWhitelistResponse{{Cidr: "192.0.2.0/24"}}})
+	reads := 0
+	client := racingClient{Client: server.Client(), reads: &reads}
+
+	if _, err := acloudapi.AddClusterIPWhitelistEntries(context.Background(), client, cluster, "203.0.113.9"); !errors.Is(err, acloudapi.ErrConflict) {
+		t.Fatalf("expected a conflict, got %v", err)
+	}
+	current, _ := server.Cluster(cluster.Identity)
+	if len(current.IPWhitelist) != 2 || current.IPWhitelist[1].Cidr != "198.51.100.7/32" {
+		t.Fatalf("the entry added concurrently was overwritten: %+v", current.IPWhitelist)
+	}
+}
+
+func TestEnvironmentIPWhitelist(t *testing.T) {
+	server := acloudapitest.NewServer(acloudapitest.Opts{})
+	defer server.Close()
+	server.AddOrganisation(acloudapi.Organisation{Slug: "org1"})
+	server.AddEnvironment("org1", acloudapi.Environment{Slug: "env1"})
+	server.AddCluster(acloudapi.Cluster{CustomerSlug: "org1", EnvironmentSlug: "env1", Slug: "web", IPWhitelist: []acloudapi.IpWhitelistResponse{{Cidr: "192.0.2.0/24", Description: "office"}}})
+	server.AddCluster(acloudapi.Cluster{CustomerSlug: "org1", EnvironmentSlug: "env1", Slug: "api"})
+	server.AddCluster(acloudapi.Cluster{CustomerSlug: "org1", EnvironmentSlug: "env1", Slug: "old", Status: acloudapi.ClusterStatusDeleted})
+	ctx := context.Background()
+
+	clusters, err := acloudapi.AddEnvironmentIPWhitelistEntries(ctx, server.Client(), "org1", "env1", "203.0.113.0/24")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(clusters) != 2 {
+		t.Fatalf("expected 2 updated clusters, got %d", len(clusters))
+	}
+	web, _ := server.Cluster(clusters[slices.IndexFunc(clusters, func(c acloudapi.Cluster) bool { return c.Slug == "web" })].Identity)
+	assertIPWhitelist(t, &web, "192.0.2.0/24 office", "203.0.113.0/24 ")
+
+	_, err = acloudapi.RemoveEnvironmentIPWhitelistEntries(ctx, server.Client(), "org1", "env1", "203.0.113.0/24")
+	if err == nil {
+		t.Fatal("expected an error for the cluster that would have an empty whitelist")
+	}
+	web, _ = server.Cluster(web.Identity)
+	assertIPWhitelist(t, &web, "192.0.2.0/24 office")
+}
+
+func assertIPWhitelist(t *testing.T, cluster *acloudapi.Cluster, want ...string) {
+	t.Helper()
+	got := make([]string, len(cluster.IPWhitelist))
+	for i, entry := range cluster.IPWhitelist {
+		got[i] = entry.Cidr + " " + entry.Description
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("unexpected IP whitelist %q, want %q", got, want)
+	}
+}
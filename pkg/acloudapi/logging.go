@@ -0,0 +1,209 @@
+package acloudapi
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/go-resty/resty/v2"
+)
+
+const omittedHeaderValue = "<omitted>"
+
+// sensitiveHeaders are redacted from the logged request and response headers
+var sensitiveHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+}
+
+// requestLogger logs every API request and response (including each retry attempt) as structured log records
+type requestLogger struct {
+	logger                       *slog.Logger
+	requestLevel                 slog.Leveler
+	errorLevel                   slog.Level
+	trace                        bool
+	debugShowAuthorizationHeader bool
+
+	// loggedAttempts holds the last attempt of a *resty.Request logged by onRetry, as resty also calls the retry hooks
+	// after the last attempt
+	loggedAttempts sync.Map
+}
+
+// newRequestLogger returns the requestLogger for the ClientOpts, or nil if neither a Logger is set nor Trace is enabled.
+// When only Trace is enabled, requests are logged using slog.Default at level Info.
+func newRequestLogger(opts ClientOpts) *requestLogger {
+	if opts.Logger == nil && !opts.Trace {
+		return nil
+	}
+	l := &requestLogger{
+		logger:                       opts.Logger,
+		requestLevel:                 opts.RequestLogLevel,
+		errorLevel:                   slog.LevelWarn,
+		trace:                        opts.Trace,
+		debugShowAuthorizationHeader: opts.DebugShowAuthorizationHeader,
+	}
+	if l.logger == nil {
+		l.logger = slog.Default()
+		if l.requestLevel == nil {
+			l.requestLevel = slog.LevelInfo
+		}
+	}
+	if l.requestLevel == nil {
+		l.requestLevel = slog.LevelDebug
+	}
+	if opts.ErrorLogLevel != nil {
+		l.errorLevel = opts.ErrorLogLevel.Level()
+	}
+	return l
+}
+
+func (l *requestLogger) register(client *resty.Client, setRestyLogger bool) {
+	if setRestyLogger {
+		client.SetLogger(&slogRestyLogger{logger: l.logger})
+	}
+	if l.trace {
+		client.EnableTrace()
+	}
+	client.OnAfterResponse(l.onAfterResponse)
+	client.AddRetryHook(l.onRetry)
+	client.OnError(l.onError)
+	client.OnSuccess(func(_ *resty.Client, response *resty.Response) { l.loggedAttempts.Delete(response.Request) })
+	client.OnPanic(func(request *resty.Request, _ error) { l.loggedAttempts.Delete(request) })
+}
+
+func (l *requestLogger) onAfterResponse(_ *resty.Client, response *resty.Response) error {
+	level := l.requestLevel.Level()
+	if !response.IsSuccess() {
+		level = l.errorLevel
+	}
+	ctx := response.Request.Context()
+	if !l.logger.Enabled(ctx, level) {
+		return nil
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", response.Request.Method),
+		slog.String("path", requestPath(response.Request)),
+		slog.Int("status", response.StatusCode()),
+		slog.Duration("duration", response.Time()),
+		slog.Int("attempt", response.Request.Attempt),
+	}
+	if requestID := response.Header().Get(HeaderRequestID); requestID != "" {
+		attrs = append(attrs, slog.String("requestId", requestID))
+	}
+	if response.Request.RawRequest != nil {
+		attrs = append(attrs, l.headersAttr("requestHeaders", response.Request.RawRequest.Header))
+	}
+	attrs = append(attrs, l.headersAttr("responseHeaders", response.Header()))
+	if l.trace {
+		attrs = append(attrs, traceAttr(response.Request.TraceInfo()))
+	}
+	l.logger.LogAttrs(ctx, level, "api request", attrs...)
+	return nil
+}
+
+// onRetry logs an attempt that failed without a response, before it is retried. Attempts with a response are logged
+// by onAfterResponse.
+func (l *requestLogger) onRetry(response *resty.Response, err error) {
+	if err == nil || response == nil || response.Request == nil || response.RawResponse != nil {
+		return
+	}
+	l.loggedAttempts.Store(response.Request, response.Request.Attempt)
+	l.logFailure(response.Request, err)
+}
+
+// onError logs the last attempt of a request that failed without a response
+func (l *requestLogger) onError(request *resty.Request, err error) {
+	if attempt, ok := l.loggedAttempts.LoadAndDelete(request); ok && attempt == request.Attempt {
+		return // already logged by onRetry
+	}
+	if responseError, ok := err.(*resty.ResponseError); ok && responseError.Response.RawResponse != nil {
+		return // already logged by onAfterResponse
+	}
+	l.logFailure(request, err)
+}
+
+func (l *requestLogger) logFailure(request *resty.Request, err error) {
+	ctx := request.Context()
+	if !l.logger.Enabled(ctx, l.errorLevel) {
+		return
+	}
+	l.logger.LogAttrs(ctx, l.errorLevel, "api request failed",
+		slog.String("method", request.Method),
+		slog.String("path", requestPath(request)),
+		slog.Int("attempt", request.Attempt),
+		slog.String("error", err.Error()),
+	)
+}
+
+func (l *requestLogger) headersAttr(key string, headers http.Header) slog.Attr {
+	attrs := make([]any, 0, len(headers))
+	for name, values := range headers {
+		value := strings.Join(values, ", ")
+		if l.isRedacted(name) {
+			value = omittedHeaderValue
+		}
+		attrs = append(attrs, slog.String(name, value))
+	}
+	return slog.Group(key, attrs...)
+}
+
+func (l *requestLogger) isRedacted(name string) bool {
+	if l.debugShowAuthorizationHeader && http.CanonicalHeaderKey(name) == "Authorization" {
+		return false
+	}
+	for _, sensitiveHeader := range sensitiveHeaders {
+		if http.CanonicalHeaderKey(name) == sensitiveHeader {
+			return true
+		}
+	}
+	return false
+}
+
+func traceAttr(ti resty.TraceInfo) slog.Attr {
+	attrs := []any{
+		slog.Duration("dnsLookup", ti.DNSLookup),
+		slog.Duration("connTime", ti.ConnTime),
+		slog.Duration("tcpConnTime", ti.TCPConnTime),
+		slog.Duration("tlsHandshake", ti.TLSHandshake),
+		slog.Duration("serverTime", ti.ServerTime),
+		slog.Duration("responseTime", ti.ResponseTime),
+		slog.Duration("totalTime", ti.TotalTime),
+		slog.Bool("isConnReused", ti.IsConnReused),
+		slog.Bool("isConnWasIdle", ti.IsConnWasIdle),
+		slog.Duration("connIdleTime", ti.ConnIdleTime),
+	}
+	if ti.RemoteAddr != nil {
+		attrs = append(attrs, slog.String("remoteAddr", ti.RemoteAddr.String()))
+	}
+	return slog.Group("trace", attrs...)
+}
+
+func requestPath(request *resty.Request) string {
+	if request.RawRequest != nil {
+		return request.RawRequest.URL.RequestURI()
+	}
+	return request.URL
+}
+
+// slogRestyLogger implements the resty.Logger interface using a slog.Logger
+type slogRestyLogger struct {
+	logger *slog.Logger
+}
+
+func (l *slogRestyLogger) Errorf(format string, v ...interface{}) {
+	l.logger.Log(context.Background(), slog.LevelError, strings.TrimSpace(fmt.Sprintf(format, v...)))
+}
+
+func (l *slogRestyLogger) Warnf(format string, v ...interface{}) {
+	l.logger.Log(context.Background(), slog.LevelWarn, strings.TrimSpace(fmt.Sprintf(format, v...)))
+}
+
+func (l *slogRestyLogger) Debugf(format string, v ...interface{}) {
+	l.logger.Log(context.Background(), slog.LevelDebug, strings.TrimSpace(fmt.Sprintf(format, v...)))
+}
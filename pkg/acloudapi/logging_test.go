@@ -0,0 +1,209 @@
+package acloudapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+func TestRequestLogging(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderRequestID, "request-1")
+		w.Header().Set("Set-Cookie", "session=secret")
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		fmt.Fprint(w, `{}`)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		path       string
+		opts       ClientOpts
+		wantLevel  string
+		wantStatus float64
+		wantAuth   string
+	}{
+		{
+			name:       "success at debug level",
+			path:       "/api/v1/orgs?page=1",
+			opts:       ClientOpts{},
+			wantLevel:  "DEBUG",
+			wantStatus: http.StatusOK,
+			wantAuth:   omittedHeaderValue,
+		},
+		{
+			name:       "error at warn level",
+			path:       "/missing",
+			opts:       ClientOpts{RetryPolicy: NoRetryPolicy()},
+			wantLevel:  "WARN",
+			wantStatus: http.StatusNotFound,
+			wantAuth:   omittedHeaderValue,
+		},
+		{
+			name:       "custom levels",
+			path:       "/missing",
+			opts:       ClientOpts{RetryPolicy: NoRetryPolicy(), ErrorLogLevel: slog.LevelError},
+			wantLevel:  "ERROR",
+			wantStatus: http.StatusNotFound,
+			wantAuth:   omittedHeaderValue,
+		},
+		{
+			name:       "show authorization header",
+			path:       "/",
+			opts:       ClientOpts{RequestLogLevel: slog.LevelInfo, DebugShowAuthorizationHeader: true},
+			wantLevel:  "INFO",
+			wantStatus: http.StatusOK,
+			wantAuth:   "Token token",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			opts := tt.opts
+			opts.APIUrl = server.URL
+			opts.Logger = slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+			c := NewRestyClient(NewPersonalAccessTokenAuthenticator("token"), opts)
+			if _, err := c.R().Get(tt.path); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			record := map[string]any{}
+			if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+				t.Fatalf("expected a single json log record, got %q: %v", buf.String(), err)
+			}
+			if record["msg"] != "api request" || record["level"] != tt.wantLevel {
+				t.Errorf("unexpected record: %v", record)
+			}
+			if record["method"] != http.MethodGet || record["path"] != tt.path || record["status"] != tt.wantStatus {
+				t.Errorf("unexpected request attributes: %v", record)
+			}
+			if record["attempt"] != float64(1) || record["requestId"] != "request-1" {
+				t.Errorf("unexpected attempt or request id: %v", record)
+			}
+			requestHeaders, _ := record["requestHeaders"].(map[string]any)
+			if requestHeaders["Authorization"] != tt.wantAuth {
+				t.Errorf("unexpected Authorization header: %v", requestHeaders["Authorization"])
+			}
+			responseHeaders, _ := record["responseHeaders"].(map[string]any)
+			if responseHeaders["Set-Cookie"] != omittedHeaderValue {
+				t.Errorf("unexpected Set-Cookie header: %v", responseHeaders["Set-Cookie"])
+			}
+		})
+	}
+
+	t.Run("logs every retry attempt", func(t *testing.T) {
+		server, _ := newFlakyServer(2, http.StatusServiceUnavailable)
+		defer server.Close()
+
+		buf := &bytes.Buffer{}
+		c := NewRestyClient(nil, ClientOpts{
+			APIUrl:      server.URL,
+			RetryPolicy: testRetryPolicy(),
+			Logger:      slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		})
+		if _, err := c.R().Get("/"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		decoder := json.NewDecoder(buf)
+		for attempt := 1; attempt <= 3; attempt++ {
+			record := map[string]any{}
+			if err := decoder.Decode(&record); err != nil {
+				t.Fatalf("expected log record for attempt %d: %v", attempt, err)
+			}
+			if record["attempt"] != float64(attempt) {
+				t.Errorf("attempt = %v, want %d", record["attempt"], attempt)
+			}
+		}
+	})
+
+	t.Run("logs transport errors", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		c := NewRestyClient(nil, ClientOpts{
+			APIUrl:      "http://127.0.0.1:0",
+			RetryPolicy: NoRetryPolicy(),
+			Logger:      slog.New(slog.NewJSONHandler(buf, nil)),
+		})
+		if _, err := c.R().Get("/"); err == nil {
+			t.Fatal("expected error")
+		}
+		record := map[string]any{}
+		if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+			t.Fatalf("expected a single json log record, got %q: %v", buf.String(), err)
+		}
+		if record["msg"] != "api request failed" || record["level"] != "WARN" || record["error"] == nil {
+			t.Errorf("unexpected record: %v", record)
+		}
+	})
+
+	t.Run("logs every failed attempt", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		c := NewRestyClient(nil, ClientOpts{
+			APIUrl:      "http://127.0.0.1:0",
+			RetryPolicy: &RetryPolicy{MaxAttempts: 3, WaitTime: time.Millisecond, MaxWaitTime: time.Millisecond, RetryNetworkErrors: true, RetryableMethods: []string{http.MethodGet}},
+			Logger:      slog.New(slog.NewJSONHandler(buf, nil)),
+		})
+		if _, err := c.R().Get("/"); err == nil {
+			t.Fatal("expected error")
+		}
+		// skip the retry warnings logged by resty
+		var attempts []any
+		decoder := json.NewDecoder(buf)
+		for decoder.More() {
+			record := map[string]any{}
+			if err := decoder.Decode(&record); err != nil {
+				t.Fatalf("invalid log record: %v", err)
+			}
+			if record["msg"] == "api request failed" {
+				attempts = append(attempts, record["attempt"])
+			}
+		}
+		if fmt.Sprint(attempts) != "[1 2 3]" {
+			t.Errorf("logged attempts %v, want [1 2 3]", attempts)
+		}
+	})
+
+	t.Run("trace with a custom resty client", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		c := NewRestyClient(nil, ClientOpts{
+			CustomResty: resty.New().SetBaseURL(server.URL),
+			Trace:       true,
+			Logger:      slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		})
+		if _, err := c.R().Get("/"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		record := map[string]any{}
+		if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+			t.Fatalf("expected a single json log record, got %q: %v", buf.String(), err)
+		}
+		if _, ok := record["trace"].(map[string]any); !ok || record["msg"] != "api request" {
+			t.Errorf("expected a record with trace timings: %v", record)
+		}
+	})
+
+	t.Run("custom resty client shared by several clients", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		opts := ClientOpts{
+			CustomResty: resty.New().SetBaseURL(server.URL),
+			Logger:      slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		}
+		NewRestyClient(nil, opts)
+		c := NewRestyClient(nil, opts)
+		if _, err := c.R().Get("/"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if records := strings.Count(buf.String(), "\n"); records != 1 {
+			t.Errorf("expected a single log record, got %d: %s", records, buf.String())
+		}
+	})
+}
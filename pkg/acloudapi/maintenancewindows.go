@@ -0,0 +1,415 @@
+package acloudapi
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	minutesPerDay  = 24 * 60
+	minutesPerWeek = 7 * minutesPerDay
+	secondsPerWeek = minutesPerWeek * 60
+)
+
+// MaintenanceWindowOccurrence is a single occurrence of a weekly maintenance window
+type MaintenanceWindowOccurrence struct {
+	Window MaintenanceWindow `json:"-"`
+	Start  time.Time         `json:"start"`
+	End    time.Time         `json:"end"`
+}
+
+func (o MaintenanceWindowOccurrence) String() string {
+	return fmt.Sprintf("%s - %s", o.Start.Format("Mon 2006-01-02 15:04"), o.End.Format("15:04 MST"))
+}
+
+// Contains returns true if t is inside the occurrence
+func (o MaintenanceWindowOccurrence) Contains(t time.Time) bool {
+	return !t.Before(o.Start) && t.Before(o.End)
+}
+
+// ParseMaintenanceWindow parses a maintenance window as formatted by MaintenanceWindow.String, e.g.
+// "MONDAY 02:00 120 minutes". The duration can also be a Go duration, e.g. "MONDAY 02:00 2h".
+func ParseMaintenanceWindow(s string) (MaintenanceWindow, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 4 && (fields[3] == "minutes" || fields[3] == "minute") {
+		fields = fields[:3]
+	}
+	if len(fields) != 3 {
+		return MaintenanceWindow{}, fmt.Errorf("invalid maintenance window %q: expected a day, start time and duration", s)
+	}
+	window := MaintenanceWindow{Day: strings.ToUpper(fields[0]), StartTime: fields[1]}
+	if minutes, err := strconv.Atoi(fields[2]); err == nil {
+		window.Duration = minutes
+	} else if duration, err := time.ParseDuration(fields[2]); err == nil {
+		window.Duration = int(duration.Minutes())
+	} else {
+		return MaintenanceWindow{}, fmt.Errorf("invalid maintenance window %q: invalid duration %q", s, fields[2])
+	}
+	if err := window.Validate(); err != nil {
+		return MaintenanceWindow{}, err
+	}
+	return window, nil
+}
+
+// Validate checks the day, start time and duration of the maintenance window. The day is the name of a weekday, e.g.
+// MONDAY or Mon, the start time is formatted as 15:04 or 15:04:05, and the duration is at most a week.
+func (m MaintenanceWindow) Validate() error {
+	if _, err := m.Weekday(); err != nil {
+		return err
+	}
+	if _, err := m.startOfDay(); err != nil {
+		return err
+	}
+	if m.Duration <= 0 || m.Duration > minutesPerWeek {
+		return fmt.Errorf("invalid duration %d of maintenance window %s: must be between 1 minute and a week", m.Duration, m)
+	}
+	return nil
+}
+
+// Weekday returns the day of the week the maintenance window starts on
+func (m MaintenanceWindow) Weekday() (time.Weekday, error) {
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		if strings.EqualFold(m.Day, weekday.String()) || strings.EqualFold(m.Day, weekday.String()[:3]) {
+			return weekday, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid day %q of maintenance window", m.Day)
+}
+
+// startOfDay returns the start time of the maintenance window as the duration since midnight
+func (m MaintenanceWindow) startOfDay() (time.Duration, error) {
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		if start, err := time.Parse(layout, m.StartTime); err == nil {
+			return time.Duration(start.Hour())*time.Hour + time.Duration(start.Minute())*time.Minute + time.Duration(start.Second())*time.Second, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid start time %q of maintenance window", m.StartTime)
+}
+
+// weekSeconds returns the start of the maintenance window as the seconds since the start of the week (Sunday 00:00),
+// and its duration in seconds
+func (m MaintenanceWindow) weekSeconds() (int, int, error) {
+	if err := m.Validate(); err != nil {
+		return 0, 0, err
+	}
+	weekday, _ := m.Weekday()
+	start, _ := m.startOfDay()
+	return int(weekday)*minutesPerDay*60 + int(start.Seconds()), m.Duration * 60, nil
+}
+
+// NextOccurrences returns the next n occurrences of the maintenance window that have not ended at from, so an
+// occurrence in progress is included. The start time of the window is interpreted in loc, or UTC if loc is nil. A start
+// time that does not exist on a day with a daylight saving time change is moved forward by the size of the change.
+func (m MaintenanceWindow) NextOccurrences(from time.Time, loc *time.Location, n int) ([]MaintenanceWindowOccurrence, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	weekday, _ := m.Weekday()
+	start, _ := m.startOfDay()
+	local := from.In(loc)
+	// start one week back, to include an occurrence that started last week and is still in progress
+	days := (int(weekday)-int(local.Weekday())+7)%7 - 7
+	occurrences := make([]MaintenanceWindowOccurrence, 0, n)
+	for week := 0; len(occurrences) < n; week++ {
+		date := local.AddDate(0, 0, days+7*week)
+		occurrence := MaintenanceWindowOccurrence{Window: m}
+		// use the wall clock start time, as a day with a daylight saving time change is shorter or longer than 24 hours
+		occurrence.Start = time.Date(date.Year(), date.Month(), date.Day(), int(start/time.Hour), int(start%time.Hour/time.Minute), int(start%time.Minute/time.Second), 0, loc)
+		occurrence.End = occurrence.Start.Add(time.Duration(m.Duration) * time.Minute)
+		if occurrence.End.After(from) {
+			occurrences = append(occurrences, occurrence)
+		}
+	}
+	return occurrences, nil
+}
+
+// Contains returns true if t is inside an occurrence of the maintenance window, interpreted in loc or UTC if loc is nil
+func (m MaintenanceWindow) Contains(t time.Time, loc *time.Location) (bool, error) {
+	occurrences, err := m.NextOccurrences(t, loc, 1)
+	if err != nil {
+		return false, err
+	}
+	return occurrences[0].Contains(t), nil
+}
+
+// MaintenanceWindowOverlap is a pair of overlapping maintenance windows
+type MaintenanceWindowOverlap struct {
+	First  MaintenanceWindow
+	Second MaintenanceWindow
+}
+
+func (o MaintenanceWindowOverlap) String() string {
+	return fmt.Sprintf("maintenance window %s overlaps with %s", o.First, o.Second)
+}
+
+// Validate checks all maintenance windows of the schedule, and that they do not overlap
+func (s MaintenanceSchedule) Validate() error {
+	var errs []error
+	for _, window := range s.MaintenanceWindows {
+		if err := window.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	for _, overlap := range s.Overlaps() {
+		errs = append(errs, errors.New(overlap.String()))
+	}
+	return errors.Join(errs...)
+}
+
+// Overlaps returns the pairs of maintenance windows of the schedule that overlap, including windows that wrap around
+// the end of the week. Invalid windows are ignored.
+func (s MaintenanceSchedule) Overlaps() []MaintenanceWindowOverlap {
+	var overlaps []MaintenanceWindowOverlap
+	for i, first := range s.MaintenanceWindows {
+		firstStart, firstDuration, err := first.weekSeconds()
+		if err != nil {
+			continue
+		}
+		for _, second := range s.MaintenanceWindows[i+1:] {
+			secondStart, secondDuration, err := second.weekSeconds()
+			if err != nil {
+				continue
+			}
+			if weekDistance(firstStart, secondStart) < firstDuration || weekDistance(secondStart, firstStart) < secondDuration {
+				overlaps = append(overlaps, MaintenanceWindowOverlap{First: first, Second: second})
+			}
+		}
+	}
+	return overlaps
+}
+
+// weekDistance returns the seconds from a to b, wrapping around the end of the week
+func weekDistance(a, b int) int {
+	return ((b-a)%secondsPerWeek + secondsPerWeek) % secondsPerWeek
+}
+
+// NextOccurrences returns the next n occurrences of all maintenance windows of the schedule that have not ended at
+// from, ordered by their start, see MaintenanceWindow.NextOccurrences
+func (s MaintenanceSchedule) NextOccurrences(from time.Time, loc *time.Location, n int) ([]MaintenanceWindowOccurrence, error) {
+	var occurrences []MaintenanceWindowOccurrence
+	for _, window := range s.MaintenanceWindows {
+		windowOccurrences, err := window.NextOccurrences(from, loc, n)
+		if err != nil {
+			return nil, err
+		}
+		occurrences = append(occurrences, windowOccurrences...)
+	}
+	slices.SortStableFunc(occurrences, func(a, b MaintenanceWindowOccurrence) int { return a.Start.Compare(b.Start) })
+	return occurrences[:min(n, len(occurrences))], nil
+}
+
+// Contains returns true if t is inside any maintenance window of the schedule
+func (s MaintenanceSchedule) Contains(t time.Time, loc *time.Location) (bool, error) {
+	for _, window := range s.MaintenanceWindows {
+		contains, err := window.Contains(t, loc)
+		if err != nil || contains {
+			return contains, err
+		}
+	}
+	return false, nil
+}
+
+// ICalendarOpts are the options of WriteICalendar
+type ICalendarOpts struct {
+	// Name is the name of the calendar
+	Name string
+	// Location is the time zone of the maintenance windows, defaults to UTC
+	Location *time.Location
+	// Now is the time the calendar is generated at, and from which the first occurrences start. Defaults to the
+	// current time.
+	Now time.Time
+}
+
+func mergeICalendarOpts(opts []ICalendarOpts) ICalendarOpts {
+	merged := ICalendarOpts{}
+	for _, opt := range opts {
+		if opt.Name != "" {
+			merged.Name = opt.Name
+		}
+		if opt.Location != nil {
+			merged.Location = opt.Location
+		}
+		if !opt.Now.IsZero() {
+			merged.Now = opt.Now
+		}
+	}
+	if merged.Location == nil {
+		merged.Location = time.UTC
+	}
+	if merged.Now.IsZero() {
+		merged.Now = time.Now()
+	}
+	return merged
+}
+
+// WriteICalendar writes the maintenance schedules as an iCalendar (RFC 5545) calendar, with a weekly recurring event
+// per maintenance window, so the schedules can be subscribed to. Time zones other than UTC are included as a VTIMEZONE
+// component, with yearly recurring rules derived from the daylight saving time transitions in the year of Now.
+func WriteICalendar(w io.Writer, schedules []MaintenanceSchedule, opts ...ICalendarOpts) error {
+	mergedOpts := mergeICalendarOpts(opts)
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Avisi Cloud//go-client//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+	}
+	if mergedOpts.Name != "" {
+		lines = append(lines, "X-WR-CALNAME:"+escapeICalendarText(mergedOpts.Name))
+	}
+	if mergedOpts.Location != time.UTC {
+		lines = append(lines, "X-WR-TIMEZONE:"+mergedOpts.Location.String())
+		lines = append(lines, iCalendarTimezone(mergedOpts.Location, mergedOpts.Now.In(mergedOpts.Location).Year())...)
+	}
+	stamp := mergedOpts.Now.UTC().Format("20060102T150405Z")
+	for _, schedule := range schedules {
+		uid := schedule.Identity
+		if uid == "" {
+			uid = strings.ToLower(strings.Join(strings.Fields(schedule.Name), "-"))
+		}
+		for i, window := range schedule.MaintenanceWindows {
+			occurrences, err := window.NextOccurrences(mergedOpts.Now, mergedOpts.Location, 1)
+			if err != nil {
+				return fmt.Errorf("invalid maintenance schedule %s: %w", schedule.Name, err)
+			}
+			weekday, _ := window.Weekday()
+			lines = append(lines,
+				"BEGIN:VEVENT",
+				fmt.Sprintf("UID:%s-%d@avisi.cloud", uid, i),
+				"DTSTAMP:"+stamp,
+				"DTSTART"+formatICalendarTime(occurrences[0].Start, mergedOpts.Location),
+				fmt.Sprintf("DURATION:PT%dM", window.Duration),
+				"RRULE:FREQ=WEEKLY;BYDAY="+strings.ToUpper(weekday.String()[:2]),
+				"SUMMARY:"+escapeICalendarText("Maintenance window "+schedule.Name),
+				"DESCRIPTION:"+escapeICalendarText(window.String()),
+				"END:VEVENT",
+			)
+		}
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(foldICalendarLine(line))
+		b.WriteString("\r\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// formatICalendarTime formats the time as the value of a DTSTART property, including its TZID parameter that refers
+// to the VTIMEZONE component of the location
+func formatICalendarTime(t time.Time, loc *time.Location) string {
+	if loc == time.UTC {
+		return ":" + t.UTC().Format("20060102T150405Z")
+	}
+	return fmt.Sprintf(";TZID=%s:%s", loc, t.In(loc).Format("20060102T150405"))
+}
+
+// iCalendarTimezone returns the VTIMEZONE component of the location. Every transition of the location in the year
+// becomes a STANDARD or DAYLIGHT observance recurring yearly on the same weekday of the month, e.g. the last Sunday of
+// March. A location without transitions in the year has a single STANDARD observance.
+func iCalendarTimezone(loc *time.Location, year int) []string {
+	lines := []string{"BEGIN:VTIMEZONE", "TZID:" + loc.String()}
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, loc)
+	end := start.AddDate(1, 0, 0)
+	transitions := 0
+	for t := start; ; {
+		_, next := t.ZoneBounds()
+		if next.IsZero() || !next.Before(end) {
+			break
+		}
+		_, offsetFrom := next.Add(-time.Second).Zone()
+		name, offsetTo := next.Zone()
+		component := "STANDARD"
+		if next.IsDST() {
+			component = "DAYLIGHT"
+		}
+		// the onset is the local time before the transition
+		onset := next.In(time.FixedZone("", offsetFrom))
+		lines = append(lines,
+			"BEGIN:"+component,
+			"DTSTART:"+onset.Format("20060102T150405"),
+			fmt.Sprintf("RRULE:FREQ=YEARLY;BYMONTH=%d;BYDAY=%s", onset.Month(), iCalendarMonthlyWeekday(onset)),
+			"TZOFFSETFROM:"+formatUTCOffset(offsetFrom),
+			"TZOFFSETTO:"+formatUTCOffset(offsetTo),
+			"TZNAME:"+name,
+			"END:"+component,
+		)
+		transitions++
+		t = next
+	}
+	if transitions == 0 {
+		name, offset := start.Zone()
+		lines = append(lines,
+			"BEGIN:STANDARD",
+			"DTSTART:"+start.Format("20060102T150405"),
+			"TZOFFSETFROM:"+formatUTCOffset(offset),
+			"TZOFFSETTO:"+formatUTCOffset(offset),
+			"TZNAME:"+name,
+			"END:STANDARD",
+		)
+	}
+	return append(lines, "END:VTIMEZONE")
+}
+
+// iCalendarMonthlyWeekday returns the weekday of the date as its occurrence in the month, e.g. 2SU for the second
+// Sunday, or -1SU for a last Sunday
+func iCalendarMonthlyWeekday(date time.Time) string {
+	weekday := strings.ToUpper(date.Weekday().String()[:2])
+	daysInMonth := time.Date(date.Year(), date.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	if date.Day()+7 > daysInMonth {
+		return "-1" + weekday
+	}
+	return strconv.Itoa((date.Day()-1)/7+1) + weekday
+}
+
+// formatUTCOffset formats an offset in seconds east of UTC as the value of a TZOFFSETFROM or TZOFFSETTO property,
+// e.g. +0100
+func formatUTCOffset(offset int) string {
+	sign := "+"
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+	formatted := fmt.Sprintf("%s%02d%02d", sign, offset/3600, offset/60%60)
+	if seconds := offset % 60; seconds != 0 {
+		formatted += fmt.Sprintf("%02d", seconds)
+	}
+	return formatted
+}
+
+var iCalendarTextEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func escapeICalendarText(s string) string {
+	return iCalendarTextEscaper.Replace(s)
+}
+
+// foldICalendarLine folds lines longer than 75 octets, without splitting UTF-8 encoded characters
+func foldICalendarLine(line string) string {
+	const maxLength = 75
+	var b strings.Builder
+	length := 0
+	for _, r := range line {
+		size := utf8.RuneLen(r)
+		if length+size > maxLength {
+			b.WriteString("\r\n ")
+			length = 1
+		}
+		b.WriteRune(r)
+		length += size
+	}
+	return b.String()
+}
@@ -0,0 +1,284 @@
+package acloudapi
+
+import (
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestParseMaintenanceWindow(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    MaintenanceWindow
+		wantErr string
+	}{
+		{input: "MONDAY 02:00 120 minutes", want: MaintenanceWindow{Day: "MONDAY", StartTime: "02:00", Duration: 120}},
+		{input: "sat 23:30 2h", want: MaintenanceWindow{Day: "SAT", StartTime: "23:30", Duration: 120}},
+		{input: "MONDAY 02:00:00 60", want: MaintenanceWindow{Day: "MONDAY", StartTime: "02:00:00", Duration: 60}},
+		{input: "SOMEDAY 02:00 60", wantErr: "invalid day"},
+		{input: "MONDAY 25:00 60", wantErr: "invalid start time"},
+		{input: "MONDAY 02:00 0", wantErr: "invalid duration"},
+		{input: "MONDAY 02:00", wantErr: "expected a day, start time and duration"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			window, err := ParseMaintenanceWindow(tt.input)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if window != tt.want {
+				t.Fatalf("got %+v, want %+v", window, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaintenanceScheduleOverlaps(t *testing.T) {
+	schedule := MaintenanceSchedule{MaintenanceWindows: []MaintenanceWindow{
+		{Day: "MONDAY", StartTime: "02:00", Duration: 120},
+		{Day: "MONDAY", StartTime: "03:59", Duration: 30},
+		{Day: "WEDNESDAY", StartTime: "02:00", Duration: 60},
+		{Day: "SUNDAY", StartTime: "23:00", Duration: 240},
+		{Day: "SATURDAY", StartTime: "22:00", Duration: 60},
+	}}
+	overlaps := schedule.Overlaps()
+	if len(overlaps) != 2 {
+		t.Fatalf("expected 2 overlaps, got %v", overlaps)
+	}
+	// the Sunday window wraps around the end of the week into Monday
+	if overlaps[1].First.Day != "MONDAY" || overlaps[1].Second.Day != "SUNDAY" {
+		t.Fatalf("unexpected overlaps %v", overlaps)
+	}
+	if err := schedule.Validate(); err == nil || !strings.Contains(err.Error(), "overlaps with") {
+		t.Fatalf("expected an overlap error, got %v", err)
+	}
+	if err := (MaintenanceSchedule{MaintenanceWindows: schedule.MaintenanceWindows[2:3]}).Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestMaintenanceScheduleNextOccurrences(t *testing.T) {
+	amsterdam := time.FixedZone("CEST", 2*60*60)
+	schedule := MaintenanceSchedule{MaintenanceWindows: []MaintenanceWindow{
+		{Day: "MONDAY", StartTime: "02:00", Duration: 120},
+		{Day: "SUNDAY", StartTime: "23:00", Duration: 60},
+	}}
+	// Monday 01:30 in Amsterdam, during the Sunday window
+	from := time.Date(2026, time.October, 18, 23, 30, 0, 0, time.UTC)
+
+	occurrences, err := schedule.NextOccurrences(from, amsterdam, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{
+		"Mon 2026-10-19 02:00 - 04:00 CEST",
+		"Sun 2026-10-25 23:00 - 00:00 CEST",
+		"Mon 2026-10-26 02:00 - 04:00 CEST",
+	}
+	if len(occurrences) != len(want) {
+		t.Fatalf("expected %d occurrences, got %v", len(want), occurrences)
+	}
+	for i, occurrence := range occurrences {
+		if occurrence.String() != want[i] {
+			t.Errorf("occurrence %d = %s, want %s", i, occurrence, want[i])
+		}
+	}
+
+	tests := []struct {
+		at   time.Time
+		want bool
+	}{
+		{at: time.Date(2026, time.October, 19, 0, 30, 0, 0, time.UTC), want: true},
+		{at: time.Date(2026, time.October, 19, 1, 59, 0, 0, time.UTC), want: true},
+		{at: time.Date(2026, time.October, 19, 2, 0, 0, 0, time.UTC), want: false},
+		{at: time.Date(2026, time.October, 18, 20, 59, 0, 0, time.UTC), want: false},
+		{at: time.Date(2026, time.October, 18, 21, 0, 0, 0, time.UTC), want: true},
+	}
+	for _, tt := range tests {
+		contains, err := schedule.Contains(tt.at, amsterdam)
+		if err != nil || contains != tt.want {
+			t.Errorf("Contains(%s) = %t, %v, want %t", tt.at, contains, err, tt.want)
+		}
+	}
+}
+
+func TestMaintenanceWindowDaylightSavingTime(t *testing.T) {
+	amsterdam, err := time.LoadLocation("Europe/Amsterdam")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := []struct {
+		name   string
+		window MaintenanceWindow
+		from   time.Time
+		want   []string
+	}{
+		{
+			name:   "summer time starts",
+			window: MaintenanceWindow{Day: "SUNDAY", StartTime: "03:00", Duration: 60},
+			from:   time.Date(2026, time.March, 23, 0, 0, 0, 0, amsterdam),
+			want:   []string{"Sun 2026-03-29 03:00 - 04:00 CEST", "Sun 2026-04-05 03:00 - 04:00 CEST"},
+		},
+		{
+			name:   "summer time ends",
+			window: MaintenanceWindow{Day: "SUNDAY", StartTime: "03:00", Duration: 60},
+			from:   time.Date(2026, time.October, 19, 0, 0, 0, 0, amsterdam),
+			want:   []string{"Sun 2026-10-25 03:00 - 04:00 CET", "Sun 2026-11-01 03:00 - 04:00 CET"},
+		},
+		{
+			name:   "start time skipped by summer time",
+			window: MaintenanceWindow{Day: "SUNDAY", StartTime: "02:30", Duration: 60},
+			from:   time.Date(2026, time.March, 23, 0, 0, 0, 0, amsterdam),
+			want:   []string{"Sun 2026-03-29 03:30 - 04:30 CEST", "Sun 2026-04-05 02:30 - 03:30 CEST"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			occurrences, err := tt.window.NextOccurrences(tt.from, amsterdam, len(tt.want))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for i, occurrence := range occurrences {
+				if occurrence.String() != tt.want[i] {
+					t.Errorf("occurrence %d = %s, want %s", i, occurrence, tt.want[i])
+				}
+			}
+			contains, err := tt.window.Contains(occurrences[0].Start, amsterdam)
+			if err != nil || !contains {
+				t.Errorf("Contains(%s) = %t, %v, want true", occurrences[0].Start, contains, err)
+			}
+			if contains, _ := tt.window.Contains(occurrences[0].Start.Add(-time.Minute), amsterdam); contains {
+				t.Errorf("Contains(%s) = true, want false", occurrences[0].Start.Add(-time.Minute))
+			}
+		})
+	}
+}
+
+func TestWriteICalendar(t *testing.T) {
+	schedules := []MaintenanceSchedule{{
+		Identity: "schedule-1",
+		Name:     "Weekly, at night",
+		MaintenanceWindows: []MaintenanceWindow{
+			{Day: "MONDAY", StartTime: "02:00", Duration: 120},
+			{Day: "THURSDAY", StartTime: "22:00", Duration: 60},
+		},
+	}}
+	var b strings.Builder
+	err := WriteICalendar(&b, schedules, ICalendarOpts{
+		Name: "Maintenance",
+		Now:  time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Avisi Cloud//go-client//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:Maintenance",
+		"BEGIN:VEVENT",
+		"UID:schedule-1-0@avisi.cloud",
+		"DTSTAMP:20261018T120000Z",
+		"DTSTART:20261019T020000Z",
+		"DURATION:PT120M",
+		"RRULE:FREQ=WEEKLY;BYDAY=MO",
+		"SUMMARY:Maintenance window Weekly\\, at night",
+		"DESCRIPTION:MONDAY 02:00 120 minutes",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:schedule-1-1@avisi.cloud",
+		"DTSTAMP:20261018T120000Z",
+		"DTSTART:20261022T220000Z",
+		"DURATION:PT60M",
+		"RRULE:FREQ=WEEKLY;BYDAY=TH",
+		"SUMMARY:Maintenance window Weekly\\, at night",
+		"DESCRIPTION:THURSDAY 22:00 60 minutes",
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n")
+	if b.String() != want {
+		t.Fatalf("unexpected calendar:\n%s\nwant:\n%s", b.String(), want)
+	}
+
+	amsterdam, err := time.LoadLocation("Europe/Amsterdam")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		location *time.Location
+		want     []string
+	}{
+		{
+			name:     "daylight saving time",
+			location: amsterdam,
+			want: []string{
+				"X-WR-TIMEZONE:Europe/Amsterdam",
+				"BEGIN:VTIMEZONE",
+				"TZID:Europe/Amsterdam",
+				"BEGIN:DAYLIGHT",
+				"DTSTART:20260329T020000",
+				"RRULE:FREQ=YEARLY;BYMONTH=3;BYDAY=-1SU",
+				"TZOFFSETFROM:+0100",
+				"TZOFFSETTO:+0200",
+				"TZNAME:CEST",
+				"END:DAYLIGHT",
+				"BEGIN:STANDARD",
+				"DTSTART:20261025T030000",
+				"RRULE:FREQ=YEARLY;BYMONTH=10;BYDAY=-1SU",
+				"TZOFFSETFROM:+0200",
+				"TZOFFSETTO:+0100",
+				"TZNAME:CET",
+				"END:STANDARD",
+				"END:VTIMEZONE",
+				"BEGIN:VEVENT",
+				"UID:schedule-1-0@avisi.cloud",
+				"DTSTAMP:20261018T120000Z",
+				"DTSTART;TZID=Europe/Amsterdam:20261019T020000",
+			},
+		},
+		{
+			name:     "fixed offset",
+			location: time.FixedZone("UTC-03:30", -(3*60+30)*60),
+			want: []string{
+				"X-WR-TIMEZONE:UTC-03:30",
+				"BEGIN:VTIMEZONE",
+				"TZID:UTC-03:30",
+				"BEGIN:STANDARD",
+				"DTSTART:20260101T000000",
+				"TZOFFSETFROM:-0330",
+				"TZOFFSETTO:-0330",
+				"TZNAME:UTC-03:30",
+				"END:STANDARD",
+				"END:VTIMEZONE",
+				"BEGIN:VEVENT",
+				"UID:schedule-1-0@avisi.cloud",
+				"DTSTAMP:20261018T120000Z",
+				"DTSTART;TZID=UTC-03:30:20261019T020000",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			err := WriteICalendar(&b, schedules, ICalendarOpts{Location: tt.location, Now: time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)})
+			if err != nil || !strings.Contains(b.String(), "METHOD:PUBLISH\r\n"+strings.Join(tt.want, "\r\n")+"\r\n") {
+				t.Fatalf("expected a time zone, got %v:\n%s", err, b.String())
+			}
+		})
+	}
+
+	if folded := foldICalendarLine(strings.Repeat("é", 50)); !strings.Contains(folded, "\r\n ") || strings.Contains(folded, "\uFFFD") {
+		t.Fatalf("unexpected folded line %q", folded)
+	}
+}
@@ -0,0 +1,177 @@
+package acloudapi
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// UpdateNodePoolRequest is a partial update of a node pool, applied with PatchNodePool. Only the fields that are set
+// are changed: labels, annotations and taints are changed per key, other fields are changed when they are not nil.
+type UpdateNodePoolRequest struct {
+	MinSize               *int
+	MaxSize               *int
+	AutoScaling           *bool
+	NodeAutoReplacement   *bool
+	EnableNodeReboots     *bool
+	UpgradeStrategy       *NodePoolUpgradeStrategy
+	SecurityUpdatesOnJoin *NodePoolSecurityUpdatesOnJoin
+
+	// SetLabels adds or replaces labels
+	SetLabels map[string]string
+	// RemoveLabels removes labels by key
+	RemoveLabels []string
+	// SetAnnotations adds or replaces annotations
+	SetAnnotations map[string]string
+	// RemoveAnnotations removes annotations by key
+	RemoveAnnotations []string
+	// SetTaints adds taints, or replaces the taints with the same key and effect
+	SetTaints []NodeTaint
+	// RemoveTaints removes taints by key and effect, a taint without an effect removes all taints with its key
+	RemoveTaints []NodeTaint
+}
+
+// SetLabel adds or replaces a label
+func (r *UpdateNodePoolRequest) SetLabel(key, value string) {
+	if r.SetLabels == nil {
+		r.SetLabels = map[string]string{}
+	}
+	r.SetLabels[key] = value
+	r.RemoveLabels = slices.DeleteFunc(r.RemoveLabels, func(k string) bool { return k == key })
+}
+
+// RemoveLabel removes a label
+func (r *UpdateNodePoolRequest) RemoveLabel(key string) {
+	delete(r.SetLabels, key)
+	r.RemoveLabels = append(r.RemoveLabels, key)
+}
+
+// SetAnnotation adds or replaces an annotation
+func (r *UpdateNodePoolRequest) SetAnnotation(key, value string) {
+	if r.SetAnnotations == nil {
+		r.SetAnnotations = map[string]string{}
+	}
+	r.SetAnnotations[key] = value
+	r.RemoveAnnotations = slices.DeleteFunc(r.RemoveAnnotations, func(k string) bool { return k == key })
+}
+
+// RemoveAnnotation removes an annotation
+func (r *UpdateNodePoolRequest) RemoveAnnotation(key string) {
+	delete(r.SetAnnotations, key)
+	r.RemoveAnnotations = append(r.RemoveAnnotations, key)
+}
+
+// SetTaint adds a taint, or replaces the taint with the same key and effect
+func (r *UpdateNodePoolRequest) SetTaint(taint NodeTaint) {
+	r.SetTaints = append(slices.DeleteFunc(r.SetTaints, taint.sameTaint), taint)
+}
+
+// RemoveTaint removes the taint with the key and effect, or all taints with the key when effect is empty
+func (r *UpdateNodePoolRequest) RemoveTaint(key, effect string) {
+	taint := NodeTaint{Key: key, Effect: effect}
+	r.SetTaints = slices.DeleteFunc(r.SetTaints, taint.matches)
+	r.RemoveTaints = append(r.RemoveTaints, taint)
+}
+
+// sameTaint returns true if both taints have the same key and effect
+func (t NodeTaint) sameTaint(other NodeTaint) bool {
+	return t.Key == other.Key && t.Effect == other.Effect
+}
+
+// matches returns true if other has the key of the taint, and its effect unless the effect of the taint is empty
+func (t NodeTaint) matches(other NodeTaint) bool {
+	return t.Key == other.Key && (t.Effect == "" || t.Effect == other.Effect)
+}
+
+// Apply returns the request to update the node pool with the changes of the request. The node pool is not modified.
+func (r UpdateNodePoolRequest) Apply(nodePool NodePool) CreateNodePool {
+	update := nodePool.ToCreateNodePool()
+	setIfNotNil(&update.MinSize, r.MinSize)
+	setIfNotNil(&update.MaxSize, r.MaxSize)
+	setIfNotNil(&update.AutoScaling, r.AutoScaling)
+	setIfNotNil(&update.NodeAutoReplacement, r.NodeAutoReplacement)
+	setIfNotNil(&update.EnableNodeReboots, r.EnableNodeReboots)
+	setIfNotNil(&update.UpgradeStrategy, r.UpgradeStrategy)
+	setIfNotNil(&update.SecurityUpdatesOnJoin, r.SecurityUpdatesOnJoin)
+
+	update.Labels = mergeStringMap(nodePool.Labels, r.SetLabels, r.RemoveLabels)
+	update.Annotations = mergeStringMap(nodePool.Annotations, r.SetAnnotations, r.RemoveAnnotations)
+	update.Taints = slices.DeleteFunc(slices.Clone(nodePool.Taints), func(taint NodeTaint) bool {
+		return slices.ContainsFunc(r.RemoveTaints, func(removed NodeTaint) bool { return removed.matches(taint) }) ||
+			slices.ContainsFunc(r.SetTaints, taint.sameTaint)
+	})
+	update.Taints = append(update.Taints, r.SetTaints...)
+	return update
+}
+
+func setIfNotNil[T any](field *T, value *T) {
+	if value != nil {
+		*field = *value
+	}
+}
+
+// mergeStringMap returns a copy of m without the removed keys and with the set keys
+func mergeStringMap(m map[string]string, set map[string]string, remove []string) map[string]string {
+	merged := maps.Clone(m)
+	if merged == nil && len(set) > 0 {
+		merged = map[string]string{}
+	}
+	for _, key := range remove {
+		delete(merged, key)
+	}
+	maps.Copy(merged, set)
+	return merged
+}
+
+// ToCreateNodePool returns the request to create or update a node pool with the settings of the node pool
+func (n NodePool) ToCreateNodePool() CreateNodePool {
+	return CreateNodePool{
+		Name:                  n.Name,
+		AvailabilityZone:      n.AvailabilityZone,
+		NodeSize:              n.NodeSize,
+		MinSize:               n.MinSize,
+		MaxSize:               n.MaxSize,
+		AutoScaling:           n.AutoScaling,
+		NodeAutoReplacement:   n.NodeAutoReplacement,
+		EnableNodeReboots:     n.EnableNodeReboots,
+		UpgradeStrategy:       n.UpgradeStrategy,
+		SecurityUpdatesOnJoin: n.SecurityUpdatesOnJoin,
+		Annotations:           maps.Clone(n.Annotations),
+		Labels:                maps.Clone(n.Labels),
+		Taints:                slices.Clone(n.Taints),
+	}
+}
+
+// PatchNodePool applies the partial update to the current state of the node pool. As the API replaces the whole node
+// pool on update, the node pool is read first and all fields that are not part of the request are kept. Labels with a
+// reserved prefix are only rejected when the request adds or changes them.
+func PatchNodePool(ctx context.Context, client NodePoolsAPI, cluster Cluster, nodePoolID int, update UpdateNodePoolRequest) (*NodePool, error) {
+	nodePools, err := client.GetNodePoolsByCluster(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	index := slices.IndexFunc(nodePools, func(nodePool NodePool) bool { return nodePool.ID == nodePoolID })
+	if index < 0 {
+		return nil, fmt.Errorf("node pool %d of cluster %s: %w", nodePoolID, cluster.Identifier(), ErrNotFound)
+	}
+	if err := update.validateLabels(nodePools[index]); err != nil {
+		return nil, err
+	}
+	return client.UpdateNodePool(ctx, cluster, nodePoolID, update.Apply(nodePools[index]))
+}
+
+// validateLabels rejects labels with a reserved prefix that the request adds to the node pool or changes. Labels the
+// node pool already has are kept as they are.
+func (r UpdateNodePoolRequest) validateLabels(nodePool NodePool) error {
+	validationError := &ValidationError{}
+	for _, key := range slices.Sorted(maps.Keys(r.SetLabels)) {
+		if value, ok := nodePool.Labels[key]; ok && value == r.SetLabels[key] {
+			continue
+		}
+		if reservedLabelKey(key) {
+			validationError.add("labels."+key, "%q uses a reserved prefix", key)
+		}
+	}
+	return validationError.errorOrNil()
+}
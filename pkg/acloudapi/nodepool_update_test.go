@@ -0,0 +1,170 @@
+package acloudapi_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"testing"
+
+	"github.com/avisi-cloud/go-client/pkg/acloudapi"
+	"github.com/avisi-cloud/go-client/pkg/acloudapi/acloudapitest"
+)
+
+func TestUpdateNodePoolRequestApply(t *testing.T) {
+	nodePool := acloudapi.NodePool{
+		Name:            "workers",
+		NodeSize:        "t3.large",
+		MinSize:         3,
+		MaxSize:         3,
+		UpgradeStrategy: acloudapi.NodePoolUpgradeStrategyReplace,
+		Labels:          map[string]string{"role": "worker", "team": "web"},
+		Annotations:     map[string]string{"owner": "ops"},
+		Taints: []acloudapi.NodeTaint{
+			{Key: "dedicated", Value: "web", Effect: "NoSchedule"},
+			{Key: "dedicated", Value: "web", Effect: "NoExecute"},
+			{Key: "gpu", Value: "true", Effect: "NoSchedule"},
+		},
+	}
+	maxSize := 6
+	tests := []struct {
+		name            string
+		update          func(r *acloudapi.UpdateNodePoolRequest)
+		wantSize        string
+		wantLabels      map[string]string
+		wantAnnotations map[string]string
+		wantTaints      string
+	}{
+		{
+			name:            "empty",
+			update:          func(r *acloudapi.UpdateNodePoolRequest) {},
+			wantSize:        "false 3 3",
+			wantLabels:      map[string]string{"role": "worker", "team": "web"},
+			wantAnnotations: map[string]string{"owner": "ops"},
+			wantTaints:      "[dedicated=web:NoSchedule dedicated=web:NoExecute gpu=true:NoSchedule]",
+		},
+		{
+			name: "labels and annotations",
+			update: func(r *acloudapi.UpdateNodePoolRequest) {
+				r.SetLabel("team", "payments")
+				r.RemoveLabel("role")
+				r.SetAnnotation("cost-center", "42")
+				r.RemoveAnnotation("unknown")
+			},
+			wantSize:        "false 3 3",
+			wantLabels:      map[string]string{"team": "payments"},
+			wantAnnotations: map[string]string{"owner": "ops", "cost-center": "42"},
+			wantTaints:      "[dedicated=web:NoSchedule dedicated=web:NoExecute gpu=true:NoSchedule]",
+		},
+		{
+			name: "set after remove",
+			update: func(r *acloudapi.UpdateNodePoolRequest) {
+				r.RemoveLabel("role")
+				r.SetLabel("role", "batch")
+			},
+			wantSize:        "false 3 3",
+			wantLabels:      map[string]string{"role": "batch", "team": "web"},
+			wantAnnotations: map[string]string{"owner": "ops"},
+			wantTaints:      "[dedicated=web:NoSchedule dedicated=web:NoExecute gpu=true:NoSchedule]",
+		},
+		{
+			name: "taints",
+			update: func(r *acloudapi.UpdateNodePoolRequest) {
+				r.RemoveTaint("dedicated", "")
+				r.SetTaint(acloudapi.NodeTaint{Key: "gpu", Value: "false", Effect: "NoSchedule"})
+				r.SetTaint(acloudapi.NodeTaint{Key: "spot", Value: "true", Effect: "PreferNoSchedule"})
+			},
+			wantSize:        "false 3 3",
+			wantLabels:      map[string]string{"role": "worker", "team": "web"},
+			wantAnnotations: map[string]string{"owner": "ops"},
+			wantTaints:      "[gpu=false:NoSchedule spot=true:PreferNoSchedule]",
+		},
+		{
+			name: "taint with effect",
+			update: func(r *acloudapi.UpdateNodePoolRequest) {
+				r.RemoveTaint("dedicated", "NoExecute")
+			},
+			wantSize:        "false 3 3",
+			wantLabels:      map[string]string{"role": "worker", "team": "web"},
+			wantAnnotations: map[string]string{"owner": "ops"},
+			wantTaints:      "[dedicated=web:NoSchedule gpu=true:NoSchedule]",
+		},
+		{
+			name: "size",
+			update: func(r *acloudapi.UpdateNodePoolRequest) {
+				r.AutoScaling = acloudapi.BoolPointer(true)
+				r.MaxSize = &maxSize
+			},
+			wantSize:        "true 3 6",
+			wantLabels:      map[string]string{"role": "worker", "team": "web"},
+			wantAnnotations: map[string]string{"owner": "ops"},
+			wantTaints:      "[dedicated=web:NoSchedule dedicated=web:NoExecute gpu=true:NoSchedule]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := acloudapi.UpdateNodePoolRequest{}
+			tt.update(&request)
+			got := request.Apply(nodePool)
+			if size := fmt.Sprintf("%t %d %d", got.AutoScaling, got.MinSize, got.MaxSize); size != tt.wantSize {
+				t.Errorf("unexpected size %s, want %s", size, tt.wantSize)
+			}
+			if !maps.Equal(got.Labels, tt.wantLabels) {
+				t.Errorf("unexpected labels %v, want %v", got.Labels, tt.wantLabels)
+			}
+			if !maps.Equal(got.Annotations, tt.wantAnnotations) {
+				t.Errorf("unexpected annotations %v, want %v", got.Annotations, tt.wantAnnotations)
+			}
+			if taints := taintsString(got.Taints); taints != tt.wantTaints {
+				t.Errorf("unexpected taints %s, want %s", taints, tt.wantTaints)
+			}
+			if got.Name != nodePool.Name || got.NodeSize != nodePool.NodeSize || got.UpgradeStrategy != nodePool.UpgradeStrategy {
+				t.Errorf("settings of the node pool were not kept: %+v", got)
+			}
+		})
+	}
+	if len(nodePool.Labels) != 2 || len(nodePool.Annotations) != 1 || len(nodePool.Taints) != 3 {
+		t.Fatalf("node pool was modified: %+v", nodePool)
+	}
+}
+
+func TestPatchNodePool(t *testing.T) {
+	server := acloudapitest.NewServer(acloudapitest.Opts{})
+	defer server.Close()
+	server.AddOrganisation(acloudapi.Organisation{Slug: "org1"})
+	server.AddEnvironment("org1", acloudapi.Environment{Slug: "env1"})
+	cluster := server.AddCluster(acloudapi.Cluster{CustomerSlug: "org1", EnvironmentSlug: "env1", Slug: "web"})
+	created := server.AddNodePool(cluster.Identity, acloudapi.NodePool{
+		Name:                "workers",
+		NodeSize:            "t3.large",
+		MinSize:             3,
+		MaxSize:             3,
+		NodeAutoReplacement: true,
+		Labels:              map[string]string{"role": "worker"},
+		Taints:              []acloudapi.NodeTaint{{Key: "dedicated", Value: "web", Effect: "NoSchedule"}},
+	})
+	ctx := context.Background()
+
+	update := acloudapi.UpdateNodePoolRequest{}
+	update.SetLabel("team", "payments")
+	nodePool, err := acloudapi.PatchNodePool(ctx, server.Client(), cluster, created.ID, update)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !maps.Equal(nodePool.Labels, map[string]string{"role": "worker", "team": "payments"}) || nodePool.MinSize != 3 || !nodePool.NodeAutoReplacement ||
+		taintsString(nodePool.Taints) != "[dedicated=web:NoSchedule]" {
+		t.Fatalf("unexpected node pool %+v", nodePool)
+	}
+
+	if _, err := acloudapi.PatchNodePool(ctx, server.Client(), cluster, created.ID+1, update); !errors.Is(err, acloudapi.ErrNotFound) {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+}
+
+func taintsString(taints []acloudapi.NodeTaint) string {
+	s := make([]string, len(taints))
+	for i, taint := range taints {
+		s[i] = fmt.Sprintf("%s=%s:%s", taint.Key, taint.Value, taint.Effect)
+	}
+	return fmt.Sprint(s)
+}
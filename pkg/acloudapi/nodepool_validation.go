@@ -0,0 +1,164 @@
+package acloudapi
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Effects of a NodeTaint
+const (
+	TaintEffectNoSchedule       = "NoSchedule"
+	TaintEffectPreferNoSchedule = "PreferNoSchedule"
+	TaintEffectNoExecute        = "NoExecute"
+)
+
+// AllTaintEffects are the effects of a NodeTaint supported by Kubernetes
+var AllTaintEffects = []string{
+	TaintEffectNoSchedule,
+	TaintEffectPreferNoSchedule,
+	TaintEffectNoExecute,
+}
+
+const (
+	qualifiedNameMaxLength  = 63
+	labelValueMaxLength     = 63
+	dnsSubdomainMaxLength   = 253
+	annotationsMaxTotalSize = 256 * 1024
+)
+
+var (
+	qualifiedNamePattern = regexp.MustCompile(`^([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]$`)
+	dnsSubdomainPattern  = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+)
+
+// reservedNamespaces are the prefixes, and their subdomains, of node labels reserved for Kubernetes components
+var reservedNamespaces = []string{"kubernetes.io", "k8s.io"}
+
+// allowedLabelNamespaces are the reserved prefixes, and their subdomains, that the kubelet accepts for node labels
+var allowedLabelNamespaces = []string{"kubelet.kubernetes.io", "node.kubernetes.io"}
+
+// ValidateQualifiedName checks a label, annotation or taint key: an optional DNS subdomain prefix of at most 253
+// characters followed by a slash, and a name of at most 63 alphanumeric characters, '-', '_' or '.', starting and
+// ending with an alphanumeric character, e.g. "example.com/team"
+func ValidateQualifiedName(key string) error {
+	name := key
+	if prefix, rest, found := strings.Cut(key, "/"); found {
+		name = rest
+		switch {
+		case prefix == "":
+			return fmt.Errorf("prefix of %q must not be empty", key)
+		case len(prefix) > dnsSubdomainMaxLength:
+			return fmt.Errorf("prefix of %q must be at most %d characters", key, dnsSubdomainMaxLength)
+		case !dnsSubdomainPattern.MatchString(prefix):
+			return fmt.Errorf("prefix of %q must be a lowercase DNS subdomain", key)
+		case strings.Contains(rest, "/"):
+			return fmt.Errorf("%q must contain at most one '/'", key)
+		}
+	}
+	switch {
+	case name == "":
+		return fmt.Errorf("name of %q must not be empty", key)
+	case len(name) > qualifiedNameMaxLength:
+		return fmt.Errorf("name of %q must be at most %d characters", key, qualifiedNameMaxLength)
+	case !qualifiedNamePattern.MatchString(name):
+		return fmt.Errorf("name of %q must consist of alphanumeric characters, '-', '_' or '.', and start and end with an alphanumeric character", key)
+	}
+	return nil
+}
+
+// ValidateLabelValue checks a label or taint value: empty, or at most 63 alphanumeric characters, '-', '_' or '.',
+// starting and ending with an alphanumeric character
+func ValidateLabelValue(value string) error {
+	switch {
+	case value == "":
+		return nil
+	case len(value) > labelValueMaxLength:
+		return fmt.Errorf("value %q must be at most %d characters", value, labelValueMaxLength)
+	case !qualifiedNamePattern.MatchString(value):
+		return fmt.Errorf("value %q must consist of alphanumeric characters, '-', '_' or '.', and start and end with an alphanumeric character", value)
+	}
+	return nil
+}
+
+// Validate checks the key, value and effect of the taint
+func (t NodeTaint) Validate() error {
+	if err := ValidateQualifiedName(t.Key); err != nil {
+		return fmt.Errorf("key: %w", err)
+	}
+	if err := ValidateLabelValue(t.Value); err != nil {
+		return fmt.Errorf("value: %w", err)
+	}
+	if !slices.Contains(AllTaintEffects, t.Effect) {
+		return fmt.Errorf("effect: must be one of %s, got %q", strings.Join(AllTaintEffects, ", "), t.Effect)
+	}
+	return nil
+}
+
+// Validate checks the labels, annotations and taints of the request following the Kubernetes rules, without contacting
+// the API. Like the NodeRestriction admission plugin, labels in the kubernetes.io and k8s.io namespaces are rejected,
+// except for the kubelet.kubernetes.io and node.kubernetes.io namespaces. All problems are returned at once as a
+// *ValidationError.
+func (c CreateNodePool) Validate() error {
+	validationError := &ValidationError{}
+	c.validate(validationError, true)
+	return validationError.errorOrNil()
+}
+
+// validateUpdate checks the request like Validate, except for the reserved label prefixes. An update contains all labels
+// of the node pool, including labels that were set before and cannot be told apart from new ones.
+func (c CreateNodePool) validateUpdate() error {
+	validationError := &ValidationError{}
+	c.validate(validationError, false)
+	return validationError.errorOrNil()
+}
+
+func (c CreateNodePool) validate(validationError *ValidationError, rejectReservedLabels bool) {
+	for _, key := range slices.Sorted(maps.Keys(c.Labels)) {
+		field := "labels." + key
+		if err := ValidateQualifiedName(key); err != nil {
+			validationError.add(field, "%s", err)
+		} else if rejectReservedLabels && reservedLabelKey(key) {
+			validationError.add(field, "%q uses a reserved prefix", key)
+		}
+		if err := ValidateLabelValue(c.Labels[key]); err != nil {
+			validationError.add(field, "%s", err)
+		}
+	}
+
+	totalSize := 0
+	for _, key := range slices.Sorted(maps.Keys(c.Annotations)) {
+		field := "annotations." + key
+		if err := ValidateQualifiedName(key); err != nil {
+			validationError.add(field, "%s", err)
+		}
+		totalSize += len(key) + len(c.Annotations[key])
+	}
+	if totalSize > annotationsMaxTotalSize {
+		validationError.add("annotations", "total size must be at most %d bytes, got %d", annotationsMaxTotalSize, totalSize)
+	}
+
+	for i, taint := range c.Taints {
+		field := fmt.Sprintf("taints[%d]", i)
+		if err := taint.Validate(); err != nil {
+			validationError.add(field, "%s", err)
+		} else if slices.ContainsFunc(c.Taints[:i], taint.sameTaint) {
+			validationError.add(field, "duplicate taint %s:%s", taint.Key, taint.Effect)
+		}
+	}
+}
+
+// reservedLabelKey returns true if the prefix of the label key is, or is a subdomain of, a namespace reserved for
+// Kubernetes, unless it is, or is a subdomain of, one of the allowed label namespaces
+func reservedLabelKey(key string) bool {
+	prefix, _, found := strings.Cut(key, "/")
+	if !found {
+		return false
+	}
+	inNamespace := func(namespace string) bool {
+		return prefix == namespace || strings.HasSuffix(prefix, "."+namespace)
+	}
+	return slices.ContainsFunc(reservedNamespaces, inNamespace) && !slices.ContainsFunc(allowedLabelNamespaces, inNamespace)
+}
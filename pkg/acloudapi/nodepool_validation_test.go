@@ -0,0 +1,216 @@
+package acloudapi_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/avisi-cloud/go-client/pkg/acloudapi"
+	"github.com/avisi-cloud/go-client/pkg/acloudapi/acloudapitest"
+)
+
+func TestValidateQualifiedName(t *testing.T) {
+	tests := []struct {
+		key     string
+		wantErr string
+	}{
+		{key: "team"},
+		{key: "example.com/team"},
+		{key: "app.kubernetes.io/Part_of.v1"},
+		{key: "", wantErr: `name of "" must not be empty`},
+		{key: "example.com/", wantErr: `name of "example.com/" must not be empty`},
+		{key: "/team", wantErr: `prefix of "/team" must not be empty`},
+		{key: "Example.com/team", wantErr: "must be a lowercase DNS subdomain"},
+		{key: "example.com/a/b", wantErr: "must contain at most one '/'"},
+		{key: "-team", wantErr: "must consist of alphanumeric characters"},
+		{key: "team.", wantErr: "must consist of alphanumeric characters"},
+		{key: "team name", wantErr: "must consist of alphanumeric characters"},
+		{key: strings.Repeat("a", 64), wantErr: "must be at most 63 characters"},
+		{key: strings.Repeat("a.", 127) + "a/team", wantErr: "must be at most 253 characters"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			err := acloudapi.ValidateQualifiedName(tt.key)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("expected error %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateLabelValue(t *testing.T) {
+	for _, value := range []string{"", "worker", "v1.2_3-a", strings.Repeat("a", 63)} {
+		if err := acloudapi.ValidateLabelValue(value); err != nil {
+			t.Errorf("unexpected error for %q: %v", value, err)
+		}
+	}
+	for _, value := range []string{"-worker", "worker_", "a/b", "two words", strings.Repeat("a", 64)} {
+		if err := acloudapi.ValidateLabelValue(value); err == nil {
+			t.Errorf("expected an error for %q", value)
+		}
+	}
+}
+
+func TestCreateNodePoolValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		create acloudapi.CreateNodePool
+		want   []string
+	}{
+		{
+			name: "valid",
+			create: acloudapi.CreateNodePool{
+				Labels:      map[string]string{"team": "web", "example.com/tier": "", "node.kubernetes.io/role": "worker"},
+				Annotations: map[string]string{"example.com/owner": "Operations team <ops@example.com>"},
+				Taints: []acloudapi.NodeTaint{
+					{Key: "dedicated", Value: "web", Effect: acloudapi.TaintEffectNoSchedule},
+					{Key: "dedicated", Value: "web", Effect: acloudapi.TaintEffectNoExecute},
+					{Key: "example.com/spot", Effect: acloudapi.TaintEffectPreferNoSchedule},
+				},
+			},
+		},
+		{
+			name: "labels",
+			create: acloudapi.CreateNodePool{Labels: map[string]string{
+				"team":                            "web team",
+				"-team":                           "web",
+				"kubernetes.io/role":              "worker",
+				"node-role.kubernetes.io/worker":  "",
+				"kubelet.kubernetes.io/pool-name": "workers",
+			}},
+			want: []string{
+				`labels.-team: name of "-team" must consist of alphanumeric characters, '-', '_' or '.', and start and end with an alphanumeric character`,
+				`labels.kubernetes.io/role: "kubernetes.io/role" uses a reserved prefix`,
+				`labels.node-role.kubernetes.io/worker: "node-role.kubernetes.io/worker" uses a reserved prefix`,
+				`labels.team: value "web team" must consist of alphanumeric characters, '-', '_' or '.', and start and end with an alphanumeric character`,
+			},
+		},
+		{
+			name: "annotations",
+			create: acloudapi.CreateNodePool{Annotations: map[string]string{
+				"cluster-autoscaler.kubernetes.io/scale-down-disabled": "true",
+				"example.com/":   "ops",
+				"example.com/ok": strings.Repeat("a", 256*1024),
+			}},
+			want: []string{
+				`annotations.example.com/: name of "example.com/" must not be empty`,
+				"annotations: total size must be at most 262144 bytes, got 262229",
+			},
+		},
+		{
+			name: "taints",
+			create: acloudapi.CreateNodePool{Taints: []acloudapi.NodeTaint{
+				{Key: "dedicated", Value: "web", Effect: "NoSchedule"},
+				{Key: "dedicated", Value: "api", Effect: "NoSchedule"},
+				{Key: "gpu", Value: "true", Effect: "noschedule"},
+				{Key: "gpu", Value: "true"},
+				{Key: "node-role.kubernetes.io/infra", Effect: "NoSchedule"},
+				{Key: "spot", Value: "yes please", Effect: "NoExecute"},
+			}},
+			want: []string{
+				"taints[1]: duplicate taint dedicated:NoSchedule",
+				`taints[2]: effect: must be one of NoSchedule, PreferNoSchedule, NoExecute, got "noschedule"`,
+				`taints[3]: effect: must be one of NoSchedule, PreferNoSchedule, NoExecute, got ""`,
+				`taints[5]: value: value "yes please" must consist of alphanumeric characters, '-', '_' or '.', and start and end with an alphanumeric character`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertValidationError(t, tt.create.Validate(), tt.want)
+		})
+	}
+}
+
+func TestPatchNodePoolWithKubernetesTaintsAndAnnotations(t *testing.T) {
+	server := acloudapitest.NewServer(acloudapitest.Opts{})
+	defer server.Close()
+	server.AddOrganisation(acloudapi.Organisation{Slug: "org1"})
+	server.AddEnvironment("org1", acloudapi.Environment{Slug: "env1"})
+	cluster := server.AddCluster(acloudapi.Cluster{CustomerSlug: "org1", EnvironmentSlug: "env1", Slug: "web"})
+	nodePool := server.AddNodePool(cluster.Identity, acloudapi.NodePool{
+		Name:        "infra",
+		NodeSize:    "t3.large",
+		MinSize:     1,
+		MaxSize:     1,
+		Annotations: map[string]string{"cluster-autoscaler.kubernetes.io/scale-down-disabled": "true"},
+		Taints:      []acloudapi.NodeTaint{{Key: "node-role.kubernetes.io/infra", Effect: acloudapi.TaintEffectNoSchedule}},
+	})
+
+	update := acloudapi.UpdateNodePoolRequest{}
+	update.SetLabel("team", "platform")
+	patched, err := acloudapi.PatchNodePool(context.Background(), server.Client(), cluster, nodePool.ID, update)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if patched.Labels["team"] != "platform" || len(patched.Taints) != 1 || len(patched.Annotations) != 1 {
+		t.Fatalf("unexpected node pool %+v", patched)
+	}
+}
+
+func TestNodePoolValidationBeforeRequest(t *testing.T) {
+	server := acloudapitest.NewServer(acloudapitest.Opts{})
+	defer server.Close()
+	server.AddOrganisation(acloudapi.Organisation{Slug: "org1"})
+	server.AddEnvironment("org1", acloudapi.Environment{Slug: "env1"})
+	cluster := server.AddCluster(acloudapi.Cluster{CustomerSlug: "org1", EnvironmentSlug: "env1", Slug: "web"})
+	nodePool := server.AddNodePool(cluster.Identity, acloudapi.NodePool{Name: "workers", NodeSize: "t3.large", MinSize: 1, MaxSize: 1})
+	ctx := context.Background()
+	reserved := acloudapi.CreateNodePool{Name: "batch", NodeSize: "t3.large", MinSize: 1, MaxSize: 1, Labels: map[string]string{"kubernetes.io/role": "batch"}}
+	invalid := acloudapi.CreateNodePool{Name: "batch", NodeSize: "t3.large", MinSize: 1, MaxSize: 1, Labels: map[string]string{"team/": "batch"}}
+
+	var validationError *acloudapi.ValidationError
+	if _, err := server.Client().CreateNodePool(ctx, cluster, reserved); !errors.As(err, &validationError) {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	if _, err := server.Client().UpdateNodePool(ctx, cluster, nodePool.ID, invalid); !errors.As(err, &validationError) {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	update := acloudapi.UpdateNodePoolRequest{}
+	update.SetTaint(acloudapi.NodeTaint{Key: "dedicated", Value: "batch", Effect: "Never"})
+	if _, err := acloudapi.PatchNodePool(ctx, server.Client(), cluster, nodePool.ID, update); !errors.As(err, &validationError) {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	update = acloudapi.UpdateNodePoolRequest{}
+	update.SetLabel("kubernetes.io/role", "batch")
+	if _, err := acloudapi.PatchNodePool(ctx, server.Client(), cluster, nodePool.ID, update); !errors.As(err, &validationError) {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	for _, request := range server.Requests() {
+		if request.Method != "GET" {
+			t.Fatalf("unexpected request %s %s for an invalid node pool", request.Method, request.Path)
+		}
+	}
+}
+
+func TestPatchNodePoolWithExistingReservedLabel(t *testing.T) {
+	server := acloudapitest.NewServer(acloudapitest.Opts{})
+	defer server.Close()
+	server.AddOrganisation(acloudapi.Organisation{Slug: "org1"})
+	server.AddEnvironment("org1", acloudapi.Environment{Slug: "env1"})
+	cluster := server.AddCluster(acloudapi.Cluster{CustomerSlug: "org1", EnvironmentSlug: "env1", Slug: "web"})
+	nodePool := server.AddNodePool(cluster.Identity, acloudapi.NodePool{
+		Name:     "workers",
+		NodeSize: "t3.large",
+		MinSize:  1,
+		MaxSize:  1,
+		Labels:   map[string]string{"node-role.kubernetes.io/worker": ""},
+	})
+
+	update := acloudapi.UpdateNodePoolRequest{}
+	maxSize := 3
+	update.MaxSize = &maxSize
+	update.SetLabel("node-role.kubernetes.io/worker", "")
+	update.SetLabel("team", "platform")
+	patched, err := acloudapi.PatchNodePool(context.Background(), server.Client(), cluster, nodePool.ID, update)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if patched.MaxSize != 3 || len(patched.Labels) != 2 {
+		t.Fatalf("unexpected node pool %+v", patched)
+	}
+}
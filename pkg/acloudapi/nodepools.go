@@ -5,9 +5,28 @@ import (
 	"fmt"
 	"sort"
 	"strings"
-	"sync"
 )
 
+// GetNodePoolsOpts are the options of GetNodePoolsByClusters
+type GetNodePoolsOpts struct {
+	// AllowPartialResults returns the node pools of all clusters that succeeded together with a *PartialResultError,
+	// instead of failing on the first error
+	AllowPartialResults *bool
+}
+
+func mergeGetNodePoolsOpts(opts []GetNodePoolsOpts) GetNodePoolsOpts {
+	merged := GetNodePoolsOpts{}
+	for _, opt := range opts {
+		if opt.AllowPartialResults != nil {
+			merged.AllowPartialResults = opt.AllowPartialResults
+		}
+	}
+	if merged.AllowPartialResults == nil {
+		merged.AllowPartialResults = False()
+	}
+	return merged
+}
+
 func (c *clientImpl) GetNodePools(ctx context.Context) ([]NodePool, error) {
 	clusters, err := c.GetClusters(ctx)
 	if err != nil {
@@ -32,37 +51,50 @@ type nodePoolResponse struct {
 }
 
 func (c *clientImpl) GetNodePoolsByClusters(ctx context.Context, clusters []Cluster) ([]NodePool, error) {
-	numberOfRequests := len(clusters)
-	responseChan := make(chan nodePoolResponse, numberOfRequests)
-	wg := sync.WaitGroup{}
-	for _, cluster := range clusters {
-		wg.Add(1)
-		go func(cls Cluster) {
-			nodePoolsByCluster, err := c.GetNodePoolsByCluster(ctx, cls)
-			resp := nodePoolResponse{
-				NodePools: nodePoolsByCluster,
-			}
-			if err != nil {
-				resp.err = fmt.Errorf("failed to get node-pools by cluster %s: %w", cls.FullIdentifier(), err)
-			}
-			responseChan <- resp
-			wg.Done()
-		}(cluster)
+	return GetNodePoolsByClusters(ctx, c, clusters)
+}
+
+// GetNodePoolsByClusters returns the node pools of all given clusters, getting the node pools of each cluster with
+// client.GetNodePoolsByCluster.
+//
+// By default, an error for any of the clusters fails the whole call, like NodePoolsAPI.GetNodePoolsByClusters. When
+// AllowPartialResults is set, the node pools of all clusters that succeeded are returned together with a
+// *PartialResultError listing the failed clusters. Together with GetClusterOpts.AllowPartialResults, this returns the
+// node pools of all organisations that succeeded:
+//
+//	clusters, clustersErr := client.GetClusters(ctx, acloudapi.GetClusterOpts{AllowPartialResults: acloudapi.True()})
+//	nodePools, err := acloudapi.GetNodePoolsByClusters(ctx, client, clusters, acloudapi.GetNodePoolsOpts{AllowPartialResults: acloudapi.True()})
+func GetNodePoolsByClusters(ctx context.Context, client NodePoolsAPI, clusters []Cluster, opts ...GetNodePoolsOpts) ([]NodePool, error) {
+	concurrency := DefaultFanOutConcurrency
+	if fanOutClient, ok := client.(interface{ fanOutConcurrency() int }); ok {
+		concurrency = fanOutClient.fanOutConcurrency()
 	}
+	responses := make([]nodePoolResponse, len(clusters))
+	runConcurrently(len(clusters), concurrency, func(i int) {
+		nodePoolsByCluster, err := client.GetNodePoolsByCluster(ctx, clusters[i])
+		responses[i] = nodePoolResponse{
+			NodePools: nodePoolsByCluster,
+			err:       err,
+		}
+	})
 
-	wg.Wait()
-	close(responseChan)
+	allowPartialResults := mergeGetNodePoolsOpts(opts).AllowPartialResults
+	partialResultError := &PartialResultError{}
 	nodePools := make([]NodePool, 0)
-	for i := 0; i < numberOfRequests; i++ {
-		response := <-responseChan
+	for i, response := range responses {
 		if response.err != nil {
-			return nil, response.err // returning first error
+			cls := clusters[i]
+			if !*allowPartialResults {
+				return nil, fmt.Errorf("failed to get node-pools by cluster %s: %w", cls.FullIdentifier(), response.err) // returning first error
+			}
+			partialResultError.add(response.err, PartialFailure{OrganisationSlug: cls.CustomerSlug, Cluster: cls.FullIdentifier()})
+			continue
 		}
 
 		nodePools = append(nodePools, response.NodePools...)
 	}
 	SortNodePools(nodePools)
-	return nodePools, nil
+	return nodePools, partialResultError.errorOrNil()
 }
 
 func (c *clientImpl) GetNodePoolsByCluster(ctx context.Context, cluster Cluster) ([]NodePool, error) {
@@ -88,6 +120,9 @@ func (c *clientImpl) mapNodePool(nodePool *NodePool, cluster Cluster) {
 }
 
 func (c *clientImpl) CreateNodePool(ctx context.Context, cluster Cluster, create CreateNodePool) (*NodePool, error) {
+	if err := create.Validate(); err != nil {
+		return nil, err
+	}
 	nodePool := NodePool{}
 	response, err := c.R().
 		SetContext(ctx).
@@ -102,6 +137,9 @@ func (c *clientImpl) CreateNodePool(ctx context.Context, cluster Cluster, create
 }
 
 func (c *clientImpl) UpdateNodePool(ctx context.Context, cluster Cluster, nodePoolID int, update CreateNodePool) (*NodePool, error) {
+	if err := update.validateUpdate(); err != nil {
+		return nil, err
+	}
 	nodePool := NodePool{}
 	response, err := c.R().
 		SetContext(ctx).
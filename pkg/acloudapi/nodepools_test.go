@@ -3,6 +3,7 @@ package acloudapi
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -128,3 +129,36 @@ func TestParseNodePoolSecurityUpdatesOnJoin(t *testing.T) {
 		}
 	})
 }
+
+func TestGetNodePoolsByClustersAllowPartialResults(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/orgs/org1/clusters/env1/cluster1/pools", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[{"id":1,"name":"pool1"}]`)
+	})
+	mux.HandleFunc("/api/v1/orgs/org1/clusters/env1/cluster2/pools", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c := &clientImpl{RestyClient: NewRestyClient(nil, ClientOpts{APIUrl: server.URL})}
+	clusters := []Cluster{
+		{CustomerSlug: "org1", EnvironmentSlug: "env1", Slug: "cluster1", Identity: "id1"},
+		{CustomerSlug: "org1", EnvironmentSlug: "env1", Slug: "cluster2", Identity: "id2"},
+	}
+	nodePools, err := GetNodePoolsByClusters(context.Background(), c, clusters, GetNodePoolsOpts{AllowPartialResults: True()})
+	if len(nodePools) != 1 || nodePools[0].Name != "pool1" {
+		t.Fatalf("unexpected node pools: %+v", nodePools)
+	}
+	var partialResultError *PartialResultError
+	if !errors.As(err, &partialResultError) {
+		t.Fatalf("expected *PartialResultError, got %v", err)
+	}
+	if len(partialResultError.Failures) != 1 || partialResultError.Failures[0].Cluster != "org1/env1/cluster2 (id2)" {
+		t.Fatalf("unexpected failures: %+v", partialResultError.Failures)
+	}
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected error to match ErrNotFound, got %v", err)
+	}
+}
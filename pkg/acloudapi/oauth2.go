@@ -0,0 +1,300 @@
+package acloudapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+const (
+	// DefaultTokenRefreshBeforeExpiry is the time before the expiry of a token at which it is refreshed
+	DefaultTokenRefreshBeforeExpiry = time.Minute
+
+	grantTypeClientCredentials = "client_credentials"
+	grantTypeRefreshToken      = "refresh_token"
+	grantTypeDeviceCode        = "urn:ietf:params:oauth:grant-type:device_code"
+)
+
+// OAuth2Token is an OAuth2 access token, as returned by an OAuth2 token endpoint
+type OAuth2Token struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type,omitempty"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	ExpiresIn    int       `json:"expires_in,omitempty"`
+	Expiry       time.Time `json:"expiry,omitempty"`
+}
+
+// expiresWithin returns true if the token expires within d. Tokens without an expiry never expire.
+func (t *OAuth2Token) expiresWithin(d time.Duration) bool {
+	return !t.Expiry.IsZero() && time.Now().Add(d).After(t.Expiry)
+}
+
+// OAuth2Error is the error response of an OAuth2 token endpoint (RFC 6749 section 5.2)
+type OAuth2Error struct {
+	StatusCode       int    `json:"-"`
+	ErrorCode        string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+func (e *OAuth2Error) Error() string {
+	if e.ErrorDescription != "" {
+		return fmt.Sprintf("oauth2: %d %s: %s", e.StatusCode, e.ErrorCode, e.ErrorDescription)
+	}
+	return fmt.Sprintf("oauth2: %d %s", e.StatusCode, e.ErrorCode)
+}
+
+// TokenSource returns OAuth2 tokens. Implementations do not need to cache tokens, this is done by the authenticator.
+type TokenSource interface {
+	// Token returns a new token. current is the last token returned by the TokenSource, or nil,
+	// and can be used to refresh the token using its RefreshToken.
+	Token(ctx context.Context, current *OAuth2Token) (*OAuth2Token, error)
+}
+
+// tokenSourceAuthenticator authenticates requests with a bearer token, obtained from a TokenSource
+type tokenSourceAuthenticator struct {
+	source              TokenSource
+	refreshBeforeExpiry time.Duration
+
+	mu    sync.Mutex
+	token *OAuth2Token
+}
+
+// NewTokenSourceAuthenticator returns an authenticator that authenticates requests with bearer tokens obtained from source.
+// Tokens are cached and refreshed before they expire, and once more after the API responds with 401 Unauthorized.
+func NewTokenSourceAuthenticator(source TokenSource) RefreshableAuthenticator {
+	return &tokenSourceAuthenticator{
+		source:              source,
+		refreshBeforeExpiry: DefaultTokenRefreshBeforeExpiry,
+	}
+}
+
+func (a *tokenSourceAuthenticator) Authenticate(c *resty.Client, r *resty.Request) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.token == nil || a.token.expiresWithin(a.refreshBeforeExpiry) {
+		if err := a.refresh(r.Context()); err != nil {
+			return err
+		}
+	}
+	r.SetAuthScheme("Bearer")
+	r.SetAuthToken(a.token.AccessToken)
+	return nil
+}
+
+func (a *tokenSourceAuthenticator) Refresh(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.refresh(ctx)
+}
+
+func (a *tokenSourceAuthenticator) refresh(ctx context.Context) error {
+	token, err := a.source.Token(ctx, a.token)
+	if err != nil {
+		return fmt.Errorf("failed to obtain token: %w", err)
+	}
+	if token.AccessToken == "" {
+		return fmt.Errorf("failed to obtain token: token endpoint returned no access token")
+	}
+	a.token = token
+	return nil
+}
+
+// ClientCredentialsConfig configures the OAuth2 client-credentials grant, used to authenticate service accounts
+type ClientCredentialsConfig struct {
+	// TokenURL is the URL of the OAuth2 token endpoint
+	TokenURL string
+	// ClientID is the client ID of the service account
+	ClientID string
+	// ClientSecret is the client secret of the service account
+	ClientSecret string
+	// Scopes are the optional scopes to request
+	Scopes []string
+	// HTTPClient is used to call the token endpoint, defaults to http.DefaultClient
+	HTTPClient *http.Client
+}
+
+// NewClientCredentialsAuthenticator returns an authenticator that obtains bearer tokens using the OAuth2 client-credentials grant
+func NewClientCredentialsAuthenticator(config ClientCredentialsConfig) RefreshableAuthenticator {
+	return NewTokenSourceAuthenticator(&clientCredentialsTokenSource{config: config})
+}
+
+type clientCredentialsTokenSource struct {
+	config ClientCredentialsConfig
+}
+
+func (s *clientCredentialsTokenSource) Token(ctx context.Context, _ *OAuth2Token) (*OAuth2Token, error) {
+	form := map[string]string{
+		"grant_type": grantTypeClientCredentials,
+	}
+	if len(s.config.Scopes) > 0 {
+		form["scope"] = strings.Join(s.config.Scopes, " ")
+	}
+	return requestToken(ctx, s.config.HTTPClient, s.config.TokenURL, s.config.ClientID, s.config.ClientSecret, form)
+}
+
+// DeviceAuthorization is the response of an OAuth2 device authorization endpoint (RFC 8628 section 3.2)
+type DeviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete,omitempty"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval,omitempty"`
+}
+
+// DeviceCodeConfig configures the OAuth2 device authorization grant, used to authenticate users on a CLI
+type DeviceCodeConfig struct {
+	// DeviceAuthorizationURL is the URL of the OAuth2 device authorization endpoint
+	DeviceAuthorizationURL string
+	// TokenURL is the URL of the OAuth2 token endpoint
+	TokenURL string
+	// ClientID is the client ID of the (public) client
+	ClientID string
+	// Scopes are the optional scopes to request
+	Scopes []string
+	// Prompt is called to show the user where and with which code to log in
+	Prompt func(authorization DeviceAuthorization) error
+	// HTTPClient is used to call the OAuth2 endpoints, defaults to http.DefaultClient
+	HTTPClient *http.Client
+}
+
+// NewDeviceCodeAuthenticator returns an authenticator that obtains bearer tokens using the OAuth2 device authorization grant.
+// Tokens are refreshed using their refresh token, the user is only prompted again when refreshing fails.
+func NewDeviceCodeAuthenticator(config DeviceCodeConfig) RefreshableAuthenticator {
+	return NewTokenSourceAuthenticator(&deviceCodeTokenSource{config: config})
+}
+
+type deviceCodeTokenSource struct {
+	config DeviceCodeConfig
+}
+
+func (s *deviceCodeTokenSource) Token(ctx context.Context, current *OAuth2Token) (*OAuth2Token, error) {
+	if current != nil && current.RefreshToken != "" {
+		token, err := requestToken(ctx, s.config.HTTPClient, s.config.TokenURL, s.config.ClientID, "", map[string]string{
+			"grant_type":    grantTypeRefreshToken,
+			"refresh_token": current.RefreshToken,
+		})
+		if err == nil {
+			if token.RefreshToken == "" {
+				token.RefreshToken = current.RefreshToken
+			}
+			return token, nil
+		}
+		var oauth2Error *OAuth2Error
+		if !errors.As(err, &oauth2Error) {
+			return nil, err
+		}
+		// the refresh token has been rejected, start a new device authorization
+	}
+
+	authorization, err := s.authorizeDevice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if s.config.Prompt != nil {
+		if err := s.config.Prompt(*authorization); err != nil {
+			return nil, err
+		}
+	}
+	return s.pollToken(ctx, authorization)
+}
+
+func (s *deviceCodeTokenSource) authorizeDevice(ctx context.Context) (*DeviceAuthorization, error) {
+	form := map[string]string{
+		"client_id": s.config.ClientID,
+	}
+	if len(s.config.Scopes) > 0 {
+		form["scope"] = strings.Join(s.config.Scopes, " ")
+	}
+	authorization := DeviceAuthorization{}
+	oauth2Error := OAuth2Error{}
+	response, err := oauth2Client(s.config.HTTPClient).R().
+		SetContext(ctx).
+		SetFormData(form).
+		SetResult(&authorization).
+		SetError(&oauth2Error).
+		Post(s.config.DeviceAuthorizationURL)
+	if err != nil {
+		return nil, err
+	}
+	if !response.IsSuccess() {
+		oauth2Error.StatusCode = response.StatusCode()
+		return nil, &oauth2Error
+	}
+	return &authorization, nil
+}
+
+func (s *deviceCodeTokenSource) pollToken(ctx context.Context, authorization *DeviceAuthorization) (*OAuth2Token, error) {
+	interval := time.Duration(authorization.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	for {
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		token, err := requestToken(ctx, s.config.HTTPClient, s.config.TokenURL, s.config.ClientID, "", map[string]string{
+			"grant_type":  grantTypeDeviceCode,
+			"device_code": authorization.DeviceCode,
+		})
+		var oauth2Error *OAuth2Error
+		if !errors.As(err, &oauth2Error) {
+			return token, err
+		}
+		switch oauth2Error.ErrorCode {
+		case "authorization_pending":
+		case "slow_down":
+			interval += 5 * time.Second
+		default:
+			return nil, err
+		}
+	}
+}
+
+func oauth2Client(httpClient *http.Client) *resty.Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return resty.NewWithClient(httpClient).
+		SetHeader(HeaderAccept, ContentTypeApplicationJson).
+		SetHeader(HeaderUserAgent, DefaultUserAgent)
+}
+
+// requestToken requests a token from an OAuth2 token endpoint. When clientSecret is set, the client authenticates using HTTP basic
+// authentication, otherwise the client ID is sent in the form as a public client.
+func requestToken(ctx context.Context, httpClient *http.Client, tokenURL, clientID, clientSecret string, form map[string]string) (*OAuth2Token, error) {
+	token := OAuth2Token{}
+	oauth2Error := OAuth2Error{}
+	request := oauth2Client(httpClient).R().
+		SetContext(ctx).
+		SetResult(&token).
+		SetError(&oauth2Error)
+	if clientSecret != "" {
+		request.SetBasicAuth(clientID, clientSecret)
+	} else {
+		form["client_id"] = clientID
+	}
+	response, err := request.
+		SetFormData(form).
+		Post(tokenURL)
+	if err != nil {
+		return nil, err
+	}
+	if !response.IsSuccess() {
+		oauth2Error.StatusCode = response.StatusCode()
+		return nil, &oauth2Error
+	}
+	if token.Expiry.IsZero() && token.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+	return &token, nil
+}
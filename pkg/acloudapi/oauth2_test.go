@@ -0,0 +1,192 @@
+package acloudapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestClientCredentialsAuthenticator(t *testing.T) {
+	tokenRequests := &atomic.Int32{}
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("failed to parse form: %v", err)
+		}
+		if got := r.PostForm.Get("grant_type"); got != "client_credentials" {
+			t.Errorf("unexpected grant_type: %s", got)
+		}
+		if got := r.PostForm.Get("scope"); got != "api admin" {
+			t.Errorf("unexpected scope: %s", got)
+		}
+		clientID, clientSecret, ok := r.BasicAuth()
+		if !ok || clientID != "ci" || clientSecret != "secret" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":"invalid_client"}`)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"Bearer","expires_in":3600}`, tokenRequests.Add(1))
+	}))
+	defer tokenServer.Close()
+
+	t.Run("caches the token", func(t *testing.T) {
+		tokenRequests.Store(0)
+		apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if got := r.Header.Get("Authorization"); got != "Bearer token-1" {
+				t.Errorf("unexpected Authorization header: %s", got)
+			}
+			fmt.Fprint(w, `{}`)
+		}))
+		defer apiServer.Close()
+
+		authenticator := NewClientCredentialsAuthenticator(ClientCredentialsConfig{
+			TokenURL:     tokenServer.URL,
+			ClientID:     "ci",
+			ClientSecret: "secret",
+			Scopes:       []string{"api", "admin"},
+		})
+		c := NewRestyClient(authenticator, ClientOpts{APIUrl: apiServer.URL})
+		for i := 0; i < 3; i++ {
+			resp, err := c.R().Get("/")
+			if err := c.CheckResponse(resp, err); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		if got := tokenRequests.Load(); got != 1 {
+			t.Fatalf("token requests = %d, want 1", got)
+		}
+	})
+
+	t.Run("refreshes and retries once on 401", func(t *testing.T) {
+		tokenRequests.Store(0)
+		apiRequests := &atomic.Int32{}
+		apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			apiRequests.Add(1)
+			if r.Header.Get("Authorization") != "Bearer token-2" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprint(w, `{}`)
+		}))
+		defer apiServer.Close()
+
+		authenticator := NewClientCredentialsAuthenticator(ClientCredentialsConfig{
+			TokenURL:     tokenServer.URL,
+			ClientID:     "ci",
+			ClientSecret: "secret",
+			Scopes:       []string{"api", "admin"},
+		})
+		c := NewRestyClient(authenticator, ClientOpts{APIUrl: apiServer.URL, RetryPolicy: NoRetryPolicy()})
+		resp, err := c.R().SetContext(context.Background()).Get("/")
+		if err := c.CheckResponse(resp, err); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := apiRequests.Load(); got != 2 {
+			t.Fatalf("api requests = %d, want 2", got)
+		}
+	})
+
+	t.Run("does not retry more than once on 401", func(t *testing.T) {
+		apiRequests := &atomic.Int32{}
+		apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			apiRequests.Add(1)
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer apiServer.Close()
+
+		authenticator := NewClientCredentialsAuthenticator(ClientCredentialsConfig{
+			TokenURL:     tokenServer.URL,
+			ClientID:     "ci",
+			ClientSecret: "secret",
+			Scopes:       []string{"api", "admin"},
+		})
+		c := NewRestyClient(authenticator, ClientOpts{APIUrl: apiServer.URL, RetryPolicy: testRetryPolicy()})
+		resp, err := c.R().Get("/")
+		if err := c.CheckResponse(resp, err); err == nil {
+			t.Fatal("expected error")
+		}
+		if got := apiRequests.Load(); got != 2 {
+			t.Fatalf("api requests = %d, want 2", got)
+		}
+	})
+
+	t.Run("invalid client", func(t *testing.T) {
+		authenticator := NewClientCredentialsAuthenticator(ClientCredentialsConfig{
+			TokenURL:     tokenServer.URL,
+			ClientID:     "ci",
+			ClientSecret: "wrong",
+			Scopes:       []string{"api", "admin"},
+		})
+		c := NewRestyClient(authenticator, ClientOpts{APIUrl: "http://127.0.0.1:0"})
+		resp, err := c.R().Get("/")
+		err = c.CheckResponse(resp, err)
+		if err == nil || err.Error() != "failed to obtain token: oauth2: 401 invalid_client" {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestDeviceCodeAuthenticator(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/device", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"device_code":"device-1","user_code":"ABCD-EFGH","verification_uri":"https://example.com/device","expires_in":600,"interval":1}`)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("failed to parse form: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.PostForm.Get("grant_type") {
+		case "urn:ietf:params:oauth:grant-type:device_code":
+			if r.PostForm.Get("device_code") != "device-1" || r.PostForm.Get("client_id") != "cli" {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error":"invalid_grant"}`)
+				return
+			}
+			fmt.Fprint(w, `{"access_token":"device-token","refresh_token":"refresh-1","expires_in":3600}`)
+		case "refresh_token":
+			if r.PostForm.Get("refresh_token") != "refresh-1" {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error":"invalid_grant"}`)
+				return
+			}
+			fmt.Fprint(w, `{"access_token":"refreshed-token","expires_in":3600}`)
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var prompted DeviceAuthorization
+	authenticator := NewDeviceCodeAuthenticator(DeviceCodeConfig{
+		DeviceAuthorizationURL: server.URL + "/device",
+		TokenURL:               server.URL + "/token",
+		ClientID:               "cli",
+		Prompt: func(authorization DeviceAuthorization) error {
+			prompted = authorization
+			return nil
+		},
+	}).(*tokenSourceAuthenticator)
+
+	ctx := context.Background()
+	if err := authenticator.Refresh(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if prompted.UserCode != "ABCD-EFGH" {
+		t.Fatalf("user was not prompted: %+v", prompted)
+	}
+	if authenticator.token.AccessToken != "device-token" {
+		t.Fatalf("unexpected token: %+v", authenticator.token)
+	}
+
+	if err := authenticator.Refresh(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if authenticator.token.AccessToken != "refreshed-token" || authenticator.token.RefreshToken != "refresh-1" {
+		t.Fatalf("unexpected refreshed token: %+v", authenticator.token)
+	}
+}
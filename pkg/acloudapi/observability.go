@@ -3,8 +3,8 @@ package acloudapi
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"net/http"
 	"strings"
 	"time"
 
@@ -71,7 +71,7 @@ func (c *clientImpl) GetObservabilityTenantBySlug(ctx context.Context, org, slug
 func (c *clientImpl) GetObservabilityTenantAlertmanagerConfiguration(ctx context.Context, org, slug string) (*ObservabilityAlertmanager, error) {
 	alertmanagerConfigResponse, getResponse, err := getAlertManagerConfigResponse(ctx, org, slug, c)
 	if err := c.CheckResponse(getResponse, err); err != nil {
-		if getResponse.StatusCode() == http.StatusNotFound {
+		if errors.Is(err, ErrNotFound) {
 			return &ObservabilityAlertmanager{
 				Rules:              alertmanagerConfigResponse.Rules,
 				Templates:          alertmanagerConfigResponse.Templates,
@@ -156,7 +156,7 @@ func (c *clientImpl) GetObservabilityOrganisationAlerts(ctx context.Context, org
 		SetResult(&alerts).
 		Get(fmt.Sprintf("/api/v1/orgs/%s/alerts", org))
 	if err := c.CheckResponse(response, err); err != nil {
-		if response.StatusCode() == http.StatusNotFound {
+		if errors.Is(err, ErrNotFound) {
 			return alerts, nil
 		}
 		return nil, err
@@ -171,7 +171,7 @@ func (c *clientImpl) GetObservabilityTenantAlerts(ctx context.Context, org strin
 		SetResult(&alerts).
 		Get(fmt.Sprintf("/api/v1/orgs/%s/alerts/%s", org, slug))
 	if err := c.CheckResponse(response, err); err != nil {
-		if response.StatusCode() == http.StatusNotFound {
+		if errors.Is(err, ErrNotFound) {
 			return alerts, nil
 		}
 		return nil, err
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"iter"
 	"strconv"
 )
 
@@ -52,3 +53,60 @@ func MarshalPagedResultContent[T any](pagedResult PagedResult) ([]T, error) {
 
 	return content, nil
 }
+
+// Page is a single page of a paged API result, with its content decoded into T
+type Page[T any] struct {
+	Content       []T  `json:"content"`
+	Last          bool `json:"last"`
+	TotalPages    int  `json:"totalPages"`
+	TotalElements int  `json:"totalElements"`
+	Number        int  `json:"number"`
+}
+
+// GetPage gets a single page of a paged API result and decodes its content into T
+func GetPage[T any](ctx context.Context, client *RestyClient, url string, page int) (Page[T], error) {
+	result := Page[T]{}
+	response, err := client.R().
+		SetContext(ctx).
+		SetQueryParam("page", strconv.Itoa(page)).
+		SetResult(&result).
+		Get(url)
+	if err := client.CheckResponse(response, err); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// IteratePaged returns an iterator over all items of a paged API result. Pages are requested one at a time,
+// only when the consumer has processed all items of the previous page. Iteration stops at the first error,
+// which is yielded together with the zero value of T, or when the context is cancelled.
+func IteratePaged[T any](ctx context.Context, client *RestyClient, url string) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		for currentPage := 0; ; currentPage++ {
+			// Check for possible unending loop when "Page.Last" is not set
+			if currentPage > MAX_PAGING_LOOPS {
+				yield(zero, ErrMaximumPagingLoopsExceeded)
+				return
+			}
+			if err := ctx.Err(); err != nil {
+				yield(zero, err)
+				return
+			}
+
+			page, err := GetPage[T](ctx, client, url, currentPage)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			for _, item := range page.Content {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if page.Last {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package acloudapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+func newPagedClustersServer(t *testing.T, numberOfPages int) (*httptest.Server, *atomic.Int32) {
+	requests := &atomic.Int32{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path != "/api/v1/orgs/org1/clusters" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("show-compute") != "true" {
+			t.Errorf("expected show-compute query param, got %s", r.URL.RawQuery)
+		}
+		page, err := strconv.Atoi(r.URL.Query().Get("page"))
+		if err != nil {
+			t.Errorf("invalid page: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"content":[{"slug":"cluster-%d-a","status":"started"},{"slug":"cluster-%d-b"}],"last":%t,"number":%d}`,
+			page, page, page == numberOfPages-1, page)
+	}))
+	return server, requests
+}
+
+func TestListClustersIter(t *testing.T) {
+	t.Run("all pages", func(t *testing.T) {
+		server, requests := newPagedClustersServer(t, 3)
+		defer server.Close()
+
+		c := &clientImpl{RestyClient: NewRestyClient(nil, ClientOpts{APIUrl: server.URL})}
+		var slugs []string
+		for cluster, err := range c.ListClustersIter(context.Background(), "org1") {
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cluster.CustomerSlug != "org1" {
+				t.Fatalf("expected CustomerSlug to be set, got %q", cluster.CustomerSlug)
+			}
+			if cluster.Slug == "cluster-0-a" && cluster.Status != "running" {
+				t.Fatalf("expected status to be fixed, got %q", cluster.Status)
+			}
+			slugs = append(slugs, cluster.Slug)
+		}
+		if len(slugs) != 6 || slugs[0] != "cluster-0-a" || slugs[5] != "cluster-2-b" {
+			t.Fatalf("unexpected clusters: %v", slugs)
+		}
+		if got := requests.Load(); got != 3 {
+			t.Fatalf("requests = %d, want 3", got)
+		}
+	})
+
+	t.Run("stops requesting pages when the consumer breaks", func(t *testing.T) {
+		server, requests := newPagedClustersServer(t, 3)
+		defer server.Close()
+
+		c := &clientImpl{RestyClient: NewRestyClient(nil, ClientOpts{APIUrl: server.URL})}
+		for cluster, err := range c.ListClustersIter(context.Background(), "org1") {
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cluster.Slug == "cluster-0-b" {
+				break
+			}
+		}
+		if got := requests.Load(); got != 1 {
+			t.Fatalf("requests = %d, want 1", got)
+		}
+	})
+
+	t.Run("stops between pages when the context is cancelled", func(t *testing.T) {
+		server, requests := newPagedClustersServer(t, 3)
+		defer server.Close()
+
+		c := &clientImpl{RestyClient: NewRestyClient(nil, ClientOpts{APIUrl: server.URL})}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		var lastErr error
+		for cluster, err := range c.ListClustersIter(ctx, "org1") {
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			if cluster.Slug == "cluster-0-b" {
+				cancel()
+			}
+		}
+		if !errors.Is(lastErr, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got %v", lastErr)
+		}
+		if got := requests.Load(); got != 1 {
+			t.Fatalf("requests = %d, want 1", got)
+		}
+	})
+
+	t.Run("yields api errors", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer server.Close()
+
+		c := &clientImpl{RestyClient: NewRestyClient(nil, ClientOpts{APIUrl: server.URL})}
+		var errs []error
+		for _, err := range c.ListClustersIter(context.Background(), "org1") {
+			errs = append(errs, err)
+		}
+		if len(errs) != 1 || !errors.Is(errs[0], ErrForbidden) {
+			t.Fatalf("expected a single ErrForbidden, got %v", errs)
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package acloudapi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PartialResultError is returned by aggregating calls such as GetClusters and GetNodePoolsByClusters when
+// AllowPartialResults is enabled and one or more organisations or clusters failed. The results of the
+// organisations and clusters that succeeded are returned together with this error.
+type PartialResultError struct {
+	Failures []PartialFailure
+}
+
+// PartialFailure describes a single organisation or cluster that could not be retrieved
+type PartialFailure struct {
+	// OrganisationSlug is the organisation that failed
+	OrganisationSlug string
+	// Cluster is the identifier of the cluster that failed, empty if the whole organisation failed
+	Cluster string
+	// Err is the error of the failed call
+	Err error
+}
+
+func (f PartialFailure) Error() string {
+	if f.Cluster != "" {
+		return fmt.Sprintf("cluster %s: %v", f.Cluster, f.Err)
+	}
+	return fmt.Sprintf("organisation %s: %v", f.OrganisationSlug, f.Err)
+}
+
+func (e *PartialResultError) Error() string {
+	failures := make([]string, len(e.Failures))
+	for i, failure := range e.Failures {
+		failures[i] = failure.Error()
+	}
+	return fmt.Sprintf("partial result, %d request(s) failed: %s", len(e.Failures), strings.Join(failures, "; "))
+}
+
+// Unwrap returns the errors of all failures, so errors.Is and errors.As match any of them
+func (e *PartialResultError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, failure := range e.Failures {
+		errs[i] = failure.Err
+	}
+	return errs
+}
+
+// add appends the failures of err to the PartialResultError, flattening a nested PartialResultError
+func (e *PartialResultError) add(err error, failure PartialFailure) {
+	if partialResultError, ok := err.(*PartialResultError); ok {
+		e.Failures = append(e.Failures, partialResultError.Failures...)
+		return
+	}
+	failure.Err = err
+	e.Failures = append(e.Failures, failure)
+}
+
+// errorOrNil returns nil when there are no failures, to prevent returning a non-nil error interface holding a nil pointer
+func (e *PartialResultError) errorOrNil() error {
+	if e == nil || len(e.Failures) == 0 {
+		return nil
+	}
+	return e
+}
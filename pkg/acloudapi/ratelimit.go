@@ -0,0 +1,141 @@
+package acloudapi
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/go-resty/resty/v2"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// DefaultFanOutConcurrency is the number of concurrent requests used by fan-out helpers such as GetClusters,
+	// when ClientOpts.MaxConcurrentRequests is not set
+	DefaultFanOutConcurrency = 10
+)
+
+// requestLimiter waits for the rate limiter and limits the number of requests in flight. It is registered as resty
+// hooks rather than wrapping the transport, so the transport of the resty client stays a *http.Transport and can still be
+// configured through the resty client, e.g. with SetProxy.
+type requestLimiter struct {
+	limiter  *rate.Limiter
+	inFlight chan struct{}
+
+	mu       sync.Mutex
+	acquired map[*http.Request]bool
+}
+
+// newRequestLimiter returns the requestLimiter for the ClientOpts, or nil if neither a rate nor a concurrency limit is set
+func newRequestLimiter(opts ClientOpts) *requestLimiter {
+	if opts.RequestsPerSecond <= 0 && opts.MaxConcurrentRequests <= 0 {
+		return nil
+	}
+	l := &requestLimiter{acquired: map[*http.Request]bool{}}
+	if opts.RequestsPerSecond > 0 {
+		burst := opts.RequestBurst
+		if burst <= 0 {
+			burst = 1
+		}
+		l.limiter = rate.NewLimiter(rate.Limit(opts.RequestsPerSecond), burst)
+	}
+	if opts.MaxConcurrentRequests > 0 {
+		l.inFlight = make(chan struct{}, opts.MaxConcurrentRequests)
+	}
+	return l
+}
+
+// register acquires a slot right before every attempt is sent. The slot is released once the response has been read,
+// or when the attempt failed: before the next retry, or when the request completes.
+func (l *requestLimiter) register(client *resty.Client) {
+	client.SetPreRequestHook(func(_ *resty.Client, request *http.Request) error {
+		return l.acquire(request)
+	})
+	releaseResponse := func(response *resty.Response) {
+		if response != nil && response.Request != nil {
+			l.release(response.Request.RawRequest)
+		}
+	}
+	client.OnAfterResponse(func(_ *resty.Client, response *resty.Response) error {
+		releaseResponse(response)
+		return nil
+	})
+	client.AddRetryHook(func(response *resty.Response, _ error) {
+		releaseResponse(response)
+	})
+	client.OnSuccess(func(_ *resty.Client, response *resty.Response) {
+		releaseResponse(response)
+	})
+	releaseRequest := func(request *resty.Request, _ error) {
+		l.release(request.RawRequest)
+	}
+	client.OnError(releaseRequest)
+	client.OnPanic(releaseRequest)
+}
+
+func (l *requestLimiter) acquire(request *http.Request) error {
+	ctx := request.Context()
+	if l.inFlight != nil {
+		select {
+		case l.inFlight <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if l.limiter != nil {
+		if err := l.limiter.Wait(ctx); err != nil {
+			if l.inFlight != nil {
+				<-l.inFlight
+			}
+			return err
+		}
+	}
+	l.mu.Lock()
+	l.acquired[request] = true
+	l.mu.Unlock()
+	return nil
+}
+
+// release releases the slot of the request, if it still holds one
+func (l *requestLimiter) release(request *http.Request) {
+	if request == nil {
+		return
+	}
+	l.mu.Lock()
+	acquired := l.acquired[request]
+	delete(l.acquired, request)
+	l.mu.Unlock()
+	if acquired && l.inFlight != nil {
+		<-l.inFlight
+	}
+}
+
+// fanOutConcurrency returns the maximum number of concurrent requests fan-out helpers may use
+func (c *RestyClient) fanOutConcurrency() int {
+	if c.opts.MaxConcurrentRequests > 0 {
+		return c.opts.MaxConcurrentRequests
+	}
+	return DefaultFanOutConcurrency
+}
+
+// runConcurrently calls fn for every index in [0, n) using a pool of at most concurrency workers, and waits until all calls have returned
+func runConcurrently(n, concurrency int, fn func(i int)) {
+	if concurrency <= 0 || concurrency > n {
+		concurrency = n
+	}
+	indexes := make(chan int)
+	wg := sync.WaitGroup{}
+	for worker := 0; worker < concurrency; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
@@ -0,0 +1,147 @@
+package acloudapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type concurrencyCounter struct {
+	current atomic.Int32
+	max     atomic.Int32
+}
+
+func (c *concurrencyCounter) enter() {
+	current := c.current.Add(1)
+	for {
+		max := c.max.Load()
+		if current <= max || c.max.CompareAndSwap(max, current) {
+			return
+		}
+	}
+}
+
+func (c *concurrencyCounter) leave() {
+	c.current.Add(-1)
+}
+
+func Test_runConcurrently(t *testing.T) {
+	counter := &concurrencyCounter{}
+	called := make([]bool, 50)
+	runConcurrently(len(called), 3, func(i int) {
+		counter.enter()
+		defer counter.leave()
+		time.Sleep(time.Millisecond)
+		called[i] = true
+	})
+	for i, c := range called {
+		if !c {
+			t.Fatalf("fn was not called for index %d", i)
+		}
+	}
+	if got := counter.max.Load(); got > 3 {
+		t.Fatalf("max concurrency = %d, want at most 3", got)
+	}
+}
+
+func TestGetClustersMaxConcurrentRequests(t *testing.T) {
+	const numberOfOrganisations = 20
+	counter := &concurrencyCounter{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/memberships", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"content":[`)
+		for i := 0; i < numberOfOrganisations; i++ {
+			if i > 0 {
+				fmt.Fprint(w, ",")
+			}
+			fmt.Fprintf(w, `{"slug":"org%d"}`, i)
+		}
+		fmt.Fprint(w, `],"last":true}`)
+	})
+	mux.HandleFunc("/api/v1/orgs/", func(w http.ResponseWriter, r *http.Request) {
+		counter.enter()
+		defer counter.leave()
+		time.Sleep(5 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"content":[{"slug":"cluster"}],"last":true}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c := &clientImpl{RestyClient: NewRestyClient(nil, ClientOpts{APIUrl: server.URL, MaxConcurrentRequests: 2})}
+	clusters, err := c.GetClusters(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(clusters) != numberOfOrganisations {
+		t.Fatalf("got %d clusters, want %d", len(clusters), numberOfOrganisations)
+	}
+	if got := counter.max.Load(); got > 2 {
+		t.Fatalf("max requests in flight = %d, want at most 2", got)
+	}
+}
+
+func TestRequestLimiter(t *testing.T) {
+	counter := &concurrencyCounter{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		counter.enter()
+		defer counter.leave()
+		time.Sleep(5 * time.Millisecond)
+		fmt.Fprint(w, `{}`)
+	}))
+	defer server.Close()
+
+	c := NewRestyClient(nil, ClientOpts{APIUrl: server.URL, MaxConcurrentRequests: 1, RequestsPerSecond: 1000, RequestBurst: 5})
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := c.R().Get("/")
+			if err := c.CheckResponse(resp, err); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := counter.max.Load(); got != 1 {
+		t.Fatalf("max requests in flight = %d, want 1", got)
+	}
+	if _, err := c.Resty().Transport(); err != nil {
+		t.Fatalf("expected the transport to stay a *http.Transport: %v", err)
+	}
+
+	t.Run("failed attempts release their slot", func(t *testing.T) {
+		c := NewRestyClient(nil, ClientOpts{APIUrl: "http://127.0.0.1:1", MaxConcurrentRequests: 1, RetryPolicy: &RetryPolicy{MaxAttempts: 3, WaitTime: time.Millisecond, MaxWaitTime: time.Millisecond, RetryNetworkErrors: true, RetryableMethods: []string{http.MethodGet}}})
+		for i := 0; i < 3; i++ {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			_, err := c.R().SetContext(ctx).Get("/")
+			cancel()
+			if err == nil || errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("expected a connection error, got %v", err)
+			}
+		}
+	})
+
+	t.Run("rate limiter respects context", func(t *testing.T) {
+		c := NewRestyClient(nil, ClientOpts{APIUrl: server.URL, RequestsPerSecond: 0.001, RetryPolicy: NoRetryPolicy()})
+		resp, err := c.R().Get("/") // consumes the only token
+		if err := c.CheckResponse(resp, err); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		resp, err = c.R().SetContext(ctx).Get("/")
+		if err := c.CheckResponse(resp, err); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net"
 	"net/http"
 	"runtime"
+	"sync"
 	"time"
 
 	"github.com/go-resty/resty/v2"
@@ -16,6 +18,7 @@ const (
 	HeaderAccept      = "Accept"
 	HeaderContentType = "Content-Type"
 	HeaderUserAgent   = "User-Agent"
+	HeaderRequestID   = "X-Request-Id"
 
 	ContentTypeApplicationJson = "application/json"
 
@@ -43,7 +46,8 @@ type ClientOpts struct {
 	// Debug is ... TODO
 	Debug bool
 
-	// Trace is ... TODO
+	// Trace adds the timings of every request (DNS lookup, connection, TLS handshake, server time, ...) to the request log records.
+	// When no Logger is set, requests are logged using slog.Default at level Info
 	Trace bool
 
 	// DebugShowAuthorizationHeader is  ... TODO
@@ -67,6 +71,43 @@ type ClientOpts struct {
 	//
 	// note: only used if CustomResty is not provided
 	CustomTransport *http.Transport
+
+	// RetryPolicy configures retries of failed API calls. Defaults to DefaultRetryPolicy, use NoRetryPolicy to disable retries
+	//
+	// note: only used if CustomResty is not provided
+	RetryPolicy *RetryPolicy
+
+	// RequestsPerSecond limits the rate of requests, including retries, using a token bucket shared by all API calls of the client.
+	// A value of 0 disables rate limiting
+	//
+	// note: only used if CustomResty is not provided
+	RequestsPerSecond float64
+
+	// RequestBurst is the maximum burst size of the rate limiter. Defaults to 1
+	RequestBurst int
+
+	// MaxConcurrentRequests limits the number of requests in flight at the same time. It also sets the number of workers
+	// used by fan-out helpers such as GetClusters and GetNodePoolsByClusters (default DefaultFanOutConcurrency)
+	//
+	// note: the limit on requests in flight is only applied if CustomResty is not provided
+	MaxConcurrentRequests int
+
+	// Logger enables logging of every request and response as structured log records, including the method, path, status,
+	// duration, attempt and (redacted) headers. It also receives the log output of Resty, such as the Debug output
+	//
+	// note: when CustomResty is provided, the request log records are added to it but its Resty logger is kept. The
+	// records are only added once per CustomResty, using the Logger of the first client created with it
+	Logger *slog.Logger
+
+	// RequestLogLevel is the level at which successful requests are logged, defaults to slog.LevelDebug
+	RequestLogLevel slog.Leveler
+
+	// ErrorLogLevel is the level at which failed requests are logged, defaults to slog.LevelWarn
+	ErrorLogLevel slog.Leveler
+
+	// Instrumentation is notified of every API call and request, e.g. to record OpenTelemetry traces and metrics
+	// using package otelacloudapi. Instrumentation is disabled when nil
+	Instrumentation Instrumentation
 }
 
 func SetMissingOpts(opts ClientOpts) ClientOpts {
@@ -78,6 +119,9 @@ func SetMissingOpts(opts ClientOpts) ClientOpts {
 	if opts.APIUrl == "" {
 		opts.APIUrl = DefaultPublicAPIUrl
 	}
+	if opts.RetryPolicy == nil {
+		opts.RetryPolicy = DefaultRetryPolicy()
+	}
 	return opts
 }
 
@@ -93,10 +137,19 @@ func GetRestyClient(authenticator Authenticator, opts ClientOpts) *resty.Client
 	client := opts.CustomResty
 	if client == nil {
 		client = NewDefaultRestyClient(authenticator, opts, client)
+	} else if requestLogger := newRequestLogger(opts); requestLogger != nil {
+		// keep the logger of the custom client, only add the request log records. The hooks are added once, so a custom
+		// client shared by several clients does not log every request multiple times.
+		if _, registered := loggedCustomRestyClients.LoadOrStore(client, struct{}{}); !registered {
+			requestLogger.register(client, false)
+		}
 	}
 	return client
 }
 
+// loggedCustomRestyClients holds the custom Resty clients the request log hooks have been added to
+var loggedCustomRestyClients sync.Map
+
 func NewDefaultRestyClient(authenticator Authenticator, opts ClientOpts, client *resty.Client) *resty.Client {
 	client = resty.New().
 		SetBaseURL(opts.APIUrl).
@@ -133,7 +186,21 @@ func NewDefaultRestyClient(authenticator Authenticator, opts ClientOpts, client
 			MaxConnsPerHost:       10,
 		}
 	}
-	client.SetTransport(transport)
+	var roundTripper http.RoundTripper = transport
+	if opts.Instrumentation != nil {
+		roundTripper = opts.Instrumentation.WrapTransport(roundTripper)
+	}
+	client.SetTransport(roundTripper)
+	if limiter := newRequestLimiter(opts); limiter != nil {
+		limiter.register(client)
+	}
+
+	if opts.RetryPolicy != nil {
+		opts.RetryPolicy.apply(client)
+	}
+	if refreshableAuthenticator, ok := authenticator.(RefreshableAuthenticator); ok {
+		addReauthenticateRetry(client, refreshableAuthenticator)
+	}
 
 	if opts.Debug {
 		client.SetDebug(true)
@@ -147,8 +214,8 @@ func NewDefaultRestyClient(authenticator Authenticator, opts ClientOpts, client
 			})
 		}
 	}
-	if opts.Trace {
-		client.EnableTrace()
+	if requestLogger := newRequestLogger(opts); requestLogger != nil {
+		requestLogger.register(client, opts.Logger != nil)
 	}
 	return client
 }
@@ -161,41 +228,42 @@ func (c *RestyClient) CheckResponse(response *resty.Response, err error) error {
 		return fmt.Errorf("no response received")
 	}
 
-	if c.opts.Trace {
-		fmt.Printf("~~~ TRACE %s %s%s ~~~\n", response.Request.Method, c.opts.APIUrl, response.Request.URL)
-		ti := response.Request.TraceInfo()
-		fmt.Println("  DNSLookup     :", ti.DNSLookup)
-		fmt.Println("  ConnTime      :", ti.ConnTime)
-		fmt.Println("  TCPConnTime   :", ti.TCPConnTime)
-		fmt.Println("  TLSHandshake  :", ti.TLSHandshake)
-		fmt.Println("  ServerTime    :", ti.ServerTime)
-		fmt.Println("  ResponseTime  :", ti.ResponseTime)
-		fmt.Println("  TotalTime     :", ti.TotalTime)
-		fmt.Println("  IsConnReused  :", ti.IsConnReused)
-		fmt.Println("  IsConnWasIdle :", ti.IsConnWasIdle)
-		fmt.Println("  ConnIdleTime  :", ti.ConnIdleTime)
-		fmt.Println("  RequestAttempt:", ti.RequestAttempt)
-		fmt.Println("  RemoteAddr    :", ti.RemoteAddr.String())
-		fmt.Println("==============================================================================")
-	}
 	if err != nil {
 		return err
 	}
 
-	body := response.Body()
-	var errorResult Error
-	if body != nil {
-		err = json.Unmarshal(body, &errorResult)
-		if err != nil {
-			errorResult.Message = string(body)
-		}
-	}
 	if !response.IsSuccess() {
-		return fmt.Errorf("%d: %s", response.StatusCode(), errorResult.Message)
+		return newAPIError(response)
 	}
 	return nil
 }
 
+func newAPIError(response *resty.Response) *APIError {
+	apiError := &APIError{
+		StatusCode: response.StatusCode(),
+		RequestID:  response.Header().Get(HeaderRequestID),
+	}
+	if response.Request != nil {
+		apiError.Method = response.Request.Method
+		apiError.Path = response.Request.URL
+		if response.Request.RawRequest != nil {
+			apiError.Path = response.Request.RawRequest.URL.RequestURI()
+		}
+	}
+
+	body := response.Body()
+	if len(body) > 0 {
+		var errorResult Error
+		if err := json.Unmarshal(body, &errorResult); err != nil {
+			apiError.Message = string(body)
+		} else {
+			apiError.Message = errorResult.Message
+			apiError.FieldErrors = errorResult.Errors
+		}
+	}
+	return apiError
+}
+
 func (c *RestyClient) GetPaged(ctx context.Context, url string) (PagedResult, error) {
 	finalPagedResult := PagedResult{}
 
@@ -1,6 +1,7 @@
 package acloudapi
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -39,4 +40,61 @@ func TestRestyClient_CheckResponse(t *testing.T) {
 			t.Fatalf("unexpected error: %v", err)
 		}
 	})
+
+	t.Run("api error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set(HeaderRequestID, "request-1")
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message":"cluster not found","errors":[{"field":"slug","message":"unknown"}]}`)
+		}))
+		defer server.Close()
+
+		c := NewRestyClient(nil, ClientOpts{APIUrl: server.URL})
+		resp, err := c.R().Get("/api/v1/orgs/org1/clusters/env1/cluster1?includeDetails=true")
+		err = c.CheckResponse(resp, err)
+		if !errors.Is(err, ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+		if errors.Is(err, ErrConflict) {
+			t.Fatalf("did not expect ErrConflict, got %v", err)
+		}
+		var apiError *APIError
+		if !errors.As(err, &apiError) {
+			t.Fatalf("expected *APIError, got %T", err)
+		}
+		if apiError.StatusCode != http.StatusNotFound || apiError.Message != "cluster not found" {
+			t.Fatalf("unexpected api error: %+v", apiError)
+		}
+		if apiError.Method != http.MethodGet || apiError.Path != "/api/v1/orgs/org1/clusters/env1/cluster1?includeDetails=true" {
+			t.Fatalf("unexpected request in api error: %s %s", apiError.Method, apiError.Path)
+		}
+		if apiError.RequestID != "request-1" {
+			t.Fatalf("unexpected request id: %q", apiError.RequestID)
+		}
+		if len(apiError.FieldErrors) != 1 || apiError.FieldErrors[0].Field != "slug" {
+			t.Fatalf("unexpected field errors: %+v", apiError.FieldErrors)
+		}
+		if want := "404: cluster not found (slug: unknown)"; err.Error() != want {
+			t.Fatalf("Error() = %q, want %q", err.Error(), want)
+		}
+	})
+
+	t.Run("api error with plain text body", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, "slow down")
+		}))
+		defer server.Close()
+
+		c := NewRestyClient(nil, ClientOpts{APIUrl: server.URL, RetryPolicy: NoRetryPolicy()})
+		resp, err := c.R().Get("/")
+		err = c.CheckResponse(resp, err)
+		if !errors.Is(err, ErrRateLimited) {
+			t.Fatalf("expected ErrRateLimited, got %v", err)
+		}
+		if err.Error() != "429: slow down" {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
 }
@@ -0,0 +1,143 @@
+package acloudapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+const (
+	HeaderRetryAfter = "Retry-After"
+
+	// DefaultRetryMaxAttempts is the default maximum number of attempts for a single API call, including the first attempt
+	DefaultRetryMaxAttempts = 3
+	// DefaultRetryWaitTime is the default initial wait time between attempts
+	DefaultRetryWaitTime = 500 * time.Millisecond
+	// DefaultRetryMaxWaitTime is the default maximum wait time between attempts
+	DefaultRetryMaxWaitTime = 10 * time.Second
+)
+
+// RetryPolicy configures how failed API calls are retried.
+//
+// The wait time between attempts increases exponentially starting at WaitTime, with jitter, and is capped at MaxWaitTime.
+// When the API returns a Retry-After header, that value is used instead (capped at MaxWaitTime).
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first attempt. A value of 1 or lower disables retries
+	MaxAttempts int
+
+	// WaitTime is the wait time before the first retry
+	WaitTime time.Duration
+
+	// MaxWaitTime is the maximum wait time between two attempts
+	MaxWaitTime time.Duration
+
+	// RetryableStatusCodes are the HTTP status codes on which a request is retried
+	RetryableStatusCodes []int
+
+	// RetryNetworkErrors enables retries on network errors, such as timeouts, refused and reset connections
+	RetryNetworkErrors bool
+
+	// RetryableMethods are the HTTP methods which are retried. Only idempotent methods are retried by default,
+	// add http.MethodPost and/or http.MethodPatch to explicitly opt in to retries for these methods.
+	RetryableMethods []string
+}
+
+// DefaultRetryPolicy returns the RetryPolicy used when ClientOpts.RetryPolicy is not set
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: DefaultRetryMaxAttempts,
+		WaitTime:    DefaultRetryWaitTime,
+		MaxWaitTime: DefaultRetryMaxWaitTime,
+		RetryableStatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+		RetryNetworkErrors: true,
+		RetryableMethods: []string{
+			http.MethodGet,
+			http.MethodHead,
+			http.MethodOptions,
+			http.MethodPut,
+			http.MethodDelete,
+		},
+	}
+}
+
+// NoRetryPolicy returns a RetryPolicy that disables retries
+func NoRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{MaxAttempts: 1}
+}
+
+func (p *RetryPolicy) apply(client *resty.Client) {
+	if p.MaxAttempts <= 1 {
+		return
+	}
+	client.
+		SetRetryCount(p.MaxAttempts - 1).
+		SetRetryWaitTime(p.WaitTime).
+		SetRetryMaxWaitTime(p.MaxWaitTime).
+		SetRetryAfter(retryAfter).
+		AddRetryCondition(p.shouldRetry)
+}
+
+func (p *RetryPolicy) shouldRetry(response *resty.Response, err error) bool {
+	if response == nil || response.Request == nil {
+		return false
+	}
+	if !slices.Contains(p.RetryableMethods, response.Request.Method) {
+		return false
+	}
+	if err != nil {
+		return p.RetryNetworkErrors && isRetryableNetworkError(err)
+	}
+	return slices.Contains(p.RetryableStatusCodes, response.StatusCode())
+}
+
+func isRetryableNetworkError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	var netError net.Error
+	return errors.As(err, &netError) && netError.Timeout()
+}
+
+// retryAfter returns the wait time requested by the Retry-After header, which is either a number of seconds
+// or an HTTP date. Returning 0 makes resty fall back to exponential backoff.
+func retryAfter(_ *resty.Client, response *resty.Response) (time.Duration, error) {
+	if response == nil {
+		return 0, nil
+	}
+	return parseRetryAfter(response.Header().Get(HeaderRetryAfter), time.Now()), nil
+}
+
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := date.Sub(now); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
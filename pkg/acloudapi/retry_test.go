@@ -0,0 +1,166 @@
+package acloudapi
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newFlakyServer(failures int32, statusCode int) (*httptest.Server, *atomic.Int32) {
+	attempts := &atomic.Int32{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) <= failures {
+			w.Header().Set(HeaderRetryAfter, "0")
+			w.WriteHeader(statusCode)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	return server, attempts
+}
+
+func testRetryPolicy() *RetryPolicy {
+	policy := DefaultRetryPolicy()
+	policy.WaitTime = time.Millisecond
+	policy.MaxWaitTime = 5 * time.Millisecond
+	return policy
+}
+
+func TestRetryPolicy(t *testing.T) {
+	t.Run("retries idempotent methods on retryable status codes", func(t *testing.T) {
+		server, attempts := newFlakyServer(2, http.StatusBadGateway)
+		defer server.Close()
+
+		c := NewRestyClient(nil, ClientOpts{APIUrl: server.URL, RetryPolicy: testRetryPolicy()})
+		resp, err := c.R().Get("/")
+		if err := c.CheckResponse(resp, err); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := attempts.Load(); got != 3 {
+			t.Fatalf("attempts = %d, want 3", got)
+		}
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		server, attempts := newFlakyServer(5, http.StatusTooManyRequests)
+		defer server.Close()
+
+		c := NewRestyClient(nil, ClientOpts{APIUrl: server.URL, RetryPolicy: testRetryPolicy()})
+		resp, err := c.R().Get("/")
+		if err := c.CheckResponse(resp, err); !errors.Is(err, ErrRateLimited) {
+			t.Fatalf("expected ErrRateLimited, got %v", err)
+		}
+		if got := attempts.Load(); got != DefaultRetryMaxAttempts {
+			t.Fatalf("attempts = %d, want %d", got, DefaultRetryMaxAttempts)
+		}
+	})
+
+	t.Run("does not retry POST by default", func(t *testing.T) {
+		server, attempts := newFlakyServer(1, http.StatusServiceUnavailable)
+		defer server.Close()
+
+		c := NewRestyClient(nil, ClientOpts{APIUrl: server.URL, RetryPolicy: testRetryPolicy()})
+		resp, err := c.R().Post("/")
+		if err := c.CheckResponse(resp, err); err == nil {
+			t.Fatal("expected error")
+		}
+		if got := attempts.Load(); got != 1 {
+			t.Fatalf("attempts = %d, want 1", got)
+		}
+	})
+
+	t.Run("retries POST when opted in", func(t *testing.T) {
+		server, attempts := newFlakyServer(1, http.StatusServiceUnavailable)
+		defer server.Close()
+
+		policy := testRetryPolicy()
+		policy.RetryableMethods = append(policy.RetryableMethods, http.MethodPost)
+		c := NewRestyClient(nil, ClientOpts{APIUrl: server.URL, RetryPolicy: policy})
+		resp, err := c.R().Post("/")
+		if err := c.CheckResponse(resp, err); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := attempts.Load(); got != 2 {
+			t.Fatalf("attempts = %d, want 2", got)
+		}
+	})
+
+	t.Run("does not retry non-retryable status codes", func(t *testing.T) {
+		server, attempts := newFlakyServer(1, http.StatusInternalServerError)
+		defer server.Close()
+
+		c := NewRestyClient(nil, ClientOpts{APIUrl: server.URL, RetryPolicy: testRetryPolicy()})
+		resp, err := c.R().Get("/")
+		if err := c.CheckResponse(resp, err); err == nil {
+			t.Fatal("expected error")
+		}
+		if got := attempts.Load(); got != 1 {
+			t.Fatalf("attempts = %d, want 1", got)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		server, attempts := newFlakyServer(1, http.StatusBadGateway)
+		defer server.Close()
+
+		c := NewRestyClient(nil, ClientOpts{APIUrl: server.URL, RetryPolicy: NoRetryPolicy()})
+		resp, err := c.R().Get("/")
+		if err := c.CheckResponse(resp, err); err == nil {
+			t.Fatal("expected error")
+		}
+		if got := attempts.Load(); got != 1 {
+			t.Fatalf("attempts = %d, want 1", got)
+		}
+	})
+}
+
+func Test_parseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{
+			name:  "empty",
+			value: "",
+			want:  0,
+		},
+		{
+			name:  "seconds",
+			value: "3",
+			want:  3 * time.Second,
+		},
+		{
+			name:  "negative seconds",
+			value: "-3",
+			want:  0,
+		},
+		{
+			name:  "http date",
+			value: now.Add(90 * time.Second).Format(http.TimeFormat),
+			want:  90 * time.Second,
+		},
+		{
+			name:  "http date in the past",
+			value: now.Add(-90 * time.Second).Format(http.TimeFormat),
+			want:  0,
+		},
+		{
+			name:  "invalid",
+			value: "soon",
+			want:  0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRetryAfter(tt.value, now); got != tt.want {
+				t.Errorf("parseRetryAfter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,8 +2,8 @@ package acloudapi
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"net/http"
 	"strings"
 )
 
@@ -14,7 +14,7 @@ func (c *clientImpl) GetSilences(ctx context.Context, org, observabilityTenantSl
 		SetResult(&silences).
 		Get(fmt.Sprintf("/api/v1/orgs/%s/observability/%s/silences", org, observabilityTenantSlug))
 	if err := c.CheckResponse(response, err); err != nil {
-		if response.StatusCode() == http.StatusNotFound {
+		if errors.Is(err, ErrNotFound) {
 			return silences, nil
 		}
 		return nil, err
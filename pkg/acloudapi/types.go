@@ -1,5 +1,7 @@
 package acloudapi
 
+import "fmt"
+
 type Pageable struct {
 	Sort       Sort `json:"sort"`
 	PageNumber int  `json:"pageNumber"`
@@ -16,9 +18,23 @@ type Sort struct {
 }
 
 type Error struct {
+	Message string       `json:"message"`
+	Errors  []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError represents a field-level validation error returned by the API
+type FieldError struct {
+	Field   string `json:"field"`
 	Message string `json:"message"`
 }
 
+func (f FieldError) String() string {
+	if f.Field == "" {
+		return f.Message
+	}
+	return fmt.Sprintf("%s: %s", f.Field, f.Message)
+}
+
 type PagedResult struct {
 	Content          []interface{} `json:"content"`
 	Pageable         interface{}   `json:"pageable"`
@@ -3,7 +3,7 @@ package acloudapi
 import (
 	"context"
 	"fmt"
-	"sort"
+	"slices"
 )
 
 func (c *clientImpl) GetUpdateChannels(ctx context.Context, org string) ([]UpdateChannelResponse, error) {
@@ -22,11 +22,15 @@ func (c *clientImpl) GetUpdateChannels(ctx context.Context, org string) ([]Updat
 	return result, nil
 }
 
+// sortUpdateChannel sorts the update channels by their Kubernetes version, highest version first. Update channels
+// with an invalid version are sorted last.
 func sortUpdateChannel(updateChannels []UpdateChannelResponse) {
-	sort.SliceStable(updateChannels, func(i, j int) bool {
-		left := updateChannels[i]
-		right := updateChannels[j]
-
-		return left.KubernetesClusterVersion > right.KubernetesClusterVersion
+	slices.SortStableFunc(updateChannels, func(left, right UpdateChannelResponse) int {
+		_, leftErr := left.ParsedVersion()
+		_, rightErr := right.ParsedVersion()
+		if (leftErr == nil) != (rightErr == nil) {
+			return CompareVersions(left.KubernetesClusterVersion, right.KubernetesClusterVersion)
+		}
+		return CompareVersions(right.KubernetesClusterVersion, left.KubernetesClusterVersion)
 	})
 }
@@ -0,0 +1,352 @@
+package acloudapi
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Version is a parsed semantic version, such as a Kubernetes version. Versions can be partial: "1.31" has no patch
+// version, and includes all its patch versions when used in Includes or a VersionConstraint.
+type Version struct {
+	Major      int
+	Minor      int
+	Patch      int
+	PreRelease string
+	Build      string
+
+	// parts is the number of numeric parts of the parsed version
+	parts int
+}
+
+// ParseVersion parses a version of the form [v]major[.minor[.patch]][-prerelease][+build], e.g. "v1.31.2"
+func ParseVersion(s string) (Version, error) {
+	version := Version{}
+	rest := strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(s), "v"), "V")
+	rest, version.Build, _ = strings.Cut(rest, "+")
+	rest, version.PreRelease, _ = strings.Cut(rest, "-")
+	numbers := strings.Split(rest, ".")
+	if len(numbers) > 3 {
+		return Version{}, fmt.Errorf("invalid version %q: expected at most 3 numeric parts", s)
+	}
+	for i, number := range numbers {
+		value, err := strconv.Atoi(number)
+		if err != nil || value < 0 || number == "" || number[0] == '+' {
+			return Version{}, fmt.Errorf("invalid version %q: %q is not a number", s, number)
+		}
+		switch i {
+		case 0:
+			version.Major = value
+		case 1:
+			version.Minor = value
+		case 2:
+			version.Patch = value
+		}
+	}
+	version.parts = len(numbers)
+	return version, nil
+}
+
+// MustParseVersion is like ParseVersion but panics if the version is invalid
+func MustParseVersion(s string) Version {
+	version, err := ParseVersion(s)
+	if err != nil {
+		panic(err)
+	}
+	return version
+}
+
+// String returns the version without a "v" prefix, e.g. "1.31.2"
+func (v Version) String() string {
+	s := strconv.Itoa(v.Major)
+	if v.parts != 1 {
+		s += "." + strconv.Itoa(v.Minor)
+	}
+	if v.parts == 0 || v.parts == 3 {
+		s += "." + strconv.Itoa(v.Patch)
+	}
+	if v.PreRelease != "" {
+		s += "-" + v.PreRelease
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// MarshalText encodes the version as its String
+func (v Version) MarshalText() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText parses the version using ParseVersion
+func (v *Version) UnmarshalText(text []byte) error {
+	version, err := ParseVersion(string(text))
+	if err != nil {
+		return err
+	}
+	*v = version
+	return nil
+}
+
+// IsPartial returns true if the version has no minor or patch version, e.g. "1.31"
+func (v Version) IsPartial() bool {
+	return v.parts > 0 && v.parts < 3
+}
+
+// Compare returns -1, 0 or +1 when v is lower than, equal to or higher than other, following the semantic versioning
+// precedence: pre-releases are lower than their release, and build metadata is ignored. Missing parts of partial
+// versions are compared as 0.
+func (v Version) Compare(other Version) int {
+	if c := cmp.Compare(v.Major, other.Major); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(v.Minor, other.Minor); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(v.Patch, other.Patch); c != 0 {
+		return c
+	}
+	return comparePreRelease(v.PreRelease, other.PreRelease)
+}
+
+func comparePreRelease(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+	left, right := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(left) && i < len(right); i++ {
+		leftNumber, leftErr := strconv.Atoi(left[i])
+		rightNumber, rightErr := strconv.Atoi(right[i])
+		var c int
+		switch {
+		case leftErr == nil && rightErr == nil:
+			c = cmp.Compare(leftNumber, rightNumber)
+		case leftErr == nil:
+			c = -1 // numeric identifiers are lower than alphanumeric identifiers
+		case rightErr == nil:
+			c = 1
+		default:
+			c = strings.Compare(left[i], right[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(len(left), len(right))
+}
+
+// LessThan returns true if v is lower than other
+func (v Version) LessThan(other Version) bool {
+	return v.Compare(other) < 0
+}
+
+// Equal returns true if v and other have the same precedence
+func (v Version) Equal(other Version) bool {
+	return v.Compare(other) == 0
+}
+
+// MinorVersion returns the major and minor version of v, e.g. "1.31" for "1.31.2"
+func (v Version) MinorVersion() Version {
+	return Version{Major: v.Major, Minor: v.Minor, parts: 2}
+}
+
+// SameMinor returns true if v and other have the same major and minor version
+func (v Version) SameMinor(other Version) bool {
+	return v.Major == other.Major && v.Minor == other.Minor
+}
+
+// MinorSkew returns the number of minor versions between v and other, regardless of their order. Versions with a
+// different major version have a skew of math.MaxInt.
+func (v Version) MinorSkew(other Version) int {
+	if v.Major != other.Major {
+		return math.MaxInt
+	}
+	if v.Minor > other.Minor {
+		return v.Minor - other.Minor
+	}
+	return other.Minor - v.Minor
+}
+
+// WithinSkew returns true if other is at most maxMinorSkew minor versions away from v, e.g. to check the version skew
+// between a control plane and its nodes
+func (v Version) WithinSkew(other Version, maxMinorSkew int) bool {
+	return v.MinorSkew(other) <= maxMinorSkew
+}
+
+// Includes returns true if other equals v or, when v is partial, other is one of its minor or patch versions:
+// "1.31" includes "1.31.0" and "1.31.2", but not "1.32.0"
+func (v Version) Includes(other Version) bool {
+	if !v.IsPartial() {
+		return v.Equal(other)
+	}
+	return !other.LessThan(v) && other.LessThan(v.nextPartial())
+}
+
+// nextPartial returns the lowest version after all versions included by the partial version v
+func (v Version) nextPartial() Version {
+	if v.parts == 1 {
+		return Version{Major: v.Major + 1, parts: 3}
+	}
+	return Version{Major: v.Major, Minor: v.Minor + 1, parts: 3}
+}
+
+// CompareVersions compares two version strings like Version.Compare. Versions that cannot be parsed are higher than
+// versions that can, and are compared as strings.
+func CompareVersions(a, b string) int {
+	left, leftErr := ParseVersion(a)
+	right, rightErr := ParseVersion(b)
+	switch {
+	case leftErr == nil && rightErr == nil:
+		if c := left.Compare(right); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	case leftErr == nil:
+		return -1
+	case rightErr == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+// VersionConstraint is a set of conditions on a version, e.g. ">=1.28 <1.30". Conditions separated by spaces or
+// commas must all match, alternatives are separated by "||". The operators are =, !=, >, >=, <, <= and ~, where
+// ~1.28.3 matches patch versions from 1.28.3. A version without operator must be equal, and partial versions include
+// all their minor and patch versions: "1.28" matches "1.28.5" and "<=1.29" matches "1.29.3".
+type VersionConstraint struct {
+	raw          string
+	alternatives [][]versionCondition
+}
+
+type versionCondition struct {
+	operator string
+	version  Version
+}
+
+// versionOperators are the operators of a VersionConstraint, longest first
+var versionOperators = []string{">=", "<=", "!=", "==", ">", "<", "=", "~"}
+
+// ParseVersionConstraint parses a version constraint, see VersionConstraint
+func ParseVersionConstraint(s string) (VersionConstraint, error) {
+	constraint := VersionConstraint{raw: strings.TrimSpace(s)}
+	for _, alternative := range strings.Split(s, "||") {
+		tokens := strings.Fields(strings.ReplaceAll(alternative, ",", " "))
+		if len(tokens) == 0 {
+			return VersionConstraint{}, fmt.Errorf("invalid version constraint %q: empty condition", s)
+		}
+		var conditions []versionCondition
+		for i := 0; i < len(tokens); i++ {
+			token := tokens[i]
+			operator := "="
+			for _, op := range versionOperators {
+				if strings.HasPrefix(token, op) {
+					operator, token = op, strings.TrimPrefix(token, op)
+					break
+				}
+			}
+			// allow a space between the operator and the version, e.g. ">= 1.28"
+			if token == "" && i+1 < len(tokens) {
+				i++
+				token = tokens[i]
+			}
+			version, err := ParseVersion(token)
+			if err != nil {
+				return VersionConstraint{}, fmt.Errorf("invalid version constraint %q: %w", s, err)
+			}
+			if operator == "==" {
+				operator = "="
+			}
+			conditions = append(conditions, versionCondition{operator: operator, version: version})
+		}
+		constraint.alternatives = append(constraint.alternatives, conditions)
+	}
+	return constraint, nil
+}
+
+// MustParseVersionConstraint is like ParseVersionConstraint but panics if the constraint is invalid
+func MustParseVersionConstraint(s string) VersionConstraint {
+	constraint, err := ParseVersionConstraint(s)
+	if err != nil {
+		panic(err)
+	}
+	return constraint
+}
+
+func (c VersionConstraint) String() string {
+	return c.raw
+}
+
+// Check returns true if the version matches all conditions of any of the alternatives
+func (c VersionConstraint) Check(version Version) bool {
+	for _, conditions := range c.alternatives {
+		matches := true
+		for _, condition := range conditions {
+			if !condition.check(version) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
+
+func (c versionCondition) check(version Version) bool {
+	switch c.operator {
+	case "=":
+		return c.version.Includes(version)
+	case "!=":
+		return !c.version.Includes(version)
+	case ">":
+		if c.version.IsPartial() {
+			return !version.LessThan(c.version.nextPartial())
+		}
+		return c.version.LessThan(version)
+	case ">=":
+		return !version.LessThan(c.version)
+	case "<":
+		return version.LessThan(c.version)
+	case "<=":
+		if c.version.IsPartial() {
+			return version.LessThan(c.version.nextPartial())
+		}
+		return !c.version.LessThan(version)
+	case "~":
+		if c.version.IsPartial() {
+			return c.version.Includes(version)
+		}
+		return !version.LessThan(c.version) && version.LessThan(c.version.MinorVersion().nextPartial())
+	}
+	return false
+}
+
+// ParsedVersion parses the Version of the cluster version
+func (c ClusterVersion) ParsedVersion() (Version, error) {
+	return ParseVersion(c.Version)
+}
+
+// ParsedVersion parses the KubernetesClusterVersion of the update channel
+func (u UpdateChannelResponse) ParsedVersion() (Version, error) {
+	return ParseVersion(u.KubernetesClusterVersion)
+}
+
+// ParsedVersion parses the Version of the cluster version
+func (a AdminClusterVersion) ParsedVersion() (Version, error) {
+	return ParseVersion(a.Version)
+}
+
+// ParsedKubernetesVersion parses the KubernetesVersion of the cluster version
+func (a AdminClusterVersion) ParsedKubernetesVersion() (Version, error) {
+	return ParseVersion(a.KubernetesVersion)
+}
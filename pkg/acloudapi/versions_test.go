@@ -0,0 +1,135 @@
+package acloudapi
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		partial bool
+		wantErr bool
+	}{
+		{input: "1.31.2", want: "1.31.2"},
+		{input: "v1.31.2", want: "1.31.2"},
+		{input: "1.31", want: "1.31", partial: true},
+		{input: "1", want: "1", partial: true},
+		{input: "1.31.0-rc.1+avisi.2", want: "1.31.0-rc.1+avisi.2"},
+		{input: "", wantErr: true},
+		{input: "1.31.2.4", wantErr: true},
+		{input: "1.x", wantErr: true},
+		{input: "1.-1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			version, err := ParseVersion(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %s", version)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if version.String() != tt.want || version.IsPartial() != tt.partial {
+				t.Fatalf("got %s (partial %t), want %s (partial %t)", version, version.IsPartial(), tt.want, tt.partial)
+			}
+		})
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	versions := []string{"1.29.0", "invalid", "1.9.3", "1.30.0-rc.10", "1.30.0", "1.30.0-rc.2", "1.30.0-beta", "1.10"}
+	slices.SortFunc(versions, CompareVersions)
+	want := []string{"1.9.3", "1.10", "1.29.0", "1.30.0-beta", "1.30.0-rc.2", "1.30.0-rc.10", "1.30.0", "invalid"}
+	if !slices.Equal(versions, want) {
+		t.Fatalf("got %v, want %v", versions, want)
+	}
+
+	updateChannels := []UpdateChannelResponse{
+		{Name: "stable", KubernetesClusterVersion: "1.9"},
+		{Name: "unknown"},
+		{Name: "rapid", KubernetesClusterVersion: "1.29"},
+	}
+	sortUpdateChannel(updateChannels)
+	if updateChannels[0].Name != "rapid" || updateChannels[1].Name != "stable" || updateChannels[2].Name != "unknown" {
+		t.Fatalf("unexpected order %+v", updateChannels)
+	}
+}
+
+func TestVersionSkew(t *testing.T) {
+	controlPlane := MustParseVersion("1.31.2")
+	tests := []struct {
+		version   string
+		skew      int
+		sameMinor bool
+	}{
+		{version: "1.31.0", skew: 0, sameMinor: true},
+		{version: "1.29.7", skew: 2},
+		{version: "1.33.0", skew: 2},
+		{version: "2.31.2", skew: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			version := MustParseVersion(tt.version)
+			if tt.skew < 0 {
+				if controlPlane.WithinSkew(version, 3) {
+					t.Fatalf("expected a different major version to be out of skew")
+				}
+				return
+			}
+			if skew := controlPlane.MinorSkew(version); skew != tt.skew {
+				t.Fatalf("skew = %d, want %d", skew, tt.skew)
+			}
+			if controlPlane.SameMinor(version) != tt.sameMinor || controlPlane.MinorVersion().Includes(version) != tt.sameMinor {
+				t.Fatalf("unexpected minor comparison of %s and %s", controlPlane, version)
+			}
+		})
+	}
+}
+
+func TestVersionConstraint(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		want       bool
+	}{
+		{constraint: ">=1.28 <1.30", version: "1.28.0", want: true},
+		{constraint: ">=1.28 <1.30", version: "1.29.9", want: true},
+		{constraint: ">=1.28 <1.30", version: "1.30.0", want: false},
+		{constraint: ">=1.28 <1.30", version: "1.9.0", want: false},
+		{constraint: ">= 1.28, < 1.30", version: "1.29.1", want: true},
+		{constraint: "1.31", version: "1.31.4", want: true},
+		{constraint: "1.31", version: "1.32.0", want: false},
+		{constraint: "=1.31.4", version: "1.31.4+avisi.1", want: true},
+		{constraint: "!=1.31", version: "1.31.4", want: false},
+		{constraint: ">1.30", version: "1.30.9", want: false},
+		{constraint: ">1.30", version: "1.31.0", want: true},
+		{constraint: "<=1.29", version: "1.29.3", want: true},
+		{constraint: "~1.28.3", version: "1.28.9", want: true},
+		{constraint: "~1.28.3", version: "1.28.2", want: false},
+		{constraint: "~1.28.3", version: "1.29.0", want: false},
+		{constraint: "<1.28 || >=1.31", version: "1.31.1", want: true},
+		{constraint: "<1.28 || >=1.31", version: "1.29.0", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.constraint+" "+tt.version, func(t *testing.T) {
+			constraint, err := ParseVersionConstraint(tt.constraint)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := constraint.Check(MustParseVersion(tt.version)); got != tt.want {
+				t.Fatalf("Check(%s) = %t, want %t", tt.version, got, tt.want)
+			}
+		})
+	}
+
+	for _, invalid := range []string{"", ">=1.28 ||", ">=a.b"} {
+		if _, err := ParseVersionConstraint(invalid); err == nil {
+			t.Errorf("expected an error for %q", invalid)
+		}
+	}
+}
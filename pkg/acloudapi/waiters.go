@@ -0,0 +1,243 @@
+package acloudapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultWaitPollInterval is the default interval between two polls of a waiter
+	DefaultWaitPollInterval = 10 * time.Second
+	// DefaultWaitTimeout is the default maximum duration of a waiter
+	DefaultWaitTimeout = 30 * time.Minute
+)
+
+// ErrTerminalStatus is returned by the waiters when a cluster or node pool reached a status from which it will not
+// reach the awaited state, e.g. it is being deleted
+var ErrTerminalStatus = errors.New("terminal status")
+
+// WaitOpts configures how a waiter polls
+type WaitOpts struct {
+	// PollInterval is the interval between two polls, defaults to DefaultWaitPollInterval
+	PollInterval time.Duration
+	// Timeout is the maximum duration of the waiter, defaults to DefaultWaitTimeout. A deadline of the context also applies
+	Timeout time.Duration
+}
+
+// WaitForClusterOpts are the options of WaitForCluster and WaitForClusterDeleted
+type WaitForClusterOpts struct {
+	WaitOpts
+	// OnProgress is called with the first polled cluster, and every time its Status or ProvisionStatus changes
+	OnProgress func(cluster Cluster)
+}
+
+// WaitForNodePoolOpts are the options of WaitForNodePool and WaitForNodePoolDeleted
+type WaitForNodePoolOpts struct {
+	WaitOpts
+	// OnProgress is called with the first polled node pool, and every time its ProvisionStatus changes
+	OnProgress func(nodePool NodePool)
+}
+
+func mergeWaitOpts(opts WaitOpts, merged WaitOpts) WaitOpts {
+	if opts.PollInterval > 0 {
+		merged.PollInterval = opts.PollInterval
+	}
+	if opts.Timeout > 0 {
+		merged.Timeout = opts.Timeout
+	}
+	return merged
+}
+
+func mergeWaitForClusterOpts(opts []WaitForClusterOpts) WaitForClusterOpts {
+	merged := WaitForClusterOpts{WaitOpts: WaitOpts{PollInterval: DefaultWaitPollInterval, Timeout: DefaultWaitTimeout}}
+	for _, opt := range opts {
+		merged.WaitOpts = mergeWaitOpts(opt.WaitOpts, merged.WaitOpts)
+		if opt.OnProgress != nil {
+			merged.OnProgress = opt.OnProgress
+		}
+	}
+	return merged
+}
+
+func mergeWaitForNodePoolOpts(opts []WaitForNodePoolOpts) WaitForNodePoolOpts {
+	merged := WaitForNodePoolOpts{WaitOpts: WaitOpts{PollInterval: DefaultWaitPollInterval, Timeout: DefaultWaitTimeout}}
+	for _, opt := range opts {
+		merged.WaitOpts = mergeWaitOpts(opt.WaitOpts, merged.WaitOpts)
+		if opt.OnProgress != nil {
+			merged.OnProgress = opt.OnProgress
+		}
+	}
+	return merged
+}
+
+// WaitForCluster polls the cluster until it is provisioned (ProvisionStatus DONE) and its Status equals its
+// DesiredStatus, e.g. after CreateCluster or UpdateCluster. The last polled cluster is returned.
+//
+// An error wrapping ErrTerminalStatus is returned when the cluster is deleted while it should not be, an error
+// wrapping context.DeadlineExceeded when the timeout expires, and the error of the context when it is cancelled.
+func WaitForCluster(ctx context.Context, client ClusterAPI, org, env, clusterSlug string, opts ...WaitForClusterOpts) (*Cluster, error) {
+	mergedOpts := mergeWaitForClusterOpts(opts)
+	return poll(ctx, mergedOpts.WaitOpts, func(ctx context.Context) (*Cluster, bool, error) {
+		cluster, err := client.GetCluster(ctx, org, env, clusterSlug)
+		if err != nil {
+			return nil, false, err
+		}
+		if err := clusterTerminalStatus(cluster); err != nil {
+			return cluster, false, err
+		}
+		return cluster, cluster.ProvisionStatus == DONE && cluster.Status == cluster.DesiredStatus, nil
+	}, clusterProgress(mergedOpts.OnProgress), func(cluster *Cluster) string {
+		return fmt.Sprintf("cluster %s/%s/%s (status %s, desired status %s, provision status %s)",
+			org, env, clusterSlug, cluster.Status, cluster.DesiredStatus, cluster.ProvisionStatus)
+	})
+}
+
+// WaitForClusterDeleted polls the cluster until it is deleted, e.g. after DeleteCluster
+func WaitForClusterDeleted(ctx context.Context, client ClusterAPI, org, env, clusterSlug string, opts ...WaitForClusterOpts) error {
+	mergedOpts := mergeWaitForClusterOpts(opts)
+	_, err := poll(ctx, mergedOpts.WaitOpts, func(ctx context.Context) (*Cluster, bool, error) {
+		cluster, err := client.GetCluster(ctx, org, env, clusterSlug)
+		if errors.Is(err, ErrNotFound) {
+			return nil, true, nil
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		return cluster, cluster.Status == ClusterStatusDeleted, nil
+	}, clusterProgress(mergedOpts.OnProgress), func(cluster *Cluster) string {
+		return fmt.Sprintf("deletion of cluster %s/%s/%s (status %s)", org, env, clusterSlug, cluster.Status)
+	})
+	return err
+}
+
+// clusterTerminalStatus returns an error if the cluster will not reach its desired status
+func clusterTerminalStatus(cluster *Cluster) error {
+	if cluster.DesiredStatus == ClusterStatusDeleted || (cluster.Status != ClusterStatusDeleting && cluster.Status != ClusterStatusDeleted) {
+		return nil
+	}
+	return fmt.Errorf("cluster %s %w %s", cluster.Identifier(), ErrTerminalStatus, cluster.Status)
+}
+
+func clusterProgress(onProgress func(cluster Cluster)) func(previous, current *Cluster) {
+	return func(previous, current *Cluster) {
+		if onProgress == nil || current == nil {
+			return
+		}
+		if previous == nil || previous.Status != current.Status || previous.ProvisionStatus != current.ProvisionStatus {
+			onProgress(*current)
+		}
+	}
+}
+
+// WaitForNodePool polls the node pool until it is provisioned, e.g. after CreateNodePool or UpdateNodePool.
+// The last polled node pool is returned.
+//
+// An error wrapping ErrTerminalStatus is returned when the node pool is deleted, an error wrapping
+// context.DeadlineExceeded when the timeout expires, and the error of the context when it is cancelled.
+func WaitForNodePool(ctx context.Context, client NodePoolsAPI, cluster Cluster, nodePoolID int, opts ...WaitForNodePoolOpts) (*NodePool, error) {
+	mergedOpts := mergeWaitForNodePoolOpts(opts)
+	return poll(ctx, mergedOpts.WaitOpts, func(ctx context.Context) (*NodePool, bool, error) {
+		nodePool, err := getNodePool(ctx, client, cluster, nodePoolID)
+		if err != nil {
+			return nil, false, err
+		}
+		switch nodePool.ProvisionStatus {
+		case NodePoolStatusDeleting, NodePoolStatusDeleted:
+			return nodePool, false, fmt.Errorf("node pool %s %w %s", nodePool.FullIdentifier(), ErrTerminalStatus, nodePool.ProvisionStatus)
+		}
+		return nodePool, nodePool.ProvisionStatus == NodePoolStatusProvisioned, nil
+	}, nodePoolProgress(mergedOpts.OnProgress), func(nodePool *NodePool) string {
+		return fmt.Sprintf("node pool %d of cluster %s (provision status %s)", nodePoolID, cluster.Identifier(), nodePool.ProvisionStatus)
+	})
+}
+
+// WaitForNodePoolDeleted polls the node pool until it is deleted, e.g. after DeleteNodePool
+func WaitForNodePoolDeleted(ctx context.Context, client NodePoolsAPI, cluster Cluster, nodePoolID int, opts ...WaitForNodePoolOpts) error {
+	mergedOpts := mergeWaitForNodePoolOpts(opts)
+	_, err := poll(ctx, mergedOpts.WaitOpts, func(ctx context.Context) (*NodePool, bool, error) {
+		nodePool, err := getNodePool(ctx, client, cluster, nodePoolID)
+		if errors.Is(err, ErrNotFound) {
+			return nil, true, nil
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		return nodePool, nodePool.ProvisionStatus == NodePoolStatusDeleted, nil
+	}, nodePoolProgress(mergedOpts.OnProgress), func(nodePool *NodePool) string {
+		return fmt.Sprintf("deletion of node pool %d of cluster %s (provision status %s)", nodePoolID, cluster.Identifier(), nodePool.ProvisionStatus)
+	})
+	return err
+}
+
+// getNodePool returns a node pool of the cluster by its ID, or an error wrapping ErrNotFound if it does not exist
+func getNodePool(ctx context.Context, client NodePoolsAPI, cluster Cluster, nodePoolID int) (*NodePool, error) {
+	nodePools, err := client.GetNodePoolsByCluster(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	for i := range nodePools {
+		if nodePools[i].ID == nodePoolID {
+			return &nodePools[i], nil
+		}
+	}
+	return nil, fmt.Errorf("node pool %d of cluster %s %w", nodePoolID, cluster.Identifier(), ErrNotFound)
+}
+
+func nodePoolProgress(onProgress func(nodePool NodePool)) func(previous, current *NodePool) {
+	return func(previous, current *NodePool) {
+		if onProgress == nil || current == nil {
+			return
+		}
+		if previous == nil || previous.ProvisionStatus != current.ProvisionStatus {
+			onProgress(*current)
+		}
+	}
+}
+
+// poll calls check every PollInterval until it returns done or an error, the timeout expires or the context is
+// cancelled. progress is called after every poll with the previous and current resource, describe describes the last
+// polled resource in the timeout or cancellation error.
+func poll[T any](
+	ctx context.Context,
+	opts WaitOpts,
+	check func(ctx context.Context) (resource *T, done bool, err error),
+	progress func(previous, current *T),
+	describe func(resource *T) string,
+) (*T, error) {
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	var previous *T
+	ticker := time.NewTicker(opts.PollInterval)
+	defer ticker.Stop()
+	for {
+		current, done, err := check(ctx)
+		if err != nil && ctx.Err() == nil {
+			return current, err
+		}
+		if err == nil {
+			progress(previous, current)
+			if done {
+				return current, nil
+			}
+			if current != nil {
+				previous = current
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			last := previous
+			if last == nil {
+				last = new(T)
+			}
+			if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return previous, fmt.Errorf("cancelled waiting for %s: %w", describe(last), ctx.Err())
+			}
+			return previous, fmt.Errorf("timed out waiting for %s: %w", describe(last), ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package acloudapi_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/avisi-cloud/go-client/pkg/acloudapi"
+	"github.com/avisi-cloud/go-client/pkg/acloudapi/acloudapitest"
+)
+
+var fastPolling = acloudapi.WaitOpts{PollInterval: time.Millisecond, Timeout: 5 * time.Second}
+
+func newWaiterTestServer(t *testing.T, opts acloudapitest.Opts) (*acloudapitest.Server, acloudapi.Client) {
+	t.Helper()
+	server := acloudapitest.NewServer(opts)
+	t.Cleanup(server.Close)
+	server.AddOrganisation(acloudapi.Organisation{Slug: "org1"})
+	server.AddEnvironment("org1", acloudapi.Environment{Slug: "env1"})
+	return server, server.Client()
+}
+
+func TestWaitForCluster(t *testing.T) {
+	_, client := newWaiterTestServer(t, acloudapitest.Opts{})
+	ctx := context.Background()
+	if _, err := client.CreateCluster(ctx, "org1", "env1", acloudapi.CreateCluster{Name: "cluster1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var progress []acloudapi.ClusterProvisionStatus
+	cluster, err := acloudapi.WaitForCluster(ctx, client, "org1", "env1", "cluster1", acloudapi.WaitForClusterOpts{
+		WaitOpts:   fastPolling,
+		OnProgress: func(cluster acloudapi.Cluster) { progress = append(progress, cluster.ProvisionStatus) },
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cluster.Status != acloudapi.ClusterStatusRunning || cluster.ProvisionStatus != acloudapi.DONE {
+		t.Fatalf("unexpected cluster status %q, provision status %q", cluster.Status, cluster.ProvisionStatus)
+	}
+	want := []acloudapi.ClusterProvisionStatus{acloudapi.OIDC_PROVISIONED, acloudapi.CLUSTER_PROVISIONED, acloudapi.INITIAL_NODE_POOLS_PROVISIONED, acloudapi.INITIAL_ADDONS_PROVISIONED, acloudapi.DONE}
+	if !slices.Equal(progress, want) {
+		t.Fatalf("progress = %v, want %v", progress, want)
+	}
+
+	deleted := acloudapi.ClusterStatusDeleted
+	if err := client.DeleteCluster(ctx, "org1", "env1", "cluster1", acloudapi.UpdateCluster{Status: &deleted}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := acloudapi.WaitForClusterDeleted(ctx, client, "org1", "env1", "cluster1", acloudapi.WaitForClusterOpts{WaitOpts: fastPolling}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestWaitForClusterErrors(t *testing.T) {
+	server, client := newWaiterTestServer(t, acloudapitest.Opts{ManualTransitions: true})
+	ctx := context.Background()
+	server.AddCluster(acloudapi.Cluster{CustomerSlug: "org1", EnvironmentSlug: "env1", Slug: "deleting", Status: acloudapi.ClusterStatusDeleting})
+	if _, err := client.CreateCluster(ctx, "org1", "env1", acloudapi.CreateCluster{Name: "pending"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		cluster string
+		opts    acloudapi.WaitOpts
+		wantErr error
+	}{
+		{name: "terminal status", cluster: "deleting", opts: fastPolling, wantErr: acloudapi.ErrTerminalStatus},
+		{name: "timeout", cluster: "pending", opts: acloudapi.WaitOpts{PollInterval: time.Millisecond, Timeout: 20 * time.Millisecond}, wantErr: context.DeadlineExceeded},
+		{name: "not found", cluster: "missing", opts: fastPolling, wantErr: acloudapi.ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := acloudapi.WaitForCluster(ctx, client, "org1", "env1", tt.cluster, acloudapi.WaitForClusterOpts{WaitOpts: tt.opts})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		time.AfterFunc(20*time.Millisecond, cancel)
+		_, err := acloudapi.WaitForCluster(ctx, client, "org1", "env1", "pending", acloudapi.WaitForClusterOpts{WaitOpts: fastPolling})
+		if !errors.Is(err, context.Canceled) || !strings.HasPrefix(err.Error(), "cancelled waiting for cluster org1/env1/pending") {
+			t.Fatalf("expected a cancellation error, got %v", err)
+		}
+	})
+}
+
+func TestWaitForNodePool(t *testing.T) {
+	server, client := newWaiterTestServer(t, acloudapitest.Opts{})
+	ctx := context.Background()
+	cluster := server.AddCluster(acloudapi.Cluster{CustomerSlug: "org1", EnvironmentSlug: "env1", Slug: "cluster1"})
+	deleting := server.AddNodePool(cluster.Identity, acloudapi.NodePool{Name: "deleting", ProvisionStatus: acloudapi.NodePoolStatusDeleting})
+
+	nodePool, err := client.CreateNodePool(ctx, cluster, acloudapi.CreateNodePool{Name: "workers", NodeSize: "small", MinSize: 1, MaxSize: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var progress []string
+	waited, err := acloudapi.WaitForNodePool(ctx, client, cluster, nodePool.ID, acloudapi.WaitForNodePoolOpts{
+		WaitOpts:   fastPolling,
+		OnProgress: func(nodePool acloudapi.NodePool) { progress = append(progress, nodePool.ProvisionStatus) },
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if waited.ProvisionStatus != acloudapi.NodePoolStatusProvisioned || !slices.Equal(progress, []string{acloudapi.NodePoolStatusProvisioned}) {
+		t.Fatalf("unexpected provision status %q, progress %v", waited.ProvisionStatus, progress)
+	}
+
+	if _, err := acloudapi.WaitForNodePool(ctx, client, cluster, deleting.ID, acloudapi.WaitForNodePoolOpts{WaitOpts: fastPolling}); !errors.Is(err, acloudapi.ErrTerminalStatus) {
+		t.Fatalf("expected ErrTerminalStatus, got %v", err)
+	}
+
+	if err := client.DeleteNodePool(ctx, cluster, nodePool.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := acloudapi.WaitForNodePoolDeleted(ctx, client, cluster, nodePool.ID, acloudapi.WaitForNodePoolOpts{WaitOpts: fastPolling}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
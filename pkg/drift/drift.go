@@ -0,0 +1,220 @@
+// Package drift reports the drift between the expected settings of clusters and their live settings, without changing
+// anything. The report can be written as JSON, or as a JUnit XML report to fail a CI job on drift:
+//
+//	expectations, err := drift.LoadExpectations("expectations.yaml")
+//	if err != nil {
+//		return err
+//	}
+//	report := drift.Check(ctx, client, expectations.Clusters)
+//	if err := report.WriteJUnit(os.Stdout); err != nil {
+//		return err
+//	}
+//	if report.HasDrift() || report.HasErrors() {
+//		os.Exit(1)
+//	}
+package drift
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/avisi-cloud/go-client/pkg/acloudapi"
+)
+
+// Expectations are the expected settings of clusters, e.g. loaded from a YAML file:
+//
+//	Clusters:
+//	  - Organisation: my-organisation
+//	    Environment: production
+//	    Cluster: web
+//	    Version: "1.31"
+//	    UpdateChannel: stable
+//	    HighlyAvailable: true
+//	    IPWhitelist:
+//	      - Cidr: 10.0.0.0/8
+//	    Addons:
+//	      cert-manager:
+//	        Enabled: true
+type Expectations struct {
+	Clusters []ClusterExpectation `yaml:"Clusters"`
+}
+
+// ClusterExpectation are the expected settings of a cluster. Only the settings that are set are checked.
+type ClusterExpectation struct {
+	// Organisation, Environment and Cluster are the slugs of the cluster
+	Organisation string `yaml:"Organisation"`
+	Environment  string `yaml:"Environment"`
+	Cluster      string `yaml:"Cluster"`
+
+	// Version is the expected Kubernetes version or version constraint (e.g. ">=1.30 <1.32"), a minor version (e.g.
+	// 1.31) matches all its patch versions
+	Version                     *string `yaml:"Version,omitempty"`
+	UpdateChannel               *string `yaml:"UpdateChannel,omitempty"`
+	HighlyAvailable             *bool   `yaml:"HighlyAvailable,omitempty"`
+	EnableNetworkEncryption     *bool   `yaml:"EnableNetworkEncryption,omitempty"`
+	PodSecurityStandardsProfile *string `yaml:"PodSecurityStandardsProfile,omitempty"`
+	// IPWhitelist is compared by CIDR, regardless of order and descriptions
+	IPWhitelist []acloudapi.IPWhitelistEntry `yaml:"IPWhitelist,omitempty"`
+	// Addons are compared per addon, addons that are not listed are not checked
+	Addons             map[string]acloudapi.APIAddon `yaml:"Addons,omitempty"`
+	AutoscalerSettings *acloudapi.AutoscalerSettings `yaml:"ClusterAutoscalerSettings,omitempty"`
+	// MaintenanceScheduleIdentity is the identity of the expected maintenance schedule, "" expects no schedule
+	MaintenanceScheduleIdentity *string `yaml:"MaintenanceScheduleIdentity,omitempty"`
+}
+
+// Identifier returns the identifier of the expected cluster, see Cluster.Identifier
+func (e ClusterExpectation) Identifier() string {
+	return fmt.Sprintf("%s/%s/%s", e.Organisation, e.Environment, e.Cluster)
+}
+
+// LoadExpectations reads the expected settings of clusters from a YAML file
+func LoadExpectations(path string) (*Expectations, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	expectations := Expectations{}
+	if err := yaml.Unmarshal(data, &expectations); err != nil {
+		return nil, fmt.Errorf("invalid expectations %s: %w", path, err)
+	}
+	for _, cluster := range expectations.Clusters {
+		if cluster.Organisation == "" || cluster.Environment == "" || cluster.Cluster == "" {
+			return nil, fmt.Errorf("invalid expectations %s: organisation, environment and cluster are required", path)
+		}
+	}
+	return &expectations, nil
+}
+
+// Report is the drift report of one or more clusters
+type Report struct {
+	GeneratedAt time.Time       `json:"generatedAt"`
+	Clusters    []ClusterReport `json:"clusters"`
+}
+
+// ClusterReport is the drift report of a single cluster
+type ClusterReport struct {
+	// Cluster is the identifier of the cluster, e.g. "org/env/cluster"
+	Cluster string `json:"cluster"`
+	// Error is set when the cluster could not be checked, e.g. because it does not exist
+	Error  string        `json:"error,omitempty"`
+	Fields []FieldResult `json:"fields"`
+}
+
+// FieldResult is the expected and actual value of a checked setting
+type FieldResult struct {
+	Field    string `json:"field"`
+	Expected any    `json:"expected"`
+	Actual   any    `json:"actual"`
+	Drifted  bool   `json:"drifted"`
+}
+
+// HasDrift returns true if any setting of any cluster drifted
+func (r Report) HasDrift() bool {
+	return slices.ContainsFunc(r.Clusters, func(cluster ClusterReport) bool { return cluster.HasDrift() })
+}
+
+// HasErrors returns true if any cluster could not be checked
+func (r Report) HasErrors() bool {
+	return slices.ContainsFunc(r.Clusters, func(cluster ClusterReport) bool { return cluster.Error != "" })
+}
+
+// HasDrift returns true if any setting of the cluster drifted
+func (r ClusterReport) HasDrift() bool {
+	return slices.ContainsFunc(r.Fields, func(field FieldResult) bool { return field.Drifted })
+}
+
+// Check gets every expected cluster including its details, and compares its settings. Clusters that cannot be
+// retrieved are reported with an Error instead of failing the whole report.
+func Check(ctx context.Context, client acloudapi.ClusterAPI, expectations []ClusterExpectation) Report {
+	report := Report{GeneratedAt: time.Now().UTC(), Clusters: make([]ClusterReport, 0, len(expectations))}
+	for _, expectation := range expectations {
+		cluster, err := client.GetCluster(ctx, expectation.Organisation, expectation.Environment, expectation.Cluster, acloudapi.GetClusterOpts{IncludeDetails: acloudapi.True()})
+		if err != nil {
+			report.Clusters = append(report.Clusters, ClusterReport{Cluster: expectation.Identifier(), Error: err.Error(), Fields: []FieldResult{}})
+			continue
+		}
+		report.Clusters = append(report.Clusters, Compare(expectation, *cluster))
+	}
+	return report
+}
+
+// Compare compares the expected settings with the settings of the cluster
+func Compare(expectation ClusterExpectation, cluster acloudapi.Cluster) ClusterReport {
+	report := ClusterReport{Cluster: expectation.Identifier(), Fields: []FieldResult{}}
+	check := func(field string, expected, actual any, drifted bool) {
+		report.Fields = append(report.Fields, FieldResult{Field: field, Expected: expected, Actual: actual, Drifted: drifted})
+	}
+
+	if expectation.Version != nil {
+		check("Version", *expectation.Version, cluster.Version, !versionMatches(*expectation.Version, cluster.Version))
+	}
+	if expectation.UpdateChannel != nil {
+		actual := ""
+		if cluster.UpdateChannel != nil {
+			actual = cluster.UpdateChannel.Name
+		}
+		check("UpdateChannel", *expectation.UpdateChannel, actual, *expectation.UpdateChannel != actual)
+	}
+	if expectation.HighlyAvailable != nil {
+		check("HighlyAvailable", *expectation.HighlyAvailable, cluster.HighlyAvailable, *expectation.HighlyAvailable != cluster.HighlyAvailable)
+	}
+	if expectation.EnableNetworkEncryption != nil {
+		check("EnableNetworkEncryption", *expectation.EnableNetworkEncryption, cluster.EnableNetworkEncryption, *expectation.EnableNetworkEncryption != cluster.EnableNetworkEncryption)
+	}
+	if expectation.PodSecurityStandardsProfile != nil {
+		check("PodSecurityStandardsProfile", *expectation.PodSecurityStandardsProfile, cluster.PodSecurityStandardsProfile, *expectation.PodSecurityStandardsProfile != cluster.PodSecurityStandardsProfile)
+	}
+	if expectation.IPWhitelist != nil {
+		expected := make([]string, 0, len(expectation.IPWhitelist))
+		for _, entry := range expectation.IPWhitelist {
+			expected = append(expected, entry.Cidr)
+		}
+		actual := make([]string, 0, len(cluster.IPWhitelist))
+		for _, entry := range cluster.IPWhitelist {
+			actual = append(actual, entry.Cidr)
+		}
+		slices.Sort(expected)
+		slices.Sort(actual)
+		check("IPWhitelist", expected, actual, !slices.Equal(expected, actual))
+	}
+	for _, name := range slices.Sorted(maps.Keys(expectation.Addons)) {
+		expected := expectation.Addons[name]
+		actual, ok := cluster.Addons[name]
+		var actualValue any
+		if ok {
+			actualValue = actual
+		}
+		check("Addons."+name, expected, actualValue, !ok || expected.Enabled != actual.Enabled || !maps.Equal(expected.CustomValues, actual.CustomValues))
+	}
+	if expectation.AutoscalerSettings != nil {
+		check("ClusterAutoscalerSettings", expectation.AutoscalerSettings, cluster.AutoscalerSettings, !reflect.DeepEqual(expectation.AutoscalerSettings, cluster.AutoscalerSettings))
+	}
+	if expectation.MaintenanceScheduleIdentity != nil {
+		actual := ""
+		if cluster.MaintenanceSchedule != nil {
+			actual = cluster.MaintenanceSchedule.Identity
+		}
+		check("MaintenanceScheduleIdentity", *expectation.MaintenanceScheduleIdentity, actual, *expectation.MaintenanceScheduleIdentity != actual)
+	}
+	return report
+}
+
+// versionMatches returns true if the version matches the expected version constraint, e.g. "1.31" or ">=1.30 <1.32"
+func versionMatches(expected, version string) bool {
+	constraint, err := acloudapi.ParseVersionConstraint(expected)
+	if err != nil {
+		return expected == version
+	}
+	parsed, err := acloudapi.ParseVersion(version)
+	if err != nil {
+		return false
+	}
+	return constraint.Check(parsed)
+}
@@ -0,0 +1,125 @@
+package drift
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/avisi-cloud/go-client/pkg/acloudapi"
+	"github.com/avisi-cloud/go-client/pkg/acloudapi/acloudapitest"
+)
+
+const testExpectations = `
+Clusters:
+  - Organisation: org1
+    Environment: env1
+    Cluster: web
+    Version: "1.31"
+    UpdateChannel: stable
+    HighlyAvailable: true
+    EnableNetworkEncryption: true
+    PodSecurityStandardsProfile: restricted
+    IPWhitelist:
+      - Cidr: 10.0.0.0/8
+      - Cidr: 192.168.0.0/16
+    Addons:
+      cert-manager:
+        Enabled: true
+      ingress-nginx:
+        Enabled: true
+    MaintenanceScheduleIdentity: ""
+  - Organisation: org1
+    Environment: env1
+    Cluster: missing
+    HighlyAvailable: true
+`
+
+func TestCheck(t *testing.T) {
+	server := acloudapitest.NewServer(acloudapitest.Opts{})
+	defer server.Close()
+	server.AddOrganisation(acloudapi.Organisation{Slug: "org1"})
+	server.AddEnvironment("org1", acloudapi.Environment{Slug: "env1"})
+	server.AddCluster(acloudapi.Cluster{
+		CustomerSlug:            "org1",
+		EnvironmentSlug:         "env1",
+		Slug:                    "web",
+		Version:                 "1.31.2",
+		UpdateChannel:           &acloudapi.UpdateChannelResponse{Name: "stable"},
+		HighlyAvailable:         false,
+		EnableNetworkEncryption: true,
+		IPWhitelist:             []acloudapi.IpWhitelistResponse{{Cidr: "192.168.0.0/16"}, {Cidr: "10.0.0.0/8"}},
+		Addons:                  map[string]acloudapi.APIAddon{"cert-manager": {Enabled: true}},
+	})
+
+	path := filepath.Join(t.TempDir(), "expectations.yaml")
+	if err := os.WriteFile(path, []byte(testExpectations), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	expectations, err := LoadExpectations(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	report := Check(context.Background(), server.Client(), expectations.Clusters)
+	if !report.HasDrift() || !report.HasErrors() {
+		t.Fatalf("expected drift and errors, got %+v", report)
+	}
+	drifted := map[string]bool{}
+	for _, field := range report.Clusters[0].Fields {
+		drifted[field.Field] = field.Drifted
+	}
+	want := map[string]bool{
+		"Version":                     false,
+		"UpdateChannel":               false,
+		"HighlyAvailable":             true,
+		"EnableNetworkEncryption":     false,
+		"PodSecurityStandardsProfile": true,
+		"IPWhitelist":                 false,
+		"Addons.cert-manager":         false,
+		"Addons.ingress-nginx":        true,
+		"MaintenanceScheduleIdentity": false,
+	}
+	if len(drifted) != len(want) {
+		t.Fatalf("checked fields = %v, want %v", drifted, want)
+	}
+	for field, wantDrifted := range want {
+		if drifted[field] != wantDrifted {
+			t.Errorf("field %s drifted = %t, want %t", field, drifted[field], wantDrifted)
+		}
+	}
+	if report.Clusters[1].Cluster != "org1/env1/missing" || report.Clusters[1].Error == "" {
+		t.Fatalf("expected an error for the missing cluster, got %+v", report.Clusters[1])
+	}
+
+	var jsonOutput bytes.Buffer
+	if err := report.WriteJSON(&jsonOutput); err != nil {
+		t.Fatal(err)
+	}
+	decoded := Report{}
+	if err := json.Unmarshal(jsonOutput.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON report: %v", err)
+	}
+	if len(decoded.Clusters) != 2 || !decoded.HasDrift() {
+		t.Fatalf("unexpected JSON report %s", jsonOutput.String())
+	}
+
+	var junitOutput bytes.Buffer
+	if err := report.WriteJUnit(&junitOutput); err != nil {
+		t.Fatal(err)
+	}
+	suites := junitTestSuites{}
+	if err := xml.Unmarshal(junitOutput.Bytes(), &suites); err != nil {
+		t.Fatalf("invalid JUnit report: %v", err)
+	}
+	if suites.Tests != 10 || suites.Failures != 3 || suites.Errors != 1 || len(suites.TestSuites) != 2 {
+		t.Fatalf("unexpected JUnit report %s", junitOutput.String())
+	}
+	failure := suites.TestSuites[0].TestCases[2].Failure
+	if suites.TestSuites[0].TestCases[2].Name != "HighlyAvailable" || failure == nil || failure.Text != "expected: true\nactual: false" {
+		t.Fatalf("expected the values of the drifted field in the JUnit report:\n%s", junitOutput.String())
+	}
+}
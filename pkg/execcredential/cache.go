@@ -0,0 +1,86 @@
+package execcredential
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// CachedToken is the ID token and refresh token of a cluster, cached on disk
+type CachedToken struct {
+	IssuerURL    string    `json:"issuerUrl"`
+	ClientID     string    `json:"clientId"`
+	IDToken      string    `json:"idToken"`
+	RefreshToken string    `json:"refreshToken,omitempty"`
+	Expiry       time.Time `json:"expiry"`
+}
+
+// validFor returns true if the ID token is still valid after d
+func (t *CachedToken) validFor(d time.Duration) bool {
+	return t.IDToken != "" && time.Now().Add(d).Before(t.Expiry)
+}
+
+// DefaultCacheDir returns the default token cache directory, ~/.acloud/cache/oidc
+func DefaultCacheDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".acloud", "cache", "oidc")
+	}
+	return filepath.Join(home, ".acloud", "cache", "oidc")
+}
+
+var unsafeFileNameCharacters = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+func cacheFile(cacheDir, clusterIdentity string) string {
+	return filepath.Join(cacheDir, unsafeFileNameCharacters.ReplaceAllString(clusterIdentity, "_")+".json")
+}
+
+// loadToken reads the cached token of a cluster, or returns nil if there is none
+func loadToken(cacheDir, clusterIdentity string) (*CachedToken, error) {
+	data, err := os.ReadFile(cacheFile(cacheDir, clusterIdentity))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	token := CachedToken{}
+	if err := json.Unmarshal(data, &token); err != nil {
+		// a corrupt cache file is replaced after the next login
+		return nil, nil
+	}
+	return &token, nil
+}
+
+// saveToken writes the cached token of a cluster, readable by the owner only. The file is replaced atomically.
+func saveToken(cacheDir, clusterIdentity string, token *CachedToken) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(cacheDir, 0o700); err != nil {
+		return err
+	}
+	path := cacheFile(cacheDir, clusterIdentity)
+	file, err := os.CreateTemp(cacheDir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Chmod(0o600); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
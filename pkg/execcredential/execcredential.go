@@ -0,0 +1,227 @@
+// Package execcredential implements a kubectl exec-credential plugin that logs in to the OpenID Connect provider of an
+// Avisi Cloud cluster, using the configuration returned by GetClusterOIDCConfig.
+//
+// The user logs in with the OAuth2 authorization code flow with PKCE in the browser. The ID and refresh tokens are
+// cached on disk per cluster identity, and the ID token is refreshed silently when it expires. The plugin is
+// implemented by the acloud-exec-credential command; use KubeconfigOpts to generate kubeconfigs that use it.
+package execcredential
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+
+	"github.com/avisi-cloud/go-client/pkg/acloudapi"
+	"github.com/avisi-cloud/go-client/pkg/kubeconfig"
+)
+
+const (
+	// Command is the name of the exec-credential plugin command
+	Command = "acloud-exec-credential"
+	// APIVersion is the default client.authentication.k8s.io API version of the ExecCredential
+	APIVersion = "client.authentication.k8s.io/v1"
+	// DefaultListenAddress is the default address to receive the OIDC redirect on, it must be registered as redirect
+	// URI of the OIDC client
+	DefaultListenAddress = "localhost:8000"
+	// EnvExecInfo is the environment variable containing the ExecCredential input of kubectl
+	EnvExecInfo = "KUBERNETES_EXEC_INFO"
+)
+
+// ErrInteractiveLoginRequired is returned when the user has to log in, but the plugin is not run interactively
+var ErrInteractiveLoginRequired = errors.New("interactive login required")
+
+// Config configures the OIDC login of a cluster
+type Config struct {
+	// IssuerURL is the URL of the OpenID Connect provider, ClusterMetadataResponse.IssuerUrl
+	IssuerURL string
+	// ClientID is the OIDC client ID, ClusterMetadataResponse.ClientID
+	ClientID string
+	// ClientSecret is the optional OIDC client secret, ClusterMetadataResponse.ClientSecret
+	ClientSecret string
+	// Scopes are requested in addition to the openid scope, defaults to email and groups
+	Scopes []string
+	// ClusterIdentity is the identity of the cluster, used as key of the token cache
+	ClusterIdentity string
+	// CacheDir is the directory of the token cache, defaults to DefaultCacheDir()
+	CacheDir string
+	// ListenAddress is the address to receive the OIDC redirect on, defaults to DefaultListenAddress
+	ListenAddress string
+	// Interactive allows logging in when there is no valid cached token
+	Interactive bool
+	// OpenBrowser opens the authorization URL for the user, defaults to OpenBrowser
+	OpenBrowser func(authURL string) error
+	// HTTPClient is used to call the OpenID Connect provider, defaults to http.DefaultClient
+	HTTPClient *http.Client
+}
+
+func configWithDefaults(config Config) (Config, error) {
+	if config.IssuerURL == "" || config.ClientID == "" || config.ClusterIdentity == "" {
+		return config, errors.New("issuer URL, client ID and cluster identity are required")
+	}
+	if config.Scopes == nil {
+		config.Scopes = []string{"email", "groups"}
+	}
+	if config.CacheDir == "" {
+		config.CacheDir = DefaultCacheDir()
+	}
+	if config.ListenAddress == "" {
+		config.ListenAddress = DefaultListenAddress
+	}
+	if config.OpenBrowser == nil {
+		config.OpenBrowser = OpenBrowser
+	}
+	return config, nil
+}
+
+// GetToken returns a valid ID token of the cluster. A cached token is returned if it is valid for at least
+// DefaultTokenRefreshBeforeExpiry, otherwise it is refreshed using the cached refresh token. The user is only asked
+// to log in if there is no refresh token or refreshing fails.
+func GetToken(ctx context.Context, config Config) (*CachedToken, error) {
+	config, err := configWithDefaults(config)
+	if err != nil {
+		return nil, err
+	}
+	cached, err := loadToken(config.CacheDir, config.ClusterIdentity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token cache: %w", err)
+	}
+	if cached != nil && (cached.IssuerURL != config.IssuerURL || cached.ClientID != config.ClientID) {
+		cached = nil
+	}
+	if cached != nil && cached.validFor(acloudapi.DefaultTokenRefreshBeforeExpiry) {
+		return cached, nil
+	}
+
+	metadata, err := discover(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	var token *CachedToken
+	if cached != nil && cached.RefreshToken != "" {
+		token, err = refresh(ctx, config, metadata, cached)
+		var oauth2Error *acloudapi.OAuth2Error
+		if err != nil && !errors.As(err, &oauth2Error) {
+			return nil, fmt.Errorf("failed to refresh token: %w", err)
+		}
+		// the refresh token has been rejected, log in again
+	}
+	if token == nil {
+		if !config.Interactive {
+			return nil, ErrInteractiveLoginRequired
+		}
+		if token, err = login(ctx, config, metadata); err != nil {
+			return nil, fmt.Errorf("failed to log in: %w", err)
+		}
+	}
+	if err := saveToken(config.CacheDir, config.ClusterIdentity, token); err != nil {
+		return nil, fmt.Errorf("failed to write token cache: %w", err)
+	}
+	return token, nil
+}
+
+// ExecCredential is the output of an exec-credential plugin
+type ExecCredential struct {
+	APIVersion string                `json:"apiVersion"`
+	Kind       string                `json:"kind"`
+	Spec       ExecCredentialSpec    `json:"spec"`
+	Status     *ExecCredentialStatus `json:"status,omitempty"`
+}
+
+// ExecCredentialSpec is the input kubectl passes to an exec-credential plugin in KUBERNETES_EXEC_INFO
+type ExecCredentialSpec struct {
+	Interactive bool `json:"interactive"`
+}
+
+type ExecCredentialStatus struct {
+	ExpirationTimestamp *time.Time `json:"expirationTimestamp,omitempty"`
+	Token               string     `json:"token"`
+}
+
+// ParseExecInfo parses the ExecCredential input of kubectl, as passed in KUBERNETES_EXEC_INFO. An ExecCredential with
+// the default APIVersion is returned when execInfo is empty.
+func ParseExecInfo(execInfo string) (*ExecCredential, error) {
+	credential := ExecCredential{APIVersion: APIVersion, Kind: "ExecCredential"}
+	if execInfo == "" {
+		return &credential, nil
+	}
+	if err := json.Unmarshal([]byte(execInfo), &credential); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", EnvExecInfo, err)
+	}
+	return &credential, nil
+}
+
+// GetExecCredential returns the ExecCredential containing a valid ID token of the cluster, see GetToken.
+// input is the ExecCredential input of kubectl, see ParseExecInfo.
+func GetExecCredential(ctx context.Context, config Config, input *ExecCredential) (*ExecCredential, error) {
+	token, err := GetToken(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	return &ExecCredential{
+		APIVersion: input.APIVersion,
+		Kind:       "ExecCredential",
+		Spec:       input.Spec,
+		Status: &ExecCredentialStatus{
+			ExpirationTimestamp: &token.Expiry,
+			Token:               token.IDToken,
+		},
+	}, nil
+}
+
+// Write writes the ExecCredential as JSON, the output expected by kubectl
+func (c *ExecCredential) Write(w io.Writer) error {
+	return json.NewEncoder(w).Encode(c)
+}
+
+// OpenBrowser prints the authorization URL on stderr and tries to open it in the browser of the user
+func OpenBrowser(authURL string) error {
+	fmt.Fprintf(os.Stderr, "Open %s in your browser to log in\n", authURL)
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", authURL)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", authURL)
+	default:
+		cmd = exec.Command("xdg-open", authURL)
+	}
+	// the URL has been printed, so failing to open the browser is not an error
+	if err := cmd.Start(); err == nil {
+		go func() { _ = cmd.Wait() }()
+	}
+	return nil
+}
+
+// KubeconfigArgs returns the arguments of the acloud-exec-credential command for a cluster. The issuer URL, client ID
+// and cluster identity are required by the command, so an error is returned when one of them is missing.
+func KubeconfigArgs(cluster kubeconfig.ClusterConfig) ([]string, error) {
+	if cluster.OIDC.IssuerUrl == "" || cluster.OIDC.ClientID == "" || cluster.Cluster.Identity == "" {
+		return nil, errors.New("issuer URL, client ID and cluster identity are required")
+	}
+	args := []string{
+		"--issuer-url=" + cluster.OIDC.IssuerUrl,
+		"--client-id=" + cluster.OIDC.ClientID,
+		"--cluster-identity=" + cluster.Cluster.Identity,
+	}
+	if cluster.OIDC.ClientSecret != "" {
+		args = append(args, "--client-secret="+cluster.OIDC.ClientSecret)
+	}
+	return args, nil
+}
+
+// KubeconfigOpts returns the kubeconfig options to authenticate with the acloud-exec-credential command instead of
+// kubelogin. It is the recommended way to generate kubeconfigs, as it needs no third-party kubectl plugin.
+func KubeconfigOpts() kubeconfig.Opts {
+	return kubeconfig.Opts{
+		ExecAPIVersion: APIVersion,
+		ExecCommand:    Command,
+		ExecArgs:       KubeconfigArgs,
+	}
+}
@@ -0,0 +1,252 @@
+package execcredential
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/avisi-cloud/go-client/pkg/acloudapi"
+	"github.com/avisi-cloud/go-client/pkg/kubeconfig"
+)
+
+// fakeProvider is a minimal OpenID Connect provider supporting the authorization code flow with PKCE and refresh tokens
+type fakeProvider struct {
+	*httptest.Server
+	tokenLifetime time.Duration
+
+	mu              sync.Mutex
+	grants          []string
+	codeChallenges  map[string]string
+	nonces          map[string]string
+	refreshRejected bool
+}
+
+func newFakeProvider(t *testing.T) *fakeProvider {
+	provider := &fakeProvider{tokenLifetime: time.Hour, codeChallenges: map[string]string{}, nonces: map[string]string{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"issuer":%q,"authorization_endpoint":%q,"token_endpoint":%q}`, provider.URL, provider.URL+"/authorize", provider.URL+"/token")
+	})
+	mux.HandleFunc("GET /authorize", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("client_id") != "kubernetes" || query.Get("code_challenge_method") != "S256" || query.Get("scope") != "openid email groups" {
+			http.Error(w, "invalid authorization request", http.StatusBadRequest)
+			return
+		}
+		provider.mu.Lock()
+		code := fmt.Sprintf("code-%d", len(provider.codeChallenges))
+		provider.codeChallenges[code] = query.Get("code_challenge")
+		provider.nonces[code] = query.Get("nonce")
+		provider.mu.Unlock()
+		http.Redirect(w, r, query.Get("redirect_uri")+"?"+url.Values{"code": {code}, "state": {query.Get("state")}}.Encode(), http.StatusFound)
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		provider.mu.Lock()
+		defer provider.mu.Unlock()
+		grantType := r.PostFormValue("grant_type")
+		provider.grants = append(provider.grants, grantType)
+		w.Header().Set("Content-Type", "application/json")
+		nonce := ""
+		switch grantType {
+		case grantTypeAuthorizationCode:
+			code := r.PostFormValue("code")
+			verifier := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+			if provider.codeChallenges[code] != base64.RawURLEncoding.EncodeToString(verifier[:]) {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error":"invalid_grant","error_description":"invalid code verifier"}`)
+				return
+			}
+			nonce = provider.nonces[code]
+		case grantTypeRefreshToken:
+			if provider.refreshRejected || r.PostFormValue("refresh_token") != "refresh-token" {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error":"invalid_grant"}`)
+				return
+			}
+		}
+		claims, _ := json.Marshal(idTokenClaims{Expiry: time.Now().Add(provider.tokenLifetime).Unix(), Nonce: nonce})
+		idToken := "eyJhbGciOiJub25lIn0." + base64.RawURLEncoding.EncodeToString(claims) + ".signature"
+		fmt.Fprintf(w, `{"access_token":"access-token","id_token":%q,"refresh_token":"refresh-token"}`, idToken)
+	})
+	provider.Server = httptest.NewServer(mux)
+	t.Cleanup(provider.Close)
+	return provider
+}
+
+func (p *fakeProvider) takeGrants() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	grants := p.grants
+	p.grants = nil
+	return grants
+}
+
+// followRedirects simulates the browser of the user
+func followRedirects(authURL string) error {
+	response, err := http.Get(authURL)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", response.Status)
+	}
+	return nil
+}
+
+func TestGetToken(t *testing.T) {
+	provider := newFakeProvider(t)
+	provider.tokenLifetime = 30 * time.Second // shorter than DefaultTokenRefreshBeforeExpiry, forcing a refresh
+	config := Config{
+		IssuerURL:       provider.URL,
+		ClientID:        "kubernetes",
+		ClusterIdentity: "cluster-1",
+		CacheDir:        t.TempDir(),
+		ListenAddress:   "127.0.0.1:0",
+		Interactive:     true,
+		OpenBrowser:     followRedirects,
+	}
+	ctx := context.Background()
+
+	token, err := GetToken(ctx, config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if grants := provider.takeGrants(); !slices.Equal(grants, []string{grantTypeAuthorizationCode}) {
+		t.Fatalf("expected login, got grants %v", grants)
+	}
+	info, err := os.Stat(cacheFile(config.CacheDir, "cluster-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("unexpected cache file mode %v", info.Mode())
+	}
+
+	config.Interactive = false
+	config.OpenBrowser = func(string) error { return errors.New("unexpected login") }
+	provider.tokenLifetime = time.Hour
+	refreshed, err := GetToken(ctx, config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if grants := provider.takeGrants(); !slices.Equal(grants, []string{grantTypeRefreshToken}) {
+		t.Fatalf("expected refresh, got grants %v", grants)
+	}
+	if refreshed.IDToken == token.IDToken || !refreshed.Expiry.After(token.Expiry) {
+		t.Fatalf("token not refreshed: %+v", refreshed)
+	}
+
+	cached, err := GetToken(ctx, config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if grants := provider.takeGrants(); len(grants) != 0 || cached.IDToken != refreshed.IDToken {
+		t.Fatalf("expected cached token, got grants %v", grants)
+	}
+
+	// another cluster has no cached token, and requires a login
+	config.ClusterIdentity = "cluster-2"
+	if _, err := GetToken(ctx, config); !errors.Is(err, ErrInteractiveLoginRequired) {
+		t.Fatalf("expected ErrInteractiveLoginRequired, got %v", err)
+	}
+}
+
+func TestGetTokenRefreshRejected(t *testing.T) {
+	provider := newFakeProvider(t)
+	provider.refreshRejected = true
+	cacheDir := t.TempDir()
+	if err := saveToken(cacheDir, "cluster-1", &CachedToken{IssuerURL: provider.URL, ClientID: "kubernetes", IDToken: "expired", RefreshToken: "refresh-token", Expiry: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := GetToken(context.Background(), Config{
+		IssuerURL:       provider.URL,
+		ClientID:        "kubernetes",
+		ClusterIdentity: "cluster-1",
+		CacheDir:        cacheDir,
+		ListenAddress:   "127.0.0.1:0",
+		Interactive:     true,
+		OpenBrowser:     followRedirects,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if grants := provider.takeGrants(); !slices.Equal(grants, []string{grantTypeRefreshToken, grantTypeAuthorizationCode}) {
+		t.Fatalf("expected refresh and login, got grants %v", grants)
+	}
+	if token.IDToken == "expired" {
+		t.Fatal("expected a new token")
+	}
+}
+
+func TestGetExecCredential(t *testing.T) {
+	provider := newFakeProvider(t)
+	input, err := ParseExecInfo(`{"apiVersion":"client.authentication.k8s.io/v1beta1","kind":"ExecCredential","spec":{"interactive":true}}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	credential, err := GetExecCredential(context.Background(), Config{
+		IssuerURL:       provider.URL,
+		ClientID:        "kubernetes",
+		ClusterIdentity: "cluster-1",
+		CacheDir:        t.TempDir(),
+		ListenAddress:   "127.0.0.1:0",
+		Interactive:     input.Spec.Interactive,
+		OpenBrowser:     followRedirects,
+	}, input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var output bytes.Buffer
+	if err := credential.Write(&output); err != nil {
+		t.Fatal(err)
+	}
+	written := map[string]any{}
+	if err := json.Unmarshal(output.Bytes(), &written); err != nil {
+		t.Fatal(err)
+	}
+	status, _ := written["status"].(map[string]any)
+	if written["apiVersion"] != "client.authentication.k8s.io/v1beta1" || written["kind"] != "ExecCredential" || status["token"] == "" || status["expirationTimestamp"] == nil {
+		t.Fatalf("unexpected ExecCredential %s", output.String())
+	}
+}
+
+func TestKubeconfigOpts(t *testing.T) {
+	config, err := kubeconfig.Generate([]kubeconfig.ClusterConfig{{
+		Cluster: acloudapi.Cluster{Identity: "cluster-1", CustomerSlug: "org1", EnvironmentSlug: "env1", Slug: "cluster1"},
+		OIDC:    acloudapi.ClusterMetadataResponse{Endpoint: "https://cluster1.example.com", ClientID: "kubernetes", IssuerUrl: "https://issuer.example.com"},
+	}}, KubeconfigOpts())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	exec := config.Users[0].User.Exec
+	wantArgs := []string{"--issuer-url=https://issuer.example.com", "--client-id=kubernetes", "--cluster-identity=cluster-1"}
+	if exec.APIVersion != APIVersion || exec.Command != Command || !slices.Equal(exec.Args, wantArgs) {
+		t.Fatalf("unexpected exec config %+v", exec)
+	}
+}
+
+func TestKubeconfigOptsWithoutClusterIdentity(t *testing.T) {
+	_, err := kubeconfig.Generate([]kubeconfig.ClusterConfig{{
+		Cluster: acloudapi.Cluster{CustomerSlug: "org1", EnvironmentSlug: "env1", Slug: "cluster1"},
+		OIDC:    acloudapi.ClusterMetadataResponse{Endpoint: "https://cluster1.example.com", ClientID: "kubernetes", IssuerUrl: "https://issuer.example.com"},
+	}}, KubeconfigOpts())
+	if err == nil || err.Error() != "cluster org1/env1/cluster1: issuer URL, client ID and cluster identity are required" {
+		t.Fatalf("expected an error for a cluster without identity, got %v", err)
+	}
+}
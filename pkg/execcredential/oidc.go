@@ -0,0 +1,269 @@
+package execcredential
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+
+	"github.com/avisi-cloud/go-client/pkg/acloudapi"
+)
+
+const (
+	grantTypeAuthorizationCode = "authorization_code"
+	grantTypeRefreshToken      = "refresh_token"
+)
+
+// providerMetadata is the part of the OpenID Connect discovery document used for the login
+type providerMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+}
+
+// tokenResponse is the response of the token endpoint of an OpenID Connect provider
+type tokenResponse struct {
+	IDToken      string `json:"id_token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+// idTokenClaims are the claims of an ID token used by the login. The signature of the ID token is not verified, this
+// is done by the Kubernetes API server.
+type idTokenClaims struct {
+	Expiry int64  `json:"exp"`
+	Nonce  string `json:"nonce,omitempty"`
+}
+
+func parseIDToken(idToken string) (*idTokenClaims, error) {
+	parts := strings.Split(idToken, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed ID token")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("malformed ID token: %w", err)
+	}
+	claims := idTokenClaims{}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("malformed ID token: %w", err)
+	}
+	if claims.Expiry == 0 {
+		return nil, errors.New("ID token has no expiry")
+	}
+	return &claims, nil
+}
+
+func httpClient(config Config) *resty.Client {
+	client := http.DefaultClient
+	if config.HTTPClient != nil {
+		client = config.HTTPClient
+	}
+	return resty.NewWithClient(client).
+		SetHeader(acloudapi.HeaderAccept, acloudapi.ContentTypeApplicationJson).
+		SetHeader(acloudapi.HeaderUserAgent, acloudapi.DefaultUserAgent)
+}
+
+func discover(ctx context.Context, config Config) (*providerMetadata, error) {
+	metadata := providerMetadata{}
+	response, err := httpClient(config).R().
+		SetContext(ctx).
+		SetResult(&metadata).
+		Get(strings.TrimSuffix(config.IssuerURL, "/") + "/.well-known/openid-configuration")
+	if err != nil {
+		return nil, err
+	}
+	if !response.IsSuccess() {
+		return nil, fmt.Errorf("failed to discover OpenID Connect provider %s: %s", config.IssuerURL, response.Status())
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" {
+		return nil, fmt.Errorf("OpenID Connect provider %s has no authorization or token endpoint", config.IssuerURL)
+	}
+	return &metadata, nil
+}
+
+// requestToken requests a token from the token endpoint and returns it as a cached token
+func requestToken(ctx context.Context, config Config, tokenEndpoint string, form map[string]string) (*CachedToken, error) {
+	token := tokenResponse{}
+	oauth2Error := acloudapi.OAuth2Error{}
+	request := httpClient(config).R().
+		SetContext(ctx).
+		SetResult(&token).
+		SetError(&oauth2Error)
+	if config.ClientSecret != "" {
+		request.SetBasicAuth(config.ClientID, config.ClientSecret)
+	} else {
+		form["client_id"] = config.ClientID
+	}
+	response, err := request.
+		SetFormData(form).
+		Post(tokenEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	if !response.IsSuccess() {
+		oauth2Error.StatusCode = response.StatusCode()
+		return nil, &oauth2Error
+	}
+	if token.IDToken == "" {
+		return nil, errors.New("token endpoint returned no ID token")
+	}
+	claims, err := parseIDToken(token.IDToken)
+	if err != nil {
+		return nil, err
+	}
+	return &CachedToken{
+		IssuerURL:    config.IssuerURL,
+		ClientID:     config.ClientID,
+		IDToken:      token.IDToken,
+		RefreshToken: token.RefreshToken,
+		Expiry:       time.Unix(claims.Expiry, 0),
+	}, nil
+}
+
+// refresh obtains a new ID token using the refresh token of the cached token
+func refresh(ctx context.Context, config Config, metadata *providerMetadata, cached *CachedToken) (*CachedToken, error) {
+	token, err := requestToken(ctx, config, metadata.TokenEndpoint, map[string]string{
+		"grant_type":    grantTypeRefreshToken,
+		"refresh_token": cached.RefreshToken,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if token.RefreshToken == "" {
+		token.RefreshToken = cached.RefreshToken
+	}
+	return token, nil
+}
+
+// login performs the OAuth2 authorization code flow with PKCE (RFC 7636). The authorization URL is opened in the
+// browser, and the authorization code is received by a server listening on the redirect URI.
+func login(ctx context.Context, config Config, metadata *providerMetadata) (*CachedToken, error) {
+	listener, err := net.Listen("tcp", config.ListenAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for the OIDC redirect: %w", err)
+	}
+	redirectURI := "http://" + redirectHost(config.ListenAddress, listener.Addr())
+
+	state, err := randomString()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := randomString()
+	if err != nil {
+		return nil, err
+	}
+	codeVerifier, err := randomString()
+	if err != nil {
+		return nil, err
+	}
+	codeChallenge := sha256.Sum256([]byte(codeVerifier))
+
+	authURL, err := url.Parse(metadata.AuthorizationEndpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid authorization endpoint: %w", err)
+	}
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", config.ClientID)
+	query.Set("redirect_uri", redirectURI)
+	query.Set("scope", strings.Join(append([]string{"openid"}, config.Scopes...), " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(codeChallenge[:]))
+	query.Set("code_challenge_method", "S256")
+	authURL.RawQuery = query.Encode()
+
+	type result struct {
+		code string
+		err  error
+	}
+	results := make(chan result, 1)
+	server := &http.Server{
+		ReadHeaderTimeout: 10 * time.Second,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query := r.URL.Query()
+			var res result
+			switch {
+			case query.Get("state") != state:
+				http.Error(w, "invalid state", http.StatusBadRequest)
+				return
+			case query.Get("error") != "":
+				res.err = &acloudapi.OAuth2Error{StatusCode: http.StatusBadRequest, ErrorCode: query.Get("error"), ErrorDescription: query.Get("error_description")}
+				http.Error(w, "Login failed: "+query.Get("error"), http.StatusBadRequest)
+			default:
+				res.code = query.Get("code")
+				fmt.Fprint(w, "Login successful, you can close this window.")
+			}
+			select {
+			case results <- res:
+			default:
+			}
+		}),
+	}
+	go func() { _ = server.Serve(listener) }()
+	defer server.Close()
+
+	if err := config.OpenBrowser(authURL.String()); err != nil {
+		return nil, err
+	}
+
+	var res result
+	select {
+	case res = <-results:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting for the OIDC login: %w", ctx.Err())
+	}
+	if res.err != nil {
+		return nil, res.err
+	}
+
+	token, err := requestToken(ctx, config, metadata.TokenEndpoint, map[string]string{
+		"grant_type":    grantTypeAuthorizationCode,
+		"code":          res.code,
+		"redirect_uri":  redirectURI,
+		"code_verifier": codeVerifier,
+	})
+	if err != nil {
+		return nil, err
+	}
+	claims, err := parseIDToken(token.IDToken)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Nonce != nonce {
+		return nil, errors.New("ID token nonce does not match")
+	}
+	return token, nil
+}
+
+// redirectHost returns the host of the redirect URI, keeping the host name of the listen address (e.g. localhost)
+// but using the actual port when listening on a random port
+func redirectHost(listenAddress string, addr net.Addr) string {
+	host, _, err := net.SplitHostPort(listenAddress)
+	if err != nil || host == "" {
+		return addr.String()
+	}
+	_, port, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return net.JoinHostPort(host, port)
+}
+
+func randomString() (string, error) {
+	data := make([]byte, 32)
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
@@ -0,0 +1,331 @@
+// Package kubeconfig renders kubeconfig files for Avisi Cloud clusters from their OIDC configuration, and merges them
+// into existing kubeconfig files.
+//
+// Every cluster gets a cluster, context and user entry named after Cluster.Identifier(), e.g. "org/env/cluster".
+// The user entry authenticates through an exec-credential plugin. Use execcredential.KubeconfigOpts for the
+// acloud-exec-credential plugin of this module, which needs no third-party kubectl plugin. Without options the
+// kubelogin plugin (kubectl oidc-login) is used:
+//
+//	config, err := kubeconfig.Fetch(ctx, client, clusters, execcredential.KubeconfigOpts())
+//	if err != nil {
+//		return err
+//	}
+//	err = kubeconfig.MergeIntoFile(kubeconfig.DefaultPath(), config)
+package kubeconfig
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/avisi-cloud/go-client/pkg/acloudapi"
+)
+
+const (
+	// DefaultExecCommand is the default command of the exec-credential user entry
+	DefaultExecCommand = "kubectl"
+	// ExecAPIVersion is the default client.authentication.k8s.io API version of the exec-credential user entry
+	ExecAPIVersion = "client.authentication.k8s.io/v1beta1"
+)
+
+// Config is a kubeconfig file. Fields that are not modelled are kept in Extra, so loading and writing a kubeconfig
+// file does not lose any settings.
+type Config struct {
+	APIVersion     string         `yaml:"apiVersion"`
+	Kind           string         `yaml:"kind"`
+	Preferences    map[string]any `yaml:"preferences"`
+	Clusters       []NamedCluster `yaml:"clusters"`
+	Contexts       []NamedContext `yaml:"contexts"`
+	Users          []NamedUser    `yaml:"users"`
+	CurrentContext string         `yaml:"current-context"`
+	Extra          map[string]any `yaml:",inline"`
+}
+
+type NamedCluster struct {
+	Name    string  `yaml:"name"`
+	Cluster Cluster `yaml:"cluster"`
+}
+
+type Cluster struct {
+	Server                   string         `yaml:"server"`
+	CertificateAuthorityData string         `yaml:"certificate-authority-data,omitempty"`
+	Extra                    map[string]any `yaml:",inline"`
+}
+
+type NamedContext struct {
+	Name    string  `yaml:"name"`
+	Context Context `yaml:"context"`
+}
+
+type Context struct {
+	Cluster   string         `yaml:"cluster"`
+	User      string         `yaml:"user"`
+	Namespace string         `yaml:"namespace,omitempty"`
+	Extra     map[string]any `yaml:",inline"`
+}
+
+type NamedUser struct {
+	Name string `yaml:"name"`
+	User User   `yaml:"user"`
+}
+
+type User struct {
+	Exec  *ExecConfig    `yaml:"exec,omitempty"`
+	Extra map[string]any `yaml:",inline"`
+}
+
+// ExecConfig configures an exec-credential plugin that provides the credentials of a user
+type ExecConfig struct {
+	APIVersion         string         `yaml:"apiVersion"`
+	Command            string         `yaml:"command"`
+	Args               []string       `yaml:"args,omitempty"`
+	Env                []ExecEnvVar   `yaml:"env,omitempty"`
+	InteractiveMode    string         `yaml:"interactiveMode,omitempty"`
+	ProvideClusterInfo bool           `yaml:"provideClusterInfo,omitempty"`
+	Extra              map[string]any `yaml:",inline"`
+}
+
+type ExecEnvVar struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value"`
+}
+
+// ClusterConfig is a cluster together with its OIDC configuration, as returned by GetClusterOIDCConfig
+type ClusterConfig struct {
+	Cluster acloudapi.Cluster
+	OIDC    acloudapi.ClusterMetadataResponse
+}
+
+// Opts are the options of Generate and Fetch
+type Opts struct {
+	// ExecAPIVersion is the client.authentication.k8s.io API version of the exec-credential user entries, defaults to ExecAPIVersion
+	ExecAPIVersion string
+	// ExecCommand is the command of the exec-credential user entries, defaults to DefaultExecCommand
+	ExecCommand string
+	// ExecArgs returns the arguments of the exec-credential command of a cluster, defaults to KubeloginArgs. Generate
+	// fails when it returns an error, e.g. when the cluster lacks a value the command needs.
+	ExecArgs func(cluster ClusterConfig) ([]string, error)
+	// Namespace is the default namespace of the contexts
+	Namespace string
+}
+
+func mergeOpts(opts []Opts) Opts {
+	merged := Opts{ExecAPIVersion: ExecAPIVersion, ExecCommand: DefaultExecCommand, ExecArgs: KubeloginArgs}
+	for _, opt := range opts {
+		if opt.ExecAPIVersion != "" {
+			merged.ExecAPIVersion = opt.ExecAPIVersion
+		}
+		if opt.ExecCommand != "" {
+			merged.ExecCommand = opt.ExecCommand
+		}
+		if opt.ExecArgs != nil {
+			merged.ExecArgs = opt.ExecArgs
+		}
+		if opt.Namespace != "" {
+			merged.Namespace = opt.Namespace
+		}
+	}
+	return merged
+}
+
+// KubeloginArgs returns the kubectl arguments to get a token using kubelogin (kubectl oidc-login)
+func KubeloginArgs(cluster ClusterConfig) ([]string, error) {
+	args := []string{
+		"oidc-login",
+		"get-token",
+		"--oidc-issuer-url=" + cluster.OIDC.IssuerUrl,
+		"--oidc-client-id=" + cluster.OIDC.ClientID,
+	}
+	if cluster.OIDC.ClientSecret != "" {
+		args = append(args, "--oidc-client-secret="+cluster.OIDC.ClientSecret)
+	}
+	return append(args, "--oidc-extra-scope=email", "--oidc-extra-scope=groups"), nil
+}
+
+// New returns an empty kubeconfig
+func New() *Config {
+	return &Config{APIVersion: "v1", Kind: "Config", Preferences: map[string]any{}}
+}
+
+// Generate renders a kubeconfig for the clusters. The current context is set to the first cluster.
+func Generate(clusters []ClusterConfig, opts ...Opts) (*Config, error) {
+	mergedOpts := mergeOpts(opts)
+	config := New()
+	for _, cluster := range clusters {
+		if cluster.OIDC.Endpoint == "" {
+			return nil, fmt.Errorf("cluster %s has no endpoint", cluster.Cluster.Identifier())
+		}
+		name := cluster.Cluster.Identifier()
+		args, err := mergedOpts.ExecArgs(cluster)
+		if err != nil {
+			return nil, fmt.Errorf("cluster %s: %w", name, err)
+		}
+		config.Clusters = append(config.Clusters, NamedCluster{Name: name, Cluster: Cluster{
+			Server:                   cluster.OIDC.Endpoint,
+			CertificateAuthorityData: certificateAuthorityData(cluster.OIDC.CACertificate),
+		}})
+		config.Contexts = append(config.Contexts, NamedContext{Name: name, Context: Context{
+			Cluster:   name,
+			User:      name,
+			Namespace: mergedOpts.Namespace,
+		}})
+		config.Users = append(config.Users, NamedUser{Name: name, User: User{Exec: &ExecConfig{
+			APIVersion:      mergedOpts.ExecAPIVersion,
+			Command:         mergedOpts.ExecCommand,
+			Args:            args,
+			InteractiveMode: "IfAvailable",
+		}}})
+	}
+	if len(config.Contexts) > 0 {
+		config.CurrentContext = config.Contexts[0].Name
+	}
+	return config, nil
+}
+
+// Fetch gets the OIDC configuration of the clusters and renders a kubeconfig for them, see Generate
+func Fetch(ctx context.Context, client acloudapi.ClusterAPI, clusters []acloudapi.Cluster, opts ...Opts) (*Config, error) {
+	clusterConfigs := make([]ClusterConfig, 0, len(clusters))
+	for _, cluster := range clusters {
+		oidc, err := client.GetClusterOIDCConfig(ctx, cluster.CustomerSlug, cluster.EnvironmentSlug, cluster.Slug)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get OIDC config of cluster %s: %w", cluster.Identifier(), err)
+		}
+		clusterConfigs = append(clusterConfigs, ClusterConfig{Cluster: cluster, OIDC: *oidc})
+	}
+	return Generate(clusterConfigs, opts...)
+}
+
+// certificateAuthorityData base64 encodes a PEM encoded CA certificate, a CA certificate that is already base64
+// encoded is returned as is
+func certificateAuthorityData(caCertificate string) string {
+	caCertificate = strings.TrimSpace(caCertificate)
+	if caCertificate == "" || !strings.HasPrefix(caCertificate, "-----BEGIN") {
+		return caCertificate
+	}
+	return base64.StdEncoding.EncodeToString([]byte(caCertificate + "\n"))
+}
+
+// Merge merges the clusters, contexts and users of config into existing. Entries of existing with the same name are
+// replaced, all other entries are kept. The current context of existing is only changed if it is not set.
+func Merge(existing, config *Config) *Config {
+	merged := *existing
+	merged.Clusters = mergeNamed(existing.Clusters, config.Clusters, func(c NamedCluster) string { return c.Name })
+	merged.Contexts = mergeNamed(existing.Contexts, config.Contexts, func(c NamedContext) string { return c.Name })
+	merged.Users = mergeNamed(existing.Users, config.Users, func(u NamedUser) string { return u.Name })
+	if merged.CurrentContext == "" {
+		merged.CurrentContext = config.CurrentContext
+	}
+	if merged.APIVersion == "" {
+		merged.APIVersion = "v1"
+	}
+	if merged.Kind == "" {
+		merged.Kind = "Config"
+	}
+	return &merged
+}
+
+func mergeNamed[T any](existing, entries []T, name func(T) string) []T {
+	merged := make([]T, 0, len(existing)+len(entries))
+	index := make(map[string]int, len(existing))
+	for _, entry := range existing {
+		index[name(entry)] = len(merged)
+		merged = append(merged, entry)
+	}
+	for _, entry := range entries {
+		if i, ok := index[name(entry)]; ok {
+			merged[i] = entry
+			continue
+		}
+		index[name(entry)] = len(merged)
+		merged = append(merged, entry)
+	}
+	return merged
+}
+
+// DefaultPath returns the first path of $KUBECONFIG, or ~/.kube/config
+func DefaultPath() string {
+	if paths := filepath.SplitList(os.Getenv("KUBECONFIG")); len(paths) > 0 && paths[0] != "" {
+		return paths[0]
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".kube", "config")
+	}
+	return filepath.Join(home, ".kube", "config")
+}
+
+// Load reads a kubeconfig file. An empty kubeconfig is returned if the file does not exist.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return New(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	config := New()
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse kubeconfig %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// Marshal encodes the kubeconfig as YAML
+func Marshal(config *Config) ([]byte, error) {
+	var buffer bytes.Buffer
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(config); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// WriteFile writes the kubeconfig to path, readable by the owner only. The file is replaced atomically.
+func WriteFile(path string, config *Config) error {
+	data, err := Marshal(config)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Chmod(0o600); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+// MergeIntoFile merges the kubeconfig into the kubeconfig file at path, see Merge. The file is created if it does
+// not exist.
+func MergeIntoFile(path string, config *Config) error {
+	existing, err := Load(path)
+	if err != nil {
+		return err
+	}
+	return WriteFile(path, Merge(existing, config))
+}
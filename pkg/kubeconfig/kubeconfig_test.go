@@ -0,0 +1,139 @@
+package kubeconfig
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/avisi-cloud/go-client/pkg/acloudapi"
+	"github.com/avisi-cloud/go-client/pkg/acloudapi/acloudapitest"
+)
+
+const existingKubeconfig = `apiVersion: v1
+kind: Config
+preferences:
+  colors: true
+clusters:
+- name: other
+  cluster:
+    server: https://other.example.com
+    insecure-skip-tls-verify: true
+- name: org1/env1/cluster1
+  cluster:
+    server: https://old.example.com
+contexts:
+- name: other
+  context:
+    cluster: other
+    user: other
+users:
+- name: other
+  user:
+    token: secret
+current-context: other
+extensions:
+- name: some-extension
+`
+
+func TestFetch(t *testing.T) {
+	server := acloudapitest.NewServer(acloudapitest.Opts{})
+	defer server.Close()
+	server.AddOrganisation(acloudapi.Organisation{Slug: "org1"})
+	server.AddEnvironment("org1", acloudapi.Environment{Slug: "env1"})
+	clusters := []acloudapi.Cluster{
+		server.AddCluster(acloudapi.Cluster{CustomerSlug: "org1", EnvironmentSlug: "env1", Slug: "cluster1"}),
+		server.AddCluster(acloudapi.Cluster{CustomerSlug: "org1", EnvironmentSlug: "env1", Slug: "cluster2"}),
+	}
+	for _, cluster := range clusters {
+		server.SetClusterOIDCConfig(cluster.Identity, acloudapi.ClusterMetadataResponse{
+			Endpoint:      "https://" + cluster.Slug + ".example.com",
+			CACertificate: "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----",
+			ClientID:      cluster.Slug,
+			IssuerUrl:     "https://issuer.example.com",
+		})
+	}
+
+	config, err := Fetch(context.Background(), server.Client(), clusters, Opts{Namespace: "default"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(config.Clusters) != 2 || len(config.Contexts) != 2 || len(config.Users) != 2 {
+		t.Fatalf("expected 2 clusters, contexts and users, got %+v", config)
+	}
+	if config.CurrentContext != "org1/env1/cluster1" {
+		t.Fatalf("unexpected current context %q", config.CurrentContext)
+	}
+	if got := config.Contexts[1].Context; got.Cluster != "org1/env1/cluster2" || got.User != "org1/env1/cluster2" || got.Namespace != "default" {
+		t.Fatalf("unexpected context %+v", got)
+	}
+	caData, err := base64.StdEncoding.DecodeString(config.Clusters[0].Cluster.CertificateAuthorityData)
+	if err != nil || !strings.HasPrefix(string(caData), "-----BEGIN CERTIFICATE-----") {
+		t.Fatalf("unexpected certificate-authority-data %q: %v", config.Clusters[0].Cluster.CertificateAuthorityData, err)
+	}
+	exec := config.Users[1].User.Exec
+	wantArgs := []string{"oidc-login", "get-token", "--oidc-issuer-url=https://issuer.example.com", "--oidc-client-id=cluster2", "--oidc-extra-scope=email", "--oidc-extra-scope=groups"}
+	if exec.Command != DefaultExecCommand || exec.APIVersion != ExecAPIVersion || !slices.Equal(exec.Args, wantArgs) {
+		t.Fatalf("unexpected exec config %+v", exec)
+	}
+
+	if _, err := Fetch(context.Background(), server.Client(), []acloudapi.Cluster{{CustomerSlug: "org1", EnvironmentSlug: "env1", Slug: "missing"}}); err == nil {
+		t.Fatal("expected an error for a missing cluster")
+	}
+}
+
+func TestMergeIntoFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(existingKubeconfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	config, err := Generate([]ClusterConfig{
+		{Cluster: acloudapi.Cluster{CustomerSlug: "org1", EnvironmentSlug: "env1", Slug: "cluster1"}, OIDC: acloudapi.ClusterMetadataResponse{Endpoint: "https://cluster1.example.com", CACertificate: "Y2E="}},
+		{Cluster: acloudapi.Cluster{CustomerSlug: "org1", EnvironmentSlug: "env1", Slug: "cluster2"}, OIDC: acloudapi.ClusterMetadataResponse{Endpoint: "https://cluster2.example.com"}},
+	}, Opts{ExecCommand: "acloud", ExecArgs: func(cluster ClusterConfig) ([]string, error) {
+		return []string{"credentials", cluster.Cluster.Identifier()}, nil
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := MergeIntoFile(path, config); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	merged, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var clusterNames []string
+	for _, cluster := range merged.Clusters {
+		clusterNames = append(clusterNames, cluster.Name)
+	}
+	if want := []string{"other", "org1/env1/cluster1", "org1/env1/cluster2"}; !slices.Equal(clusterNames, want) {
+		t.Fatalf("clusters = %v, want %v", clusterNames, want)
+	}
+	if merged.CurrentContext != "other" {
+		t.Fatalf("current context changed to %q", merged.CurrentContext)
+	}
+	if got := merged.Clusters[1].Cluster; got.Server != "https://cluster1.example.com" || got.CertificateAuthorityData != "Y2E=" {
+		t.Fatalf("cluster not replaced: %+v", got)
+	}
+	if merged.Clusters[0].Cluster.Extra["insecure-skip-tls-verify"] != true || merged.Users[0].User.Extra["token"] != "secret" {
+		t.Fatalf("unrelated entries not kept: %+v, %+v", merged.Clusters[0], merged.Users[0])
+	}
+	if merged.Preferences["colors"] != true || merged.Extra["extensions"] == nil {
+		t.Fatalf("unmodelled fields not kept: %+v", merged)
+	}
+	if exec := merged.Users[2].User.Exec; exec == nil || exec.Command != "acloud" || !slices.Equal(exec.Args, []string{"credentials", "org1/env1/cluster2"}) {
+		t.Fatalf("unexpected exec config %+v", exec)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("unexpected file mode %v", info.Mode())
+	}
+}
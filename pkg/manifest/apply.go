@@ -0,0 +1,171 @@
+package manifest
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/avisi-cloud/go-client/pkg/acloudapi"
+)
+
+// ApplyOpts are the options of Plan.Apply
+type ApplyOpts struct {
+	// OnChange is called before a change is applied
+	OnChange func(change Change)
+	// WaitOpts configures waiting for new clusters before their node pools are created, and for the deletion of
+	// clusters before their environment is deleted
+	WaitOpts acloudapi.WaitOpts
+}
+
+func mergeApplyOpts(opts []ApplyOpts) ApplyOpts {
+	merged := ApplyOpts{}
+	for _, opt := range opts {
+		if opt.OnChange != nil {
+			merged.OnChange = opt.OnChange
+		}
+		merged.WaitOpts = mergeWaitOpts(opt.WaitOpts, merged.WaitOpts)
+	}
+	return merged
+}
+
+func mergeWaitOpts(opts, merged acloudapi.WaitOpts) acloudapi.WaitOpts {
+	if opts.PollInterval > 0 {
+		merged.PollInterval = opts.PollInterval
+	}
+	if opts.Timeout > 0 {
+		merged.Timeout = opts.Timeout
+	}
+	return merged
+}
+
+// Apply applies the changes of the plan in order, and stops at the first change that fails. Clusters and node pools
+// are not awaited, except for new clusters before their node pools are created, and deleted clusters before their
+// environment is deleted.
+func (p *Plan) Apply(ctx context.Context, client Client, opts ...ApplyOpts) error {
+	mergedOpts := mergeApplyOpts(opts)
+	for _, change := range p.Changes {
+		if change.Action == ActionNoOp {
+			continue
+		}
+		if mergedOpts.OnChange != nil {
+			mergedOpts.OnChange(change)
+		}
+		if err := p.apply(ctx, client, change, mergedOpts); err != nil {
+			return fmt.Errorf("failed to %s %s %s: %w", change.Action, change.Kind, change.Name(), err)
+		}
+	}
+	return nil
+}
+
+func (p *Plan) apply(ctx context.Context, client Client, change Change, opts ApplyOpts) error {
+	switch change.Kind {
+	case KindEnvironment:
+		return p.applyEnvironment(ctx, client, change, opts)
+	case KindCluster:
+		return p.applyCluster(ctx, client, change, opts)
+	case KindNodePool:
+		return p.applyNodePool(ctx, client, change)
+	default:
+		return fmt.Errorf("unknown kind %s", change.Kind)
+	}
+}
+
+func (p *Plan) applyEnvironment(ctx context.Context, client Client, change Change, opts ApplyOpts) error {
+	switch change.Action {
+	case ActionCreate:
+		environment, err := client.CreateEnvironment(ctx, *change.createEnvironment, p.Organisation)
+		if err != nil {
+			return err
+		}
+		p.environments[change.Environment] = *environment
+		return nil
+	case ActionUpdate:
+		environment, err := client.UpdateEnvironment(ctx, *change.updateEnvironment, p.Organisation, p.environments[change.Environment].Slug)
+		if err != nil {
+			return err
+		}
+		p.environments[change.Environment] = *environment
+		return nil
+	case ActionDelete:
+		environment := p.environments[change.Environment]
+		for _, cluster := range p.clusters {
+			if cluster.EnvironmentSlug != environment.Slug {
+				continue
+			}
+			if err := acloudapi.WaitForClusterDeleted(ctx, client, p.Organisation, environment.Slug, cluster.Slug, acloudapi.WaitForClusterOpts{WaitOpts: opts.WaitOpts}); err != nil {
+				return err
+			}
+		}
+		return client.DeleteEnvironment(ctx, p.Organisation, environment.Slug)
+	}
+	return nil
+}
+
+func (p *Plan) applyCluster(ctx context.Context, client Client, change Change, opts ApplyOpts) error {
+	key := clusterKey(change.Environment, change.Cluster)
+	switch change.Action {
+	case ActionCreate:
+		environment, ok := p.environments[change.Environment]
+		if !ok {
+			return fmt.Errorf("environment %s has not been created", change.Environment)
+		}
+		create := *change.createCluster
+		create.EnvironmentID = strconv.Itoa(environment.ID)
+		cluster, err := client.CreateCluster(ctx, p.Organisation, environment.Slug, create)
+		if err != nil {
+			return err
+		}
+		p.clusters[key] = *cluster
+		if !p.createsNodePools(change.Environment, change.Cluster) {
+			return nil
+		}
+		// node pools can only be added once the cluster is running
+		cluster, err = acloudapi.WaitForCluster(ctx, client, p.Organisation, cluster.EnvironmentSlug, cluster.Slug, acloudapi.WaitForClusterOpts{WaitOpts: opts.WaitOpts})
+		if err != nil {
+			return err
+		}
+		p.clusters[key] = *cluster
+		return nil
+	case ActionUpdate:
+		live := p.clusters[key]
+		cluster, err := client.UpdateCluster(ctx, p.Organisation, live.EnvironmentSlug, live.Slug, *change.updateCluster)
+		if err != nil {
+			return err
+		}
+		p.clusters[key] = *cluster
+		return nil
+	case ActionDelete:
+		live := p.clusters[key]
+		deleted := acloudapi.ClusterStatusDeleted
+		return client.DeleteCluster(ctx, p.Organisation, live.EnvironmentSlug, live.Slug, acloudapi.UpdateCluster{Status: &deleted})
+	}
+	return nil
+}
+
+// createsNodePools returns true if the plan creates node pools in the cluster
+func (p *Plan) createsNodePools(envName, clusterName string) bool {
+	for _, change := range p.Changes {
+		if change.Kind == KindNodePool && change.Action == ActionCreate && change.Environment == envName && change.Cluster == clusterName {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Plan) applyNodePool(ctx context.Context, client Client, change Change) error {
+	cluster, ok := p.clusters[clusterKey(change.Environment, change.Cluster)]
+	if !ok {
+		return fmt.Errorf("cluster %s has not been created", clusterKey(change.Environment, change.Cluster))
+	}
+	switch change.Action {
+	case ActionCreate:
+		_, err := client.CreateNodePool(ctx, cluster, *change.createNodePool)
+		return err
+	case ActionUpdate:
+		_, err := client.UpdateNodePool(ctx, cluster, change.nodePoolID, *change.createNodePool)
+		return err
+	case ActionDelete:
+		return client.DeleteNodePool(ctx, cluster, change.nodePoolID)
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+// Package manifest applies a declarative description of the environments, clusters and node pools of an
+// organisation. A Plan is computed against the live state, and can be reviewed before it is applied:
+//
+//	m, err := manifest.Load("organisation.yaml")
+//	if err != nil {
+//		return err
+//	}
+//	plan, err := manifest.NewPlan(ctx, client, *m)
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Print(plan)
+//	err = plan.Apply(ctx, client)
+//
+// Environments, clusters and node pools are matched by name. Resources that are not in the manifest are only deleted
+// when PlanOpts.Prune is set, and clusters with delete protection are never deleted.
+package manifest
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/avisi-cloud/go-client/pkg/acloudapi"
+)
+
+// Manifest is the desired state of the environments, clusters and node pools of an organisation:
+//
+//	Organisation: my-organisation
+//	Environments:
+//	  - Name: production
+//	    Type: production
+//	    Purpose: customer facing workloads
+//	    Clusters:
+//	      - Name: web
+//	        CloudAccountIdentity: 8e4f2c1a
+//	        Region: ams3
+//	        UpdateChannel: stable
+//	        EnableHighAvailability: true
+//	        DeleteProtection: true
+//	        NodePools:
+//	          - Name: workers
+//	            NodeSize: s-4vcpu-8gb
+//	            MinSize: 3
+//	            MaxSize: 3
+type Manifest struct {
+	Organisation string        `yaml:"Organisation"`
+	Environments []Environment `yaml:"Environments"`
+}
+
+// Environment is the desired state of an environment and its clusters
+type Environment struct {
+	acloudapi.CreateEnvironment `yaml:",inline"`
+	Clusters                    []Cluster `yaml:"Clusters,omitempty"`
+}
+
+// Cluster is the desired state of a cluster and its node pools, using the fields of CreateCluster. The initial node
+// pools of CreateCluster are replaced by NodePools, which are created after the cluster.
+type Cluster struct {
+	acloudapi.CreateCluster `yaml:"-"`
+	// EnableNetworkEncryption, EnableAutoUpgrade, EnableHighAvailability and DeleteProtection are left unchanged when
+	// not set, and disabled for a new cluster
+	EnableNetworkEncryption *bool      `yaml:"EnableNetworkEncryption,omitempty"`
+	EnableAutoUpgrade       *bool      `yaml:"EnableAutoUpgrade,omitempty"`
+	EnableHighAvailability  *bool      `yaml:"EnableHighAvailability,omitempty"`
+	DeleteProtection        *bool      `yaml:"DeleteProtection,omitempty"`
+	NodePools               []NodePool `yaml:"NodePools,omitempty"`
+}
+
+func (c *Cluster) UnmarshalYAML(node *yaml.Node) error {
+	if err := node.Decode(&c.CreateCluster); err != nil {
+		return err
+	}
+	c.CreateCluster.NodePools = nil
+	fields := struct {
+		EnableNetworkEncryption *bool      `yaml:"EnableNetworkEncryption"`
+		EnableAutoUpgrade       *bool      `yaml:"EnableAutoUpgrade"`
+		EnableHighAvailability  *bool      `yaml:"EnableHighAvailability"`
+		DeleteProtection        *bool      `yaml:"DeleteProtection"`
+		NodePools               []NodePool `yaml:"NodePools"`
+	}{}
+	if err := node.Decode(&fields); err != nil {
+		return err
+	}
+	c.EnableNetworkEncryption = fields.EnableNetworkEncryption
+	c.EnableAutoUpgrade = fields.EnableAutoUpgrade
+	c.EnableHighAvailability = fields.EnableHighAvailability
+	c.DeleteProtection = fields.DeleteProtection
+	c.NodePools = fields.NodePools
+	return nil
+}
+
+// NodePool is the desired state of a node pool, using the fields of CreateNodePool
+type NodePool struct {
+	acloudapi.CreateNodePool `yaml:"-"`
+	// AutoScaling, EnableNodeAutoReplacement and EnableNodeReboots are left unchanged when not set, and disabled for a new
+	// node pool
+	AutoScaling         *bool `yaml:"AutoScaling,omitempty"`
+	NodeAutoReplacement *bool `yaml:"EnableNodeAutoReplacement,omitempty"`
+	EnableNodeReboots   *bool `yaml:"EnableNodeReboots,omitempty"`
+}
+
+func (n *NodePool) UnmarshalYAML(node *yaml.Node) error {
+	if err := node.Decode(&n.CreateNodePool); err != nil {
+		return err
+	}
+	fields := struct {
+		AutoScaling         *bool `yaml:"AutoScaling"`
+		NodeAutoReplacement *bool `yaml:"EnableNodeAutoReplacement"`
+		EnableNodeReboots   *bool `yaml:"EnableNodeReboots"`
+	}{}
+	if err := node.Decode(&fields); err != nil {
+		return err
+	}
+	n.AutoScaling = fields.AutoScaling
+	n.NodeAutoReplacement = fields.NodeAutoReplacement
+	n.EnableNodeReboots = fields.EnableNodeReboots
+	return nil
+}
+
+// create returns the request to create the node pool
+func (n NodePool) create() acloudapi.CreateNodePool {
+	create := n.CreateNodePool
+	create.AutoScaling = n.AutoScaling != nil && *n.AutoScaling
+	create.NodeAutoReplacement = n.NodeAutoReplacement != nil && *n.NodeAutoReplacement
+	create.EnableNodeReboots = n.EnableNodeReboots != nil && *n.EnableNodeReboots
+	return create
+}
+
+// Load reads and validates a manifest file
+func Load(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+	}
+	return manifest, nil
+}
+
+// Parse parses and validates a YAML manifest
+func Parse(data []byte) (*Manifest, error) {
+	manifest := Manifest{}
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, err
+	}
+	if err := manifest.Validate(); err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+// Validate checks that the organisation is set, and that all environments, clusters and node pools have a unique name
+func (m Manifest) Validate() error {
+	var errs []error
+	if m.Organisation == "" {
+		errs = append(errs, errors.New("organisation is required"))
+	}
+	environments := map[string]bool{}
+	for _, environment := range m.Environments {
+		if environment.Name == "" {
+			errs = append(errs, errors.New("environment name is required"))
+			continue
+		}
+		if environments[environment.Name] {
+			errs = append(errs, fmt.Errorf("duplicate environment %s", environment.Name))
+		}
+		environments[environment.Name] = true
+
+		clusters := map[string]bool{}
+		for _, cluster := range environment.Clusters {
+			if cluster.Name == "" {
+				errs = append(errs, fmt.Errorf("name of cluster in environment %s is required", environment.Name))
+				continue
+			}
+			if clusters[cluster.Name] {
+				errs = append(errs, fmt.Errorf("duplicate cluster %s/%s", environment.Name, cluster.Name))
+			}
+			clusters[cluster.Name] = true
+
+			nodePools := map[string]bool{}
+			for _, nodePool := range cluster.NodePools {
+				if nodePool.Name == "" {
+					errs = append(errs, fmt.Errorf("name of node pool in cluster %s/%s is required", environment.Name, cluster.Name))
+					continue
+				}
+				if nodePools[nodePool.Name] {
+					errs = append(errs, fmt.Errorf("duplicate node pool %s/%s/%s", environment.Name, cluster.Name, nodePool.Name))
+				}
+				nodePools[nodePool.Name] = true
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
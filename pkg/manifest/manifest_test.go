@@ -0,0 +1,318 @@
+package manifest
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/avisi-cloud/go-client/pkg/acloudapi"
+	"github.com/avisi-cloud/go-client/pkg/acloudapi/acloudapitest"
+)
+
+const testManifest = `
+Organisation: org1
+Environments:
+  - Name: production
+    Type: production
+    Purpose: customer facing workloads
+    Clusters:
+      - Name: web
+        Region: ams3
+        Version: "1.31"
+        EnableAutoUpgrade: true
+        DeleteProtection: true
+        NodePools:
+          - Name: workers
+            NodeSize: small
+            MinSize: 3
+            MaxSize: 3
+            Labels:
+              role: worker
+  - Name: staging
+    Type: staging
+    Clusters:
+      - Name: web
+        Region: ams3
+        NodePools:
+          - Name: workers
+            NodeSize: small
+            MinSize: 1
+            MaxSize: 1
+`
+
+func TestParse(t *testing.T) {
+	manifest, err := Parse([]byte(testManifest))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cluster := manifest.Environments[0].Clusters[0]
+	if cluster.Name != "web" || cluster.Version != "1.31" || cluster.EnableAutoUpgrade == nil || !*cluster.EnableAutoUpgrade || cluster.EnableHighAvailability != nil || cluster.DeleteProtection == nil || !*cluster.DeleteProtection {
+		t.Fatalf("unexpected cluster %+v", cluster)
+	}
+	if len(cluster.CreateCluster.NodePools) != 0 || len(cluster.NodePools) != 1 || cluster.NodePools[0].Labels["role"] != "worker" {
+		t.Fatalf("unexpected node pools %+v", cluster.NodePools)
+	}
+
+	tests := []struct {
+		name     string
+		manifest string
+		wantErr  string
+	}{
+		{name: "missing organisation", manifest: "Environments: []", wantErr: "organisation is required"},
+		{name: "duplicate environment", manifest: "Organisation: org1\nEnvironments:\n  - Name: a\n  - Name: a", wantErr: "duplicate environment a"},
+		{name: "duplicate node pool", manifest: "Organisation: org1\nEnvironments:\n  - Name: a\n    Clusters:\n      - Name: b\n        NodePools: [{Name: c}, {Name: c}]", wantErr: "duplicate node pool a/b/c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.manifest))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func newTestServer(t *testing.T) (*acloudapitest.Server, acloudapi.Client) {
+	t.Helper()
+	server := acloudapitest.NewServer(acloudapitest.Opts{})
+	t.Cleanup(server.Close)
+	server.AddOrganisation(acloudapi.Organisation{Slug: "org1"})
+	return server, server.Client()
+}
+
+func actions(plan *Plan) []string {
+	var actions []string
+	for _, change := range plan.Changes {
+		actions = append(actions, strings.TrimSpace(change.String()))
+	}
+	return actions
+}
+
+func TestPlanAndApply(t *testing.T) {
+	server, client := newTestServer(t)
+	ctx := context.Background()
+	production := server.AddEnvironment("org1", acloudapi.Environment{Name: "production", Slug: "production", Type: "production", Purpose: "old purpose"})
+	web := server.AddCluster(acloudapi.Cluster{CustomerSlug: "org1", EnvironmentSlug: production.Slug, Name: "web", Slug: "web", Region: "ams3", Version: "1.30.4"})
+	server.AddNodePool(web.Identity, acloudapi.NodePool{Name: "workers", NodeSize: "small", MinSize: 3, MaxSize: 3, Labels: map[string]string{"role": "worker"}})
+	server.AddNodePool(web.Identity, acloudapi.NodePool{Name: "legacy", NodeSize: "small", MinSize: 1, MaxSize: 1})
+	legacy := server.AddEnvironment("org1", acloudapi.Environment{Name: "legacy", Slug: "legacy"})
+	server.AddCluster(acloudapi.Cluster{CustomerSlug: "org1", EnvironmentSlug: legacy.Slug, Name: "old", Slug: "old"})
+	server.AddCluster(acloudapi.Cluster{CustomerSlug: "org1", EnvironmentSlug: legacy.Slug, Name: "protected", Slug: "protected", DeleteProtection: true})
+
+	manifest, err := Parse([]byte(testManifest))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	plan, err := NewPlan(ctx, client, *manifest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{
+		"~ update environment production (Purpose)",
+		"+ create environment staging",
+		"~ update cluster production/web (Version, EnableAutoUpgrade, DeleteProtection)",
+		"+ create cluster staging/web",
+		"no-op node pool production/web/workers",
+		"+ create node pool staging/web/workers",
+	}
+	if got := actions(plan); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected plan:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	prunePlan, err := NewPlan(ctx, client, *manifest, PlanOpts{Prune: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want = append(want,
+		"- delete node pool production/web/legacy",
+		"- delete cluster legacy/old",
+	)
+	if got := actions(prunePlan); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected plan:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if len(prunePlan.Warnings) != 2 || !strings.Contains(prunePlan.Warnings[0], "legacy/protected") {
+		t.Fatalf("unexpected warnings %v", prunePlan.Warnings)
+	}
+
+	var applied []string
+	err = prunePlan.Apply(ctx, client, ApplyOpts{
+		OnChange: func(change Change) {
+			applied = append(applied, change.Name())
+			if change.Kind == KindNodePool && change.Action == ActionCreate {
+				cluster, _ := server.Cluster(prunePlan.clusters[clusterKey(change.Environment, change.Cluster)].Identity)
+				if cluster.Status != acloudapi.ClusterStatusRunning {
+					t.Errorf("node pool %s created while its cluster is %s", change.Name(), cluster.Status)
+				}
+			}
+		},
+		WaitOpts: acloudapi.WaitOpts{PollInterval: time.Millisecond, Timeout: 5 * time.Second},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(applied) != 7 {
+		t.Fatalf("expected 7 applied changes, got %v", applied)
+	}
+
+	cluster, err := client.GetCluster(ctx, "org1", "staging", "web")
+	if err != nil {
+		t.Fatalf("staging cluster not created: %v", err)
+	}
+	if nodePools := server.NodePools(cluster.Identity); len(nodePools) != 1 || nodePools[0].Name != "workers" {
+		t.Fatalf("unexpected staging node pools %+v", nodePools)
+	}
+	updated, err := acloudapi.WaitForCluster(ctx, client, "org1", "production", "web", acloudapi.WaitForClusterOpts{WaitOpts: acloudapi.WaitOpts{PollInterval: time.Millisecond}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !updated.AutoUpgrade || !updated.DeleteProtection || updated.Version != "1.31" {
+		t.Fatalf("production cluster not updated: %+v", updated)
+	}
+
+	// the live state now matches the manifest
+	for _, change := range waitForPlan(t, client, *manifest).Changes {
+		if change.Action != ActionNoOp {
+			t.Fatalf("unexpected change after apply: %s", change)
+		}
+	}
+}
+
+// waitForPlan plans the manifest until it has no changes, or the applied changes did not complete within 5 seconds
+func waitForPlan(t *testing.T, client acloudapi.Client, manifest Manifest) *Plan {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		plan, err := NewPlan(context.Background(), client, manifest)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !plan.HasChanges() || time.Now().After(deadline) {
+			return plan
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestPlanImmutableFields(t *testing.T) {
+	server, client := newTestServer(t)
+	production := server.AddEnvironment("org1", acloudapi.Environment{Name: "production", Slug: "production", Type: "production", Purpose: "customer facing workloads"})
+	web := server.AddCluster(acloudapi.Cluster{CustomerSlug: "org1", EnvironmentSlug: production.Slug, Name: "web", Slug: "web", Region: "fra1"})
+	server.AddNodePool(web.Identity, acloudapi.NodePool{Name: "workers", NodeSize: "large", MinSize: 3, MaxSize: 3})
+
+	manifest, err := Parse([]byte(testManifest))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = NewPlan(context.Background(), client, *manifest)
+	if err == nil || !strings.Contains(err.Error(), "region of cluster production/web cannot be changed") {
+		t.Fatalf("expected an immutable region error, got %v", err)
+	}
+}
+
+func TestPlanOptionalFields(t *testing.T) {
+	server, client := newTestServer(t)
+	production := server.AddEnvironment("org1", acloudapi.Environment{Name: "production", Slug: "production"})
+	server.AddCluster(acloudapi.Cluster{CustomerSlug: "org1", EnvironmentSlug: production.Slug, Name: "web", Slug: "web", Region: "ams3",
+		EnableNetworkEncryption: true, AutoUpgrade: true, HighlyAvailable: true, DeleteProtection: true})
+
+	manifest, err := Parse([]byte("Organisation: org1\nEnvironments:\n  - Name: production\n    Clusters:\n      - Name: web\n        Region: ams3"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	plan, err := NewPlan(context.Background(), client, *manifest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plan.HasChanges() {
+		t.Fatalf("unexpected plan %v", actions(plan))
+	}
+}
+
+func TestPlanTaintOrder(t *testing.T) {
+	server, client := newTestServer(t)
+	production := server.AddEnvironment("org1", acloudapi.Environment{Name: "production", Slug: "production"})
+	web := server.AddCluster(acloudapi.Cluster{CustomerSlug: "org1", EnvironmentSlug: production.Slug, Name: "web", Slug: "web", Region: "ams3"})
+	server.AddNodePool(web.Identity, acloudapi.NodePool{Name: "workers", NodeSize: "small", MinSize: 1, MaxSize: 1, Taints: []acloudapi.NodeTaint{
+		{Key: "dedicated", Value: "web", Effect: acloudapi.TaintEffectNoExecute},
+		{Key: "dedicated", Value: "web", Effect: acloudapi.TaintEffectNoSchedule},
+	}})
+
+	manifest, err := Parse([]byte(`
+Organisation: org1
+Environments:
+  - Name: production
+    Clusters:
+      - Name: web
+        NodePools:
+          - Name: workers
+            NodeSize: small
+            MinSize: 1
+            MaxSize: 1
+            Taints:
+              - {Key: dedicated, Value: web, Effect: NoSchedule}
+              - {Key: dedicated, Value: web, Effect: NoExecute}
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	plan, err := NewPlan(context.Background(), client, *manifest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plan.HasChanges() {
+		t.Fatalf("unexpected plan %v", actions(plan))
+	}
+
+	manifest.Environments[0].Clusters[0].NodePools[0].Taints[1].Value = "api"
+	plan, err = NewPlan(context.Background(), client, *manifest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := actions(plan); !slices.Contains(got, "~ update node pool production/web/workers (Taints)") {
+		t.Fatalf("unexpected plan %v", got)
+	}
+}
+
+func TestApplyNodePoolKeepsOmittedFields(t *testing.T) {
+	server, client := newTestServer(t)
+	ctx := context.Background()
+	production := server.AddEnvironment("org1", acloudapi.Environment{Name: "production", Slug: "production"})
+	web := server.AddCluster(acloudapi.Cluster{CustomerSlug: "org1", EnvironmentSlug: production.Slug, Name: "web", Slug: "web", Region: "ams3"})
+	live := acloudapi.NodePool{
+		Name:                  "workers",
+		NodeSize:              "small",
+		AvailabilityZone:      "ams3-a",
+		MinSize:               1,
+		MaxSize:               1,
+		NodeAutoReplacement:   true,
+		EnableNodeReboots:     true,
+		UpgradeStrategy:       acloudapi.NodePoolUpgradeStrategyInPlace,
+		SecurityUpdatesOnJoin: acloudapi.NodePoolSecurityUpdatesOnJoinInstall,
+	}
+	server.AddNodePool(web.Identity, live)
+
+	manifest, err := Parse([]byte("Organisation: org1\nEnvironments:\n  - Name: production\n    Clusters:\n      - Name: web\n        NodePools:\n          - {Name: workers, NodeSize: small, MinSize: 1, MaxSize: 3}"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	plan, err := NewPlan(ctx, client, *manifest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := actions(plan); !slices.Contains(got, "~ update node pool production/web/workers (MaxSize)") {
+		t.Fatalf("unexpected plan %v", got)
+	}
+	if err := plan.Apply(ctx, client); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	updated := server.NodePools(web.Identity)[0]
+	live.MaxSize = 3
+	if updated.MaxSize != 3 || updated.AvailabilityZone != live.AvailabilityZone || !updated.NodeAutoReplacement || !updated.EnableNodeReboots ||
+		updated.UpgradeStrategy != live.UpgradeStrategy || updated.SecurityUpdatesOnJoin != live.SecurityUpdatesOnJoin {
+		t.Fatalf("unexpected node pool %+v", updated)
+	}
+}
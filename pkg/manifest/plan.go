@@ -0,0 +1,447 @@
+package manifest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/avisi-cloud/go-client/pkg/acloudapi"
+)
+
+// Client is the part of the API client used to plan and apply a manifest
+type Client interface {
+	acloudapi.EnvironmentsAPI
+	acloudapi.ClusterAPI
+	acloudapi.NodePoolsAPI
+}
+
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+	ActionNoOp   Action = "no-op"
+)
+
+type Kind string
+
+const (
+	KindEnvironment Kind = "environment"
+	KindCluster     Kind = "cluster"
+	KindNodePool    Kind = "node pool"
+)
+
+// Change is a planned change of a single environment, cluster or node pool
+type Change struct {
+	Action Action
+	Kind   Kind
+	// Environment, Cluster and NodePool are the names of the changed resource and its parents
+	Environment string
+	Cluster     string
+	NodePool    string
+	// Fields are the names of the fields changed by an update
+	Fields []string
+
+	createEnvironment *acloudapi.CreateEnvironment
+	updateEnvironment *acloudapi.UpdateEnvironment
+	createCluster     *acloudapi.CreateCluster
+	updateCluster     *acloudapi.UpdateCluster
+	createNodePool    *acloudapi.CreateNodePool
+	nodePoolID        int
+}
+
+// Name returns the path of the changed resource, e.g. "production/web/workers"
+func (c Change) Name() string {
+	name := c.Environment
+	if c.Cluster != "" {
+		name += "/" + c.Cluster
+	}
+	if c.NodePool != "" {
+		name += "/" + c.NodePool
+	}
+	return name
+}
+
+func (c Change) String() string {
+	symbol := map[Action]string{ActionCreate: "+", ActionUpdate: "~", ActionDelete: "-", ActionNoOp: " "}[c.Action]
+	s := fmt.Sprintf("%s %s %s %s", symbol, c.Action, c.Kind, c.Name())
+	if len(c.Fields) > 0 {
+		s += " (" + strings.Join(c.Fields, ", ") + ")"
+	}
+	return s
+}
+
+// Plan is the ordered list of changes to apply a manifest: environments, clusters and node pools are created and
+// updated in that order, and deleted in reverse order
+type Plan struct {
+	Organisation string
+	Changes      []Change
+	// Warnings describe changes that are required by the manifest but will not be applied, e.g. deleting a cluster
+	// with delete protection
+	Warnings []string
+
+	environments map[string]acloudapi.Environment
+	clusters     map[string]acloudapi.Cluster
+}
+
+// HasChanges returns true if the plan contains changes other than no-ops
+func (p *Plan) HasChanges() bool {
+	return slices.ContainsFunc(p.Changes, func(change Change) bool { return change.Action != ActionNoOp })
+}
+
+func (p *Plan) String() string {
+	var b strings.Builder
+	for _, change := range p.Changes {
+		b.WriteString(change.String())
+		b.WriteString("\n")
+	}
+	for _, warning := range p.Warnings {
+		b.WriteString("! ")
+		b.WriteString(warning)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// PlanOpts are the options of NewPlan
+type PlanOpts struct {
+	// Prune deletes environments, clusters and node pools that are not in the manifest
+	Prune bool
+}
+
+func mergePlanOpts(opts []PlanOpts) PlanOpts {
+	merged := PlanOpts{}
+	for _, opt := range opts {
+		if opt.Prune {
+			merged.Prune = true
+		}
+	}
+	return merged
+}
+
+// NewPlan computes the changes required to bring the live state of the organisation in line with the manifest.
+// An error is returned when the manifest changes fields that cannot be updated, e.g. the region of a cluster.
+func NewPlan(ctx context.Context, client Client, manifest Manifest, opts ...PlanOpts) (*Plan, error) {
+	mergedOpts := mergePlanOpts(opts)
+	if err := manifest.Validate(); err != nil {
+		return nil, err
+	}
+	org := manifest.Organisation
+
+	liveEnvironments, err := client.GetEnvironments(ctx, org)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get environments: %w", err)
+	}
+	liveClusters, err := client.GetClustersByOrg(ctx, org)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get clusters: %w", err)
+	}
+	environmentsByName := map[string]acloudapi.Environment{}
+	environmentsBySlug := map[string]acloudapi.Environment{}
+	for _, environment := range liveEnvironments {
+		environmentsByName[environment.Name] = environment
+		environmentsBySlug[environment.Slug] = environment
+	}
+	// clusters by environment name and cluster name, clusters that are being deleted are ignored
+	clustersByName := map[string]map[string]acloudapi.Cluster{}
+	for _, cluster := range liveClusters {
+		environment, ok := environmentsBySlug[cluster.EnvironmentSlug]
+		if !ok || cluster.Status == acloudapi.ClusterStatusDeleting || cluster.Status == acloudapi.ClusterStatusDeleted {
+			continue
+		}
+		if clustersByName[environment.Name] == nil {
+			clustersByName[environment.Name] = map[string]acloudapi.Cluster{}
+		}
+		clustersByName[environment.Name][cluster.Name] = cluster
+	}
+
+	plan := &Plan{Organisation: org, environments: map[string]acloudapi.Environment{}, clusters: map[string]acloudapi.Cluster{}}
+	var environmentChanges, clusterChanges, nodePoolChanges []Change
+	var nodePoolDeletes, clusterDeletes, environmentDeletes []Change
+	var errs []error
+
+	for _, desiredEnvironment := range manifest.Environments {
+		envName := desiredEnvironment.Name
+		liveEnvironment, exists := environmentsByName[envName]
+		if !exists {
+			create := desiredEnvironment.CreateEnvironment
+			environmentChanges = append(environmentChanges, Change{Action: ActionCreate, Kind: KindEnvironment, Environment: envName, createEnvironment: &create})
+		} else {
+			plan.environments[envName] = liveEnvironment
+			environmentChanges = append(environmentChanges, environmentChange(desiredEnvironment, liveEnvironment))
+		}
+
+		liveEnvironmentClusters := clustersByName[envName]
+		for _, desiredCluster := range desiredEnvironment.Clusters {
+			liveCluster, exists := liveEnvironmentClusters[desiredCluster.Name]
+			if !exists {
+				create := desiredCluster.CreateCluster
+				create.EnableNetworkEncryption = desiredCluster.EnableNetworkEncryption != nil && *desiredCluster.EnableNetworkEncryption
+				create.EnableAutoUpgrade = desiredCluster.EnableAutoUpgrade != nil && *desiredCluster.EnableAutoUpgrade
+				create.EnableHighAvailability = desiredCluster.EnableHighAvailability != nil && *desiredCluster.EnableHighAvailability
+				if liveEnvironment.ID != 0 {
+					create.EnvironmentID = strconv.Itoa(liveEnvironment.ID)
+				}
+				clusterChanges = append(clusterChanges, Change{Action: ActionCreate, Kind: KindCluster, Environment: envName, Cluster: desiredCluster.Name, createCluster: &create})
+				for _, desiredNodePool := range desiredCluster.NodePools {
+					create := desiredNodePool.create()
+					nodePoolChanges = append(nodePoolChanges, Change{Action: ActionCreate, Kind: KindNodePool, Environment: envName, Cluster: desiredCluster.Name, NodePool: desiredNodePool.Name, createNodePool: &create})
+				}
+				continue
+			}
+
+			plan.clusters[clusterKey(envName, desiredCluster.Name)] = liveCluster
+			change, err := clusterChange(envName, desiredCluster, liveCluster)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			clusterChanges = append(clusterChanges, change)
+
+			liveNodePools, err := client.GetNodePoolsByCluster(ctx, liveCluster)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get node pools of cluster %s: %w", liveCluster.Identifier(), err)
+			}
+			liveNodePoolsByName := map[string]acloudapi.NodePool{}
+			for _, nodePool := range liveNodePools {
+				if nodePool.ProvisionStatus == acloudapi.NodePoolStatusDeleting || nodePool.ProvisionStatus == acloudapi.NodePoolStatusDeleted {
+					continue
+				}
+				liveNodePoolsByName[nodePool.Name] = nodePool
+			}
+			for _, desiredNodePool := range desiredCluster.NodePools {
+				liveNodePool, exists := liveNodePoolsByName[desiredNodePool.Name]
+				if !exists {
+					create := desiredNodePool.create()
+					nodePoolChanges = append(nodePoolChanges, Change{Action: ActionCreate, Kind: KindNodePool, Environment: envName, Cluster: desiredCluster.Name, NodePool: desiredNodePool.Name, createNodePool: &create})
+					continue
+				}
+				change, err := nodePoolChange(envName, desiredCluster.Name, desiredNodePool, liveNodePool)
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				nodePoolChanges = append(nodePoolChanges, change)
+			}
+			if mergedOpts.Prune {
+				for _, name := range slices.Sorted(maps.Keys(liveNodePoolsByName)) {
+					if slices.ContainsFunc(desiredCluster.NodePools, func(n NodePool) bool { return n.Name == name }) {
+						continue
+					}
+					nodePoolDeletes = append(nodePoolDeletes, Change{Action: ActionDelete, Kind: KindNodePool, Environment: envName, Cluster: desiredCluster.Name, NodePool: name, nodePoolID: liveNodePoolsByName[name].ID})
+				}
+			}
+		}
+
+		if mergedOpts.Prune {
+			deletes, warnings := pruneClusters(plan, envName, liveEnvironmentClusters, func(name string) bool {
+				return slices.ContainsFunc(desiredEnvironment.Clusters, func(c Cluster) bool { return c.Name == name })
+			})
+			clusterDeletes = append(clusterDeletes, deletes...)
+			plan.Warnings = append(plan.Warnings, warnings...)
+		}
+	}
+
+	if mergedOpts.Prune {
+		for _, liveEnvironment := range liveEnvironments {
+			if slices.ContainsFunc(manifest.Environments, func(e Environment) bool { return e.Name == liveEnvironment.Name }) {
+				continue
+			}
+			plan.environments[liveEnvironment.Name] = liveEnvironment
+			deletes, warnings := pruneClusters(plan, liveEnvironment.Name, clustersByName[liveEnvironment.Name], func(string) bool { return false })
+			clusterDeletes = append(clusterDeletes, deletes...)
+			plan.Warnings = append(plan.Warnings, warnings...)
+			if len(warnings) > 0 {
+				plan.Warnings = append(plan.Warnings, fmt.Sprintf("environment %s is not deleted, it contains clusters with delete protection", liveEnvironment.Name))
+				continue
+			}
+			environmentDeletes = append(environmentDeletes, Change{Action: ActionDelete, Kind: KindEnvironment, Environment: liveEnvironment.Name})
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	plan.Changes = slices.Concat(environmentChanges, clusterChanges, nodePoolChanges, nodePoolDeletes, clusterDeletes, environmentDeletes)
+	return plan, nil
+}
+
+func clusterKey(environment, cluster string) string {
+	return environment + "/" + cluster
+}
+
+// versionIncludes returns true if the live version equals the desired version, or is a patch version of a desired
+// minor version
+func versionIncludes(desired, live string) bool {
+	desiredVersion, err := acloudapi.ParseVersion(desired)
+	if err != nil {
+		return desired == live
+	}
+	liveVersion, err := acloudapi.ParseVersion(live)
+	if err != nil {
+		return desired == live
+	}
+	return desiredVersion.Includes(liveVersion)
+}
+
+// pruneClusters returns the deletes of the live clusters that are not desired, and warnings for the clusters with
+// delete protection
+func pruneClusters(plan *Plan, envName string, liveClusters map[string]acloudapi.Cluster, desired func(name string) bool) ([]Change, []string) {
+	var deletes []Change
+	var warnings []string
+	for _, name := range slices.Sorted(maps.Keys(liveClusters)) {
+		if desired(name) {
+			continue
+		}
+		liveCluster := liveClusters[name]
+		if liveCluster.DeleteProtection {
+			warnings = append(warnings, fmt.Sprintf("cluster %s/%s is not deleted, it has delete protection", envName, name))
+			continue
+		}
+		plan.clusters[clusterKey(envName, name)] = liveCluster
+		deletes = append(deletes, Change{Action: ActionDelete, Kind: KindCluster, Environment: envName, Cluster: name})
+	}
+	return deletes, warnings
+}
+
+func environmentChange(desired Environment, live acloudapi.Environment) Change {
+	change := Change{Action: ActionNoOp, Kind: KindEnvironment, Environment: desired.Name}
+	if desired.Purpose != "" && desired.Purpose != live.Purpose {
+		change.Fields = append(change.Fields, "Purpose")
+	}
+	if desired.Type != "" && desired.Type != live.Type {
+		change.Fields = append(change.Fields, "Type")
+	}
+	if desired.Description != "" && desired.Description != live.Description {
+		change.Fields = append(change.Fields, "Description")
+	}
+	if len(change.Fields) > 0 {
+		change.Action = ActionUpdate
+		change.updateEnvironment = &acloudapi.UpdateEnvironment{
+			Name:        desired.Name,
+			Purpose:     desired.Purpose,
+			Type:        desired.Type,
+			Description: desired.Description,
+		}
+	}
+	return change
+}
+
+func clusterChange(envName string, desired Cluster, live acloudapi.Cluster) (Change, error) {
+	change := Change{Action: ActionNoOp, Kind: KindCluster, Environment: envName, Cluster: desired.Name}
+	name := clusterKey(envName, desired.Name)
+	if desired.Region != "" && desired.Region != live.Region {
+		return change, fmt.Errorf("region of cluster %s cannot be changed from %s to %s", name, live.Region, desired.Region)
+	}
+	if desired.CloudAccountIdentity != "" && live.CloudAccount != nil && desired.CloudAccountIdentity != live.CloudAccount.Identity {
+		return change, fmt.Errorf("cloud account of cluster %s cannot be changed", name)
+	}
+
+	update := acloudapi.UpdateCluster{}
+	if desired.Version != "" && !versionIncludes(desired.Version, live.Version) {
+		update.Version = &desired.Version
+		change.Fields = append(change.Fields, "Version")
+	}
+	if desired.UpdateChannel != "" && (live.UpdateChannel == nil || desired.UpdateChannel != live.UpdateChannel.Name) {
+		update.UpdateChannel = &desired.UpdateChannel
+		change.Fields = append(change.Fields, "UpdateChannel")
+	}
+	if desired.CNI != "" && desired.CNI != live.CNI {
+		update.CNI = &desired.CNI
+		change.Fields = append(change.Fields, "CNI")
+	}
+	if desired.EnableNetworkEncryption != nil && *desired.EnableNetworkEncryption != live.EnableNetworkEncryption {
+		update.EnableNetworkEncryption = desired.EnableNetworkEncryption
+		change.Fields = append(change.Fields, "EnableNetworkEncryption")
+	}
+	if desired.EnableAutoUpgrade != nil && *desired.EnableAutoUpgrade != live.AutoUpgrade {
+		update.EnableAutoUpgrade = desired.EnableAutoUpgrade
+		change.Fields = append(change.Fields, "EnableAutoUpgrade")
+	}
+	if desired.EnableHighAvailability != nil && *desired.EnableHighAvailability != live.HighlyAvailable {
+		update.EnableHighAvailability = desired.EnableHighAvailability
+		change.Fields = append(change.Fields, "EnableHighAvailability")
+	}
+	if desired.PodSecurityStandardsProfile != "" && desired.PodSecurityStandardsProfile != live.PodSecurityStandardsProfile {
+		update.PodSecurityStandardsProfile = &desired.PodSecurityStandardsProfile
+		change.Fields = append(change.Fields, "PodSecurityStandardsProfile")
+	}
+	if desired.DeleteProtection != nil && *desired.DeleteProtection != live.DeleteProtection {
+		update.DeleteProtection = desired.DeleteProtection
+		change.Fields = append(change.Fields, "DeleteProtection")
+	}
+	if desired.MaintenanceScheduleIdentity != "" && (live.MaintenanceSchedule == nil || desired.MaintenanceScheduleIdentity != live.MaintenanceSchedule.Identity) {
+		update.MaintenanceScheduleIdentity = &desired.MaintenanceScheduleIdentity
+		change.Fields = append(change.Fields, "MaintenanceScheduleIdentity")
+	}
+	if desired.AutoscalerSettings != nil && !reflect.DeepEqual(desired.AutoscalerSettings, live.AutoscalerSettings) {
+		update.AutoscalerSettings = desired.AutoscalerSettings
+		change.Fields = append(change.Fields, "ClusterAutoscalerSettings")
+	}
+	if len(change.Fields) > 0 {
+		change.Action = ActionUpdate
+		change.updateCluster = &update
+	}
+	return change, nil
+}
+
+// nodePoolChange compares the desired node pool with the live node pool. As the API replaces the whole node pool on
+// update, the update is the live node pool with only the changed fields applied.
+func nodePoolChange(envName, clusterName string, desired NodePool, live acloudapi.NodePool) (Change, error) {
+	change := Change{Action: ActionNoOp, Kind: KindNodePool, Environment: envName, Cluster: clusterName, NodePool: desired.Name, nodePoolID: live.ID}
+	name := clusterKey(clusterKey(envName, clusterName), desired.Name)
+	if desired.NodeSize != live.NodeSize {
+		return change, fmt.Errorf("node size of node pool %s cannot be changed from %s to %s", name, live.NodeSize, desired.NodeSize)
+	}
+	if desired.AvailabilityZone != "" && desired.AvailabilityZone != live.AvailabilityZone {
+		return change, fmt.Errorf("availability zone of node pool %s cannot be changed", name)
+	}
+
+	update := live.ToCreateNodePool()
+	fields := []struct {
+		name    string
+		changed bool
+		apply   func()
+	}{
+		{"MinSize", desired.MinSize != live.MinSize, func() { update.MinSize = desired.MinSize }},
+		{"MaxSize", desired.MaxSize != live.MaxSize, func() { update.MaxSize = desired.MaxSize }},
+		{"AutoScaling", desired.AutoScaling != nil && *desired.AutoScaling != live.AutoScaling, func() { update.AutoScaling = *desired.AutoScaling }},
+		{"EnableNodeAutoReplacement", desired.NodeAutoReplacement != nil && *desired.NodeAutoReplacement != live.NodeAutoReplacement, func() { update.NodeAutoReplacement = *desired.NodeAutoReplacement }},
+		{"EnableNodeReboots", desired.EnableNodeReboots != nil && *desired.EnableNodeReboots != live.EnableNodeReboots, func() { update.EnableNodeReboots = *desired.EnableNodeReboots }},
+		{"UpgradeStrategy", desired.UpgradeStrategy != "" && desired.UpgradeStrategy != live.UpgradeStrategy, func() { update.UpgradeStrategy = desired.UpgradeStrategy }},
+		{"SecurityUpdatesOnJoin", desired.SecurityUpdatesOnJoin != "" && desired.SecurityUpdatesOnJoin != live.SecurityUpdatesOnJoin, func() { update.SecurityUpdatesOnJoin = desired.SecurityUpdatesOnJoin }},
+		{"Annotations", !maps.Equal(desired.Annotations, live.Annotations), func() { update.Annotations = desired.Annotations }},
+		{"Labels", !maps.Equal(desired.Labels, live.Labels), func() { update.Labels = desired.Labels }},
+		{"Taints", !taintsEqual(desired.Taints, live.Taints), func() { update.Taints = desired.Taints }},
+	}
+	for _, field := range fields {
+		if field.changed {
+			change.Fields = append(change.Fields, field.name)
+			field.apply()
+		}
+	}
+	if len(change.Fields) > 0 {
+		change.Action = ActionUpdate
+		change.createNodePool = &update
+	}
+	return change, nil
+}
+
+// taintsEqual returns true if both lists contain the same taints in any order, keyed by their key and effect
+func taintsEqual(a, b []acloudapi.NodeTaint) bool {
+	type taintKey struct{ key, effect string }
+	values := func(taints []acloudapi.NodeTaint) map[taintKey]string {
+		values := map[taintKey]string{}
+		for _, taint := range taints {
+			values[taintKey{taint.Key, taint.Effect}] = taint.Value
+		}
+		return values
+	}
+	return len(a) == len(b) && maps.Equal(values(a), values(b))
+}
@@ -0,0 +1,277 @@
+// Package nodepool provides high-level operations on the node pools of a cluster: scaling a node pool to a fixed size,
+// enabling autoscaling, and resizing the nodes of a node pool by replacing it with a new node pool. Every operation
+// returns the steps it took, or would take in a dry run:
+//
+//	steps, err := nodepool.Resize(ctx, client, *cluster, "workers", "t3.xlarge", nodepool.Opts{DryRun: true})
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Print(steps)
+package nodepool
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/avisi-cloud/go-client/pkg/acloudapi"
+)
+
+// Client is the part of the API client used by the node pool operations
+type Client interface {
+	acloudapi.ClusterAPI
+	acloudapi.NodePoolsAPI
+}
+
+// Opts are the options of the node pool operations
+type Opts struct {
+	// DryRun only returns the steps, without changing the node pools
+	DryRun bool
+	// OnStep is called before a step is applied, and for every step in a dry run
+	OnStep func(step Step)
+	// WaitOpts configures waiting for node pools during Resize
+	WaitOpts acloudapi.WaitOpts
+	// ReplacementName is the name of the node pool created by Resize, defaults to the name of the node pool suffixed
+	// with the new node size
+	ReplacementName string
+}
+
+func mergeOpts(opts []Opts) Opts {
+	merged := Opts{}
+	for _, opt := range opts {
+		if opt.DryRun {
+			merged.DryRun = true
+		}
+		if opt.OnStep != nil {
+			merged.OnStep = opt.OnStep
+		}
+		if opt.WaitOpts.PollInterval > 0 {
+			merged.WaitOpts.PollInterval = opt.WaitOpts.PollInterval
+		}
+		if opt.WaitOpts.Timeout > 0 {
+			merged.WaitOpts.Timeout = opt.WaitOpts.Timeout
+		}
+		if opt.ReplacementName != "" {
+			merged.ReplacementName = opt.ReplacementName
+		}
+	}
+	return merged
+}
+
+// Action is the action of a Step
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionWait   Action = "wait for"
+	ActionDelete Action = "delete"
+)
+
+// Step is a single change of a node pool operation
+type Step struct {
+	Action      Action
+	NodePool    string
+	Description string
+
+	apply func(ctx context.Context) error
+}
+
+func (s Step) String() string {
+	return fmt.Sprintf("%s node pool %s: %s", s.Action, s.NodePool, s.Description)
+}
+
+// Steps are the steps of a node pool operation, in order
+type Steps []Step
+
+// String returns the steps as a numbered list
+func (s Steps) String() string {
+	b := strings.Builder{}
+	for i, step := range s {
+		fmt.Fprintf(&b, "%d. %s\n", i+1, step)
+	}
+	return b.String()
+}
+
+// Scale sets the node pool to a fixed number of nodes, disabling autoscaling. The last node pool of a cluster cannot be
+// scaled to zero nodes.
+func Scale(ctx context.Context, client Client, cluster acloudapi.Cluster, name string, size int, opts ...Opts) (Steps, error) {
+	nodePools, nodePool, err := getNodePool(ctx, client, cluster, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSize(cluster, nodePools, *nodePool, size, size); err != nil {
+		return nil, err
+	}
+	if !nodePool.AutoScaling && nodePool.MinSize == size && nodePool.MaxSize == size {
+		return nil, nil
+	}
+	update := nodePool.ToCreateNodePool()
+	update.AutoScaling = false
+	update.MinSize = size
+	update.MaxSize = size
+	steps := Steps{updateStep(client, cluster, *nodePool, update, fmt.Sprintf("scale from %s to %d nodes", sizeString(*nodePool), size))}
+	return apply(ctx, steps, mergeOpts(opts))
+}
+
+// Autoscale enables autoscaling of the node pool between minSize and maxSize nodes. The last node pool of a cluster
+// requires a minSize of at least one node.
+func Autoscale(ctx context.Context, client Client, cluster acloudapi.Cluster, name string, minSize, maxSize int, opts ...Opts) (Steps, error) {
+	nodePools, nodePool, err := getNodePool(ctx, client, cluster, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSize(cluster, nodePools, *nodePool, minSize, maxSize); err != nil {
+		return nil, err
+	}
+	if nodePool.AutoScaling && nodePool.MinSize == minSize && nodePool.MaxSize == maxSize {
+		return nil, nil
+	}
+	update := nodePool.ToCreateNodePool()
+	update.AutoScaling = true
+	update.MinSize = minSize
+	update.MaxSize = maxSize
+	steps := Steps{updateStep(client, cluster, *nodePool, update, fmt.Sprintf("autoscale between %d and %d nodes, was %s", minSize, maxSize, sizeString(*nodePool)))}
+	return apply(ctx, steps, mergeOpts(opts))
+}
+
+// Resize changes the node size of a node pool. As the node size of a node pool cannot be changed, a replacement node
+// pool with the same settings and the new node size is created. Once it is provisioned, the old node pool is deleted.
+// Resize refuses to replace node pools of a cluster with delete protection.
+func Resize(ctx context.Context, client Client, cluster acloudapi.Cluster, name, nodeSize string, opts ...Opts) (Steps, error) {
+	mergedOpts := mergeOpts(opts)
+	nodePools, nodePool, err := getNodePool(ctx, client, cluster, name)
+	if err != nil {
+		return nil, err
+	}
+	if nodePool.NodeSize == nodeSize {
+		return nil, nil
+	}
+	current, err := client.GetCluster(ctx, cluster.CustomerSlug, cluster.EnvironmentSlug, cluster.Slug)
+	if err != nil {
+		return nil, err
+	}
+	if current.DeleteProtection {
+		return nil, fmt.Errorf("cluster %s has delete protection, node pool %s cannot be replaced", cluster.Identifier(), name)
+	}
+	replacementName := mergedOpts.ReplacementName
+	if replacementName == "" {
+		replacementName = fmt.Sprintf("%s-%s", name, invalidNameCharacters.ReplaceAllString(strings.ToLower(nodeSize), "-"))
+	}
+	if slices.ContainsFunc(nodePools, func(n acloudapi.NodePool) bool { return n.Name == replacementName }) {
+		return nil, fmt.Errorf("node pool %s already exists in cluster %s", replacementName, cluster.Identifier())
+	}
+
+	create := nodePool.ToCreateNodePool()
+	create.Name = replacementName
+	create.NodeSize = nodeSize
+	var replacement *acloudapi.NodePool
+	waitOpts := acloudapi.WaitForNodePoolOpts{WaitOpts: mergedOpts.WaitOpts}
+	steps := Steps{
+		{
+			Action:      ActionCreate,
+			NodePool:    replacementName,
+			Description: fmt.Sprintf("replacement of %s with node size %s and %s", name, nodeSize, sizeString(*nodePool)),
+			apply: func(ctx context.Context) error {
+				created, err := client.CreateNodePool(ctx, cluster, create)
+				replacement = created
+				return err
+			},
+		},
+		{
+			Action:      ActionWait,
+			NodePool:    replacementName,
+			Description: "provisioned",
+			apply: func(ctx context.Context) error {
+				_, err := acloudapi.WaitForNodePool(ctx, client, cluster, replacement.ID, waitOpts)
+				return err
+			},
+		},
+		{
+			Action:      ActionDelete,
+			NodePool:    name,
+			Description: fmt.Sprintf("replaced by %s, node size %s", replacementName, nodePool.NodeSize),
+			apply: func(ctx context.Context) error {
+				return client.DeleteNodePool(ctx, cluster, nodePool.ID)
+			},
+		},
+		{
+			Action:      ActionWait,
+			NodePool:    name,
+			Description: "deleted",
+			apply: func(ctx context.Context) error {
+				return acloudapi.WaitForNodePoolDeleted(ctx, client, cluster, nodePool.ID, waitOpts)
+			},
+		},
+	}
+	return apply(ctx, steps, mergedOpts)
+}
+
+var invalidNameCharacters = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// getNodePool returns the active node pools of the cluster, and the node pool with the name
+func getNodePool(ctx context.Context, client Client, cluster acloudapi.Cluster, name string) ([]acloudapi.NodePool, *acloudapi.NodePool, error) {
+	nodePools, err := client.GetNodePoolsByCluster(ctx, cluster)
+	if err != nil {
+		return nil, nil, err
+	}
+	nodePools = slices.DeleteFunc(nodePools, func(nodePool acloudapi.NodePool) bool {
+		return nodePool.ProvisionStatus == acloudapi.NodePoolStatusDeleting || nodePool.ProvisionStatus == acloudapi.NodePoolStatusDeleted
+	})
+	index := slices.IndexFunc(nodePools, func(nodePool acloudapi.NodePool) bool { return nodePool.Name == name })
+	if index < 0 {
+		return nil, nil, fmt.Errorf("node pool %s of cluster %s: %w", name, cluster.Identifier(), acloudapi.ErrNotFound)
+	}
+	return nodePools, &nodePools[index], nil
+}
+
+// checkSize returns an error if the bounds are invalid, or leave the cluster without nodes
+func checkSize(cluster acloudapi.Cluster, nodePools []acloudapi.NodePool, nodePool acloudapi.NodePool, minSize, maxSize int) error {
+	switch {
+	case minSize < 0:
+		return fmt.Errorf("the size of node pool %s must not be negative", nodePool.Name)
+	case maxSize < minSize:
+		return fmt.Errorf("the maximum size of node pool %s must not be less than its minimum size %d", nodePool.Name, minSize)
+	case minSize < 1 && len(nodePools) == 1:
+		return fmt.Errorf("node pool %s is the last node pool of cluster %s and requires at least one node", nodePool.Name, cluster.Identifier())
+	}
+	return nil
+}
+
+func sizeString(nodePool acloudapi.NodePool) string {
+	if nodePool.AutoScaling {
+		return fmt.Sprintf("autoscaling between %d and %d nodes", nodePool.MinSize, nodePool.MaxSize)
+	}
+	return fmt.Sprintf("%d nodes", nodePool.MinSize)
+}
+
+func updateStep(client Client, cluster acloudapi.Cluster, nodePool acloudapi.NodePool, update acloudapi.CreateNodePool, description string) Step {
+	return Step{
+		Action:      ActionUpdate,
+		NodePool:    nodePool.Name,
+		Description: description,
+		apply: func(ctx context.Context) error {
+			_, err := client.UpdateNodePool(ctx, cluster, nodePool.ID, update)
+			return err
+		},
+	}
+}
+
+// apply applies the steps in order unless it is a dry run, and stops at the first step that fails. The steps are
+// returned, up to and including the failed step.
+func apply(ctx context.Context, steps Steps, opts Opts) (Steps, error) {
+	for i, step := range steps {
+		if opts.OnStep != nil {
+			opts.OnStep(step)
+		}
+		if opts.DryRun {
+			continue
+		}
+		if err := step.apply(ctx); err != nil {
+			return steps[:i+1], fmt.Errorf("failed to %s: %w", step, err)
+		}
+	}
+	return steps, nil
+}
@@ -0,0 +1,181 @@
+package nodepool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/avisi-cloud/go-client/pkg/acloudapi"
+	"github.com/avisi-cloud/go-client/pkg/acloudapi/acloudapitest"
+)
+
+func newTestServer(t *testing.T, deleteProtection bool) (*acloudapitest.Server, acloudapi.Cluster) {
+	t.Helper()
+	server := acloudapitest.NewServer(acloudapitest.Opts{})
+	t.Cleanup(server.Close)
+	server.AddOrganisation(acloudapi.Organisation{Slug: "org1"})
+	server.AddEnvironment("org1", acloudapi.Environment{Slug: "env1"})
+	cluster := server.AddCluster(acloudapi.Cluster{CustomerSlug: "org1", EnvironmentSlug: "env1", Slug: "web", DeleteProtection: deleteProtection})
+	server.AddNodePool(cluster.Identity, acloudapi.NodePool{
+		Name:            "workers",
+		NodeSize:        "t3.large",
+		MinSize:         3,
+		MaxSize:         3,
+		UpgradeStrategy: acloudapi.NodePoolUpgradeStrategyReplace,
+		Labels:          map[string]string{"role": "worker"},
+	})
+	return server, cluster
+}
+
+func TestScale(t *testing.T) {
+	server, cluster := newTestServer(t, false)
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		scale     func() (Steps, error)
+		wantSteps string
+		wantErr   string
+		want      string
+	}{
+		{
+			name:      "scale",
+			scale:     func() (Steps, error) { return Scale(ctx, server.Client(), cluster, "workers", 5) },
+			wantSteps: "1. update node pool workers: scale from 3 nodes to 5 nodes\n",
+			want:      "false 5 5",
+		},
+		{
+			name:  "unchanged",
+			scale: func() (Steps, error) { return Scale(ctx, server.Client(), cluster, "workers", 5) },
+			want:  "false 5 5",
+		},
+		{
+			name:      "autoscale",
+			scale:     func() (Steps, error) { return Autoscale(ctx, server.Client(), cluster, "workers", 2, 6) },
+			wantSteps: "1. update node pool workers: autoscale between 2 and 6 nodes, was 5 nodes\n",
+			want:      "true 2 6",
+		},
+		{
+			name:    "last node pool",
+			scale:   func() (Steps, error) { return Scale(ctx, server.Client(), cluster, "workers", 0) },
+			wantErr: "node pool workers is the last node pool of cluster org1/env1/web and requires at least one node",
+			want:    "true 2 6",
+		},
+		{
+			name:    "invalid bounds",
+			scale:   func() (Steps, error) { return Autoscale(ctx, server.Client(), cluster, "workers", 4, 3) },
+			wantErr: "must not be less than its minimum size 4",
+			want:    "true 2 6",
+		},
+		{
+			name:    "unknown node pool",
+			scale:   func() (Steps, error) { return Scale(ctx, server.Client(), cluster, "unknown", 1) },
+			wantErr: "node pool unknown of cluster org1/env1/web: not found",
+			want:    "true 2 6",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steps, err := tt.scale()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error %q, got %v", tt.wantErr, err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if steps.String() != tt.wantSteps {
+				t.Fatalf("unexpected steps:\n%s\nwant:\n%s", steps, tt.wantSteps)
+			}
+			nodePool := server.NodePools(cluster.Identity)[0]
+			if got := fmt.Sprintf("%t %d %d", nodePool.AutoScaling, nodePool.MinSize, nodePool.MaxSize); got != tt.want {
+				t.Fatalf("unexpected node pool size %s, want %s", got, tt.want)
+			}
+			if nodePool.Labels["role"] != "worker" || nodePool.UpgradeStrategy != acloudapi.NodePoolUpgradeStrategyReplace {
+				t.Fatalf("settings of the node pool were not kept: %+v", nodePool)
+			}
+		})
+	}
+}
+
+func TestScaleWithReservedLabel(t *testing.T) {
+	server, cluster := newTestServer(t, false)
+	ctx := context.Background()
+	nodePool := server.AddNodePool(cluster.Identity, acloudapi.NodePool{
+		Name:     "infra",
+		NodeSize: "t3.large",
+		MinSize:  1,
+		MaxSize:  1,
+		Labels:   map[string]string{"node-role.kubernetes.io/infra": ""},
+	})
+
+	if _, err := Scale(ctx, server.Client(), cluster, nodePool.Name, 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := Autoscale(ctx, server.Client(), cluster, nodePool.Name, 1, 3); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	scaled := server.NodePools(cluster.Identity)[1]
+	if !scaled.AutoScaling || scaled.MaxSize != 3 || len(scaled.Labels) != 1 {
+		t.Fatalf("unexpected node pool %+v", scaled)
+	}
+}
+
+func TestResize(t *testing.T) {
+	server, cluster := newTestServer(t, false)
+	ctx := context.Background()
+	fastPolling := acloudapi.WaitOpts{PollInterval: time.Millisecond, Timeout: 5 * time.Second}
+	want := `1. create node pool workers-t3-xlarge: replacement of workers with node size t3.xlarge and 3 nodes
+2. wait for node pool workers-t3-xlarge: provisioned
+3. delete node pool workers: replaced by workers-t3-xlarge, node size t3.large
+4. wait for node pool workers: deleted
+`
+
+	var dryRun []string
+	steps, err := Resize(ctx, server.Client(), cluster, "workers", "t3.xlarge", Opts{DryRun: true, OnStep: func(step Step) { dryRun = append(dryRun, step.String()) }})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if steps.String() != want || len(dryRun) != 4 {
+		t.Fatalf("unexpected dry run steps:\n%s\nwant:\n%s", steps, want)
+	}
+	for _, request := range server.Requests() {
+		if request.Method != "GET" {
+			t.Fatalf("unexpected request %s %s in a dry run", request.Method, request.Path)
+		}
+	}
+
+	steps, err = Resize(ctx, server.Client(), cluster, "workers", "t3.xlarge", Opts{WaitOpts: fastPolling})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if steps.String() != want {
+		t.Fatalf("unexpected steps:\n%s\nwant:\n%s", steps, want)
+	}
+	nodePools := server.NodePools(cluster.Identity)
+	if len(nodePools) != 2 || nodePools[0].ProvisionStatus != acloudapi.NodePoolStatusDeleted {
+		t.Fatalf("expected the old node pool to be deleted: %+v", nodePools)
+	}
+	replacement := nodePools[1]
+	if replacement.Name != "workers-t3-xlarge" || replacement.NodeSize != "t3.xlarge" || replacement.MinSize != 3 || replacement.Labels["role"] != "worker" ||
+		replacement.ProvisionStatus != acloudapi.NodePoolStatusProvisioned {
+		t.Fatalf("unexpected replacement node pool %+v", replacement)
+	}
+}
+
+func TestResizeDeleteProtection(t *testing.T) {
+	server, cluster := newTestServer(t, true)
+	_, err := Resize(context.Background(), server.Client(), cluster, "workers", "t3.xlarge")
+	if err == nil || !strings.Contains(err.Error(), "has delete protection") {
+		t.Fatalf("expected a delete protection error, got %v", err)
+	}
+	if len(server.NodePools(cluster.Identity)) != 1 {
+		t.Fatal("expected no replacement node pool")
+	}
+	if _, err := Resize(context.Background(), server.Client(), cluster, "unknown", "t3.xlarge"); !errors.Is(err, acloudapi.ErrNotFound) {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+}
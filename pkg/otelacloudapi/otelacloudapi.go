@@ -0,0 +1,208 @@
+// Package otelacloudapi instruments the Avisi Cloud API client with OpenTelemetry traces and metrics.
+//
+// Only the OpenTelemetry API is used: spans and metrics are recorded by the TracerProvider and MeterProvider
+// configured by the application, which default to the global providers (no-ops unless an SDK is installed).
+//
+//	instrumentation, err := otelacloudapi.New(otelacloudapi.Opts{})
+//	if err != nil {
+//		return err
+//	}
+//	client := acloudapi.NewClient(authenticator, acloudapi.ClientOpts{
+//		APIUrl:          "https://example.com",
+//		Instrumentation: instrumentation,
+//	})
+package otelacloudapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/avisi-cloud/go-client/pkg/acloudapi"
+)
+
+const (
+	// ScopeName is the instrumentation scope name of the tracer and meter
+	ScopeName = "github.com/avisi-cloud/go-client/pkg/otelacloudapi"
+
+	// AttributeOperation is the attribute containing the operation name, e.g. "ClusterAPI.GetCluster"
+	AttributeOperation = attribute.Key("acloudapi.operation")
+
+	attributeErrorType          = attribute.Key("error.type")
+	attributeHTTPRequestMethod  = attribute.Key("http.request.method")
+	attributeHTTPResponseStatus = attribute.Key("http.response.status_code")
+	attributeServerAddress      = attribute.Key("server.address")
+	attributeURLFull            = attribute.Key("url.full")
+)
+
+// Opts configures the OpenTelemetry instrumentation
+type Opts struct {
+	// TracerProvider creates the tracer used for the spans, defaults to the global TracerProvider
+	TracerProvider trace.TracerProvider
+	// MeterProvider creates the meter used for the metrics, defaults to the global MeterProvider
+	MeterProvider metric.MeterProvider
+	// Propagator injects the trace context into the request headers, defaults to the W3C Trace Context propagator
+	Propagator propagation.TextMapPropagator
+}
+
+// Instrumentation implements acloudapi.Instrumentation using OpenTelemetry.
+//
+// Every API call is recorded as a span named after the operation, e.g. "ClusterAPI.GetCluster", and every HTTP
+// request of the call (including retries) as a child client span. The trace context is propagated in the request headers.
+type Instrumentation struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+
+	operationDuration metric.Float64Histogram
+	requestCount      metric.Int64Counter
+	requestDuration   metric.Float64Histogram
+	requestErrors     metric.Int64Counter
+}
+
+var _ acloudapi.Instrumentation = &Instrumentation{}
+
+// New returns an Instrumentation recording traces and metrics with the providers configured in opts
+func New(opts Opts) (*Instrumentation, error) {
+	if opts.TracerProvider == nil {
+		opts.TracerProvider = otel.GetTracerProvider()
+	}
+	if opts.MeterProvider == nil {
+		opts.MeterProvider = otel.GetMeterProvider()
+	}
+	if opts.Propagator == nil {
+		opts.Propagator = propagation.TraceContext{}
+	}
+
+	meter := opts.MeterProvider.Meter(ScopeName)
+	i := &Instrumentation{
+		tracer:     opts.TracerProvider.Tracer(ScopeName),
+		propagator: opts.Propagator,
+	}
+	var err, errs error
+	i.operationDuration, err = meter.Float64Histogram("acloudapi.client.operation.duration",
+		metric.WithDescription("Duration of API calls, including all requests and retries"),
+		metric.WithUnit("s"))
+	errs = errors.Join(errs, err)
+	i.requestCount, err = meter.Int64Counter("acloudapi.client.request.count",
+		metric.WithDescription("Number of HTTP requests, including retries"),
+		metric.WithUnit("{request}"))
+	errs = errors.Join(errs, err)
+	i.requestDuration, err = meter.Float64Histogram("acloudapi.client.request.duration",
+		metric.WithDescription("Duration of HTTP requests"),
+		metric.WithUnit("s"))
+	errs = errors.Join(errs, err)
+	i.requestErrors, err = meter.Int64Counter("acloudapi.client.request.errors",
+		metric.WithDescription("Number of failed HTTP requests, either without a response or with a non-2xx status"),
+		metric.WithUnit("{request}"))
+	errs = errors.Join(errs, err)
+	if errs != nil {
+		return nil, fmt.Errorf("failed to create metric instruments: %w", errs)
+	}
+	return i, nil
+}
+
+type operationKey struct{}
+
+// StartOperation starts the span of an API call
+func (i *Instrumentation) StartOperation(ctx context.Context, operation string) (context.Context, func(err error)) {
+	start := time.Now()
+	ctx, span := i.tracer.Start(ctx, operation,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(AttributeOperation.String(operation)),
+	)
+	ctx = context.WithValue(ctx, operationKey{}, operation)
+	return ctx, func(err error) {
+		attrs := []attribute.KeyValue{AttributeOperation.String(operation)}
+		if err != nil {
+			attrs = append(attrs, attributeErrorType.String(errorType(err)))
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		i.operationDuration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(attrs...))
+		span.End()
+	}
+}
+
+// WrapTransport records a client span and metrics for every HTTP request, and injects the trace context into its headers
+func (i *Instrumentation) WrapTransport(next http.RoundTripper) http.RoundTripper {
+	return &transport{next: next, instrumentation: i}
+}
+
+type transport struct {
+	next            http.RoundTripper
+	instrumentation *Instrumentation
+}
+
+func (t *transport) RoundTrip(request *http.Request) (*http.Response, error) {
+	ctx := request.Context()
+	attrs := []attribute.KeyValue{
+		attributeHTTPRequestMethod.String(request.Method),
+		attributeServerAddress.String(request.URL.Hostname()),
+	}
+	if operation, ok := ctx.Value(operationKey{}).(string); ok {
+		attrs = append(attrs, AttributeOperation.String(operation))
+	}
+
+	start := time.Now()
+	ctx, span := t.instrumentation.tracer.Start(ctx, request.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+		trace.WithAttributes(attributeURLFull.String(redactedURL(request))),
+	)
+	defer span.End()
+
+	// the request must not be modified by a RoundTripper, clone it before injecting the headers
+	request = request.Clone(ctx)
+	t.instrumentation.propagator.Inject(ctx, propagation.HeaderCarrier(request.Header))
+
+	response, err := t.next.RoundTrip(request)
+	failed := true
+	switch {
+	case err != nil:
+		attrs = append(attrs, attributeErrorType.String(fmt.Sprintf("%T", err)))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	case response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices:
+		attrs = append(attrs, attributeHTTPResponseStatus.Int(response.StatusCode), attributeErrorType.String(strconv.Itoa(response.StatusCode)))
+		span.SetAttributes(attributeHTTPResponseStatus.Int(response.StatusCode))
+		span.SetStatus(codes.Error, http.StatusText(response.StatusCode))
+	default:
+		failed = false
+		attrs = append(attrs, attributeHTTPResponseStatus.Int(response.StatusCode))
+		span.SetAttributes(attributeHTTPResponseStatus.Int(response.StatusCode))
+	}
+
+	metricAttrs := metric.WithAttributes(attrs...)
+	t.instrumentation.requestCount.Add(ctx, 1, metricAttrs)
+	t.instrumentation.requestDuration.Record(ctx, time.Since(start).Seconds(), metricAttrs)
+	if failed {
+		t.instrumentation.requestErrors.Add(ctx, 1, metricAttrs)
+	}
+	return response, err
+}
+
+// errorType returns the status code of API errors, or the type of other errors
+func errorType(err error) string {
+	var apiError *acloudapi.APIError
+	if errors.As(err, &apiError) {
+		return strconv.Itoa(apiError.StatusCode)
+	}
+	return fmt.Sprintf("%T", err)
+}
+
+// redactedURL returns the URL of the request without user info
+func redactedURL(request *http.Request) string {
+	u := *request.URL
+	u.User = nil
+	return u.String()
+}
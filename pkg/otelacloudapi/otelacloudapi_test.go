@@ -0,0 +1,128 @@
+package otelacloudapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/avisi-cloud/go-client/pkg/acloudapi"
+)
+
+func TestInstrumentation(t *testing.T) {
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("Traceparent")
+		if r.URL.Path == "/api/v1/orgs/org1/clusters/env1/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message":"not found"}`)
+			return
+		}
+		fmt.Fprint(w, `{"identity":"cluster-1","slug":"cluster1"}`)
+	}))
+	defer server.Close()
+
+	spanRecorder := tracetest.NewSpanRecorder()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder))
+	metricReader := sdkmetric.NewManualReader()
+	meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(metricReader))
+
+	instrumentation, err := New(Opts{TracerProvider: tracerProvider, MeterProvider: meterProvider})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client := acloudapi.NewClient(nil, acloudapi.ClientOpts{
+		APIUrl:          server.URL,
+		RetryPolicy:     acloudapi.NoRetryPolicy(),
+		Instrumentation: instrumentation,
+	})
+
+	ctx := context.Background()
+	if _, err := client.GetCluster(ctx, "org1", "env1", "cluster1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := client.GetCluster(ctx, "org1", "env1", "missing"); !errors.Is(err, acloudapi.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	spans := spanRecorder.Ended()
+	if len(spans) != 4 {
+		t.Fatalf("expected 4 spans, got %d", len(spans))
+	}
+	httpSpan, operationSpan := spans[0], spans[1]
+	if operationSpan.Name() != "ClusterAPI.GetCluster" || operationSpan.SpanKind() != trace.SpanKindInternal {
+		t.Errorf("unexpected operation span: %s %s", operationSpan.Name(), operationSpan.SpanKind())
+	}
+	if httpSpan.Name() != http.MethodGet || httpSpan.SpanKind() != trace.SpanKindClient {
+		t.Errorf("unexpected http span: %s %s", httpSpan.Name(), httpSpan.SpanKind())
+	}
+	if httpSpan.Parent().SpanID() != operationSpan.SpanContext().SpanID() {
+		t.Errorf("http span is not a child of the operation span")
+	}
+	if want := fmt.Sprintf("00-%s-%s-01", spans[2].SpanContext().TraceID(), spans[2].SpanContext().SpanID()); traceparent != want {
+		t.Errorf("traceparent = %q, want %q", traceparent, want)
+	}
+	if failedSpan := spans[3]; failedSpan.Status().Code != codes.Error || len(failedSpan.Events()) != 1 {
+		t.Errorf("expected failed operation span to record the error: %+v %+v", failedSpan.Status(), failedSpan.Events())
+	}
+
+	metrics := metricdata.ResourceMetrics{}
+	if err := metricReader.Collect(ctx, &metrics); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := map[string]int64{}
+	for _, scopeMetrics := range metrics.ScopeMetrics {
+		for _, m := range scopeMetrics.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				for _, point := range data.DataPoints {
+					got[m.Name] += point.Value
+				}
+			case metricdata.Histogram[float64]:
+				for _, point := range data.DataPoints {
+					got[m.Name] += int64(point.Count)
+					if m.Name == "acloudapi.client.operation.duration" {
+						if operation, _ := point.Attributes.Value(AttributeOperation); operation.AsString() != "ClusterAPI.GetCluster" {
+							t.Errorf("unexpected operation attribute: %v", operation)
+						}
+					}
+				}
+			}
+		}
+	}
+	want := map[string]int64{
+		"acloudapi.client.operation.duration": 2,
+		"acloudapi.client.request.count":      2,
+		"acloudapi.client.request.duration":   2,
+		"acloudapi.client.request.errors":     1,
+	}
+	for name, value := range want {
+		if got[name] != value {
+			t.Errorf("%s = %d, want %d", name, got[name], value)
+		}
+	}
+}
+
+func TestErrorType(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{err: fmt.Errorf("wrapped: %w", &acloudapi.APIError{StatusCode: http.StatusConflict}), want: "409"},
+		{err: errors.New("failed"), want: "*errors.errorString"},
+	}
+	for _, tt := range tests {
+		if got := errorType(tt.err); got != tt.want {
+			t.Errorf("errorType(%v) = %s, want %s", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/avisi-cloud/go-client/pkg/acloudapi"
+)
+
+// Client is the part of the API client used to export and restore snapshots
+type Client interface {
+	acloudapi.EnvironmentsAPI
+	acloudapi.ClusterAPI
+	acloudapi.NodePoolsAPI
+	acloudapi.CloudAccountsAPI
+	acloudapi.MaintenanceAPI
+	acloudapi.ObservabilityAPI
+}
+
+// silenceStateExpired is the state of a silence that ended
+const silenceStateExpired = "expired"
+
+// Export exports the environments, clusters, node pools, maintenance schedules, cloud accounts and observability
+// tenants of an organisation. Deleted clusters and expired silences are not exported.
+func Export(ctx context.Context, client Client, org string) (*Snapshot, error) {
+	snapshot := &Snapshot{
+		APIVersion:   APIVersion,
+		Kind:         Kind,
+		Organisation: org,
+		CreatedAt:    time.Now().UTC(),
+	}
+
+	environments, err := client.GetEnvironments(ctx, org)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get environments: %w", err)
+	}
+	clusters, err := client.GetClustersByOrg(ctx, org, acloudapi.GetClusterOpts{IncludeDetails: acloudapi.True()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get clusters: %w", err)
+	}
+	for _, environment := range environments {
+		exported := Environment{
+			Slug: environment.Slug,
+			CreateEnvironment: acloudapi.CreateEnvironment{
+				Name:        environment.Name,
+				Purpose:     environment.Purpose,
+				Type:        environment.Type,
+				Description: environment.Description,
+			},
+			Clusters: []Cluster{},
+		}
+		for _, cluster := range clusters {
+			if cluster.EnvironmentSlug != environment.Slug || cluster.Status == acloudapi.ClusterStatusDeleting || cluster.Status == acloudapi.ClusterStatusDeleted {
+				continue
+			}
+			nodePools, err := client.GetNodePoolsByCluster(ctx, cluster)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get node pools of cluster %s: %w", cluster.Identifier(), err)
+			}
+			exported.Clusters = append(exported.Clusters, exportCluster(cluster, nodePools))
+		}
+		snapshot.Environments = append(snapshot.Environments, exported)
+	}
+
+	if snapshot.MaintenanceSchedules, err = client.GetMaintenanceSchedules(ctx, org); err != nil {
+		return nil, fmt.Errorf("failed to get maintenance schedules: %w", err)
+	}
+	cloudAccounts, err := client.GetCloudAccounts(ctx, org)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cloud accounts: %w", err)
+	}
+	for _, cloudAccount := range cloudAccounts {
+		snapshot.CloudAccounts = append(snapshot.CloudAccounts, CloudAccount{
+			Identity:     cloudAccount.Identity,
+			DisplayName:  cloudAccount.DisplayName,
+			CloudProfile: cloudAccount.CloudProfile.Identity,
+			Metadata:     cloudAccount.Metadata,
+		})
+	}
+
+	tenants, err := client.GetObservabilityTenants(ctx, org)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get observability tenants: %w", err)
+	}
+	for _, tenant := range tenants {
+		exported, err := exportObservabilityTenant(ctx, client, org, tenant)
+		if err != nil {
+			return nil, err
+		}
+		snapshot.ObservabilityTenants = append(snapshot.ObservabilityTenants, exported)
+	}
+	return snapshot, nil
+}
+
+func exportCluster(cluster acloudapi.Cluster, nodePools []acloudapi.NodePool) Cluster {
+	exported := Cluster{
+		Slug: cluster.Slug,
+		CreateCluster: acloudapi.CreateCluster{
+			Name:                         cluster.Name,
+			Description:                  cluster.Description,
+			Region:                       cluster.Region,
+			Version:                      cluster.Version,
+			CNI:                          cluster.CNI,
+			EnableNATGateway:             cluster.EnableNATGateway,
+			EnableNetworkEncryption:      cluster.EnableNetworkEncryption,
+			PodSecurityStandardsProfile:  cluster.PodSecurityStandardsProfile,
+			EnableMultiAvailabilityZones: cluster.EnableMultiAvailAbilityZones,
+			EnableAutoUpgrade:            cluster.AutoUpgrade,
+			EnableHighAvailability:       cluster.HighlyAvailable,
+			Addons:                       cluster.Addons,
+			AutoscalerSettings:           cluster.AutoscalerSettings,
+		},
+		DeleteProtection: cluster.DeleteProtection,
+		NodePools:        []acloudapi.CreateNodePool{},
+	}
+	if cluster.CloudAccount != nil {
+		exported.CloudAccountIdentity = cluster.CloudAccount.Identity
+	}
+	if cluster.UpdateChannel != nil {
+		exported.UpdateChannel = cluster.UpdateChannel.Name
+	}
+	if cluster.MaintenanceSchedule != nil {
+		exported.MaintenanceScheduleIdentity = cluster.MaintenanceSchedule.Identity
+	}
+	for _, entry := range cluster.IPWhitelist {
+		exported.IPWhitelist = append(exported.IPWhitelist, acloudapi.IPWhitelistEntry{Cidr: entry.Cidr, Description: entry.Description})
+	}
+	for _, nodePool := range nodePools {
+		if nodePool.ProvisionStatus == acloudapi.NodePoolStatusDeleting || nodePool.ProvisionStatus == acloudapi.NodePoolStatusDeleted {
+			continue
+		}
+		exported.NodePools = append(exported.NodePools, nodePool.ToCreateNodePool())
+	}
+	return exported
+}
+
+func exportObservabilityTenant(ctx context.Context, client Client, org string, tenant acloudapi.ObservabilityTenant) (ObservabilityTenant, error) {
+	exported := ObservabilityTenant{Slug: tenant.Slug, Name: tenant.Name, PrometheusRules: map[string]string{}, Silences: []acloudapi.Silence{}}
+	alertmanager, err := client.GetObservabilityTenantAlertmanagerConfiguration(ctx, org, tenant.Slug)
+	if err != nil {
+		return exported, fmt.Errorf("failed to get Prometheus rules of observability tenant %s: %w", tenant.Slug, err)
+	}
+	if alertmanager != nil {
+		maps.Copy(exported.PrometheusRules, alertmanager.Rules)
+	}
+	silences, err := client.GetSilences(ctx, org, tenant.Slug)
+	if err != nil {
+		return exported, fmt.Errorf("failed to get silences of observability tenant %s: %w", tenant.Slug, err)
+	}
+	for _, silence := range silences {
+		if !strings.EqualFold(silence.Status.State, silenceStateExpired) {
+			exported.Silences = append(exported.Silences, silence)
+		}
+	}
+	slices.SortFunc(exported.Silences, func(a, b acloudapi.Silence) int { return a.StartsAt.Compare(b.StartsAt) })
+	return exported, nil
+}
@@ -0,0 +1,356 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/avisi-cloud/go-client/pkg/acloudapi"
+)
+
+// RestoreOpts are the options of Restore
+type RestoreOpts struct {
+	// EnvironmentSlugs maps the slugs of exported environments to the slugs to restore them as. A remapped environment
+	// is created with its new slug as name.
+	EnvironmentSlugs map[string]string
+	// ObservabilityTenantSlugs maps the slugs of exported observability tenants to the existing tenants to restore
+	// their Prometheus rules and silences into
+	ObservabilityTenantSlugs map[string]string
+	// CloudAccountIdentities maps the identities of exported cloud accounts to existing cloud accounts to use instead,
+	// e.g. a cloud account in another region
+	CloudAccountIdentities map[string]string
+	// WaitOpts configures waiting for clusters to be running before their node pools are created
+	WaitOpts acloudapi.WaitOpts
+}
+
+func mergeRestoreOpts(opts []RestoreOpts) RestoreOpts {
+	merged := RestoreOpts{
+		EnvironmentSlugs:         map[string]string{},
+		ObservabilityTenantSlugs: map[string]string{},
+		CloudAccountIdentities:   map[string]string{},
+	}
+	for _, opt := range opts {
+		maps.Copy(merged.EnvironmentSlugs, opt.EnvironmentSlugs)
+		maps.Copy(merged.ObservabilityTenantSlugs, opt.ObservabilityTenantSlugs)
+		maps.Copy(merged.CloudAccountIdentities, opt.CloudAccountIdentities)
+		if opt.WaitOpts.PollInterval > 0 {
+			merged.WaitOpts.PollInterval = opt.WaitOpts.PollInterval
+		}
+		if opt.WaitOpts.Timeout > 0 {
+			merged.WaitOpts.Timeout = opt.WaitOpts.Timeout
+		}
+	}
+	return merged
+}
+
+// Result lists the resources created and skipped by Restore, e.g. "cluster production/web"
+type Result struct {
+	Created  []string
+	Skipped  []string
+	Warnings []string
+}
+
+func (r *Result) created(format string, args ...any) {
+	r.Created = append(r.Created, fmt.Sprintf(format, args...))
+}
+
+func (r *Result) skipped(format string, args ...any) {
+	r.Skipped = append(r.Skipped, fmt.Sprintf(format, args...))
+}
+
+func (r *Result) warn(format string, args ...any) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+// Restore restores a snapshot into an organisation. Resources that already exist are skipped, so a failed restore can
+// be retried: maintenance schedules and cloud accounts are matched by name, environments and clusters by slug and
+// node pools by name. Node pools are created once their cluster is running. Restored cloud accounts have no
+// credentials, these have to be added before the clusters can be provisioned, so Restore times out waiting for a
+// cluster with node pools until then. Observability tenants cannot be created, their rules and silences are only
+// restored into existing tenants. Restore stops at the first resource that fails, and returns the result so far.
+func Restore(ctx context.Context, client Client, snapshot Snapshot, org string, opts ...RestoreOpts) (*Result, error) {
+	if err := snapshot.Validate(); err != nil {
+		return nil, err
+	}
+	r := &restorer{
+		client: client,
+		org:    org,
+		opts:   mergeRestoreOpts(opts),
+		result: &Result{},
+	}
+	var err error
+	if r.maintenanceSchedules, err = r.restoreMaintenanceSchedules(ctx, snapshot.MaintenanceSchedules); err != nil {
+		return r.result, err
+	}
+	if r.cloudAccounts, err = r.restoreCloudAccounts(ctx, snapshot.CloudAccounts); err != nil {
+		return r.result, err
+	}
+	if err := r.restoreEnvironments(ctx, snapshot.Environments); err != nil {
+		return r.result, err
+	}
+	if err := r.restoreObservabilityTenants(ctx, snapshot.ObservabilityTenants); err != nil {
+		return r.result, err
+	}
+	return r.result, nil
+}
+
+type restorer struct {
+	client Client
+	org    string
+	opts   RestoreOpts
+	result *Result
+
+	// maintenanceSchedules and cloudAccounts map the exported identities to the restored identities
+	maintenanceSchedules map[string]string
+	cloudAccounts        map[string]string
+}
+
+func (r *restorer) restoreMaintenanceSchedules(ctx context.Context, maintenanceSchedules []acloudapi.MaintenanceSchedule) (map[string]string, error) {
+	existing, err := r.client.GetMaintenanceSchedules(ctx, r.org)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get maintenance schedules: %w", err)
+	}
+	identities := map[string]string{}
+	for _, maintenanceSchedule := range maintenanceSchedules {
+		index := slices.IndexFunc(existing, func(e acloudapi.MaintenanceSchedule) bool { return e.Name == maintenanceSchedule.Name })
+		if index >= 0 {
+			identities[maintenanceSchedule.Identity] = existing[index].Identity
+			r.result.skipped("maintenance schedule %s", maintenanceSchedule.Name)
+			continue
+		}
+		created, err := r.client.CreateMaintenanceSchedule(ctx, r.org, acloudapi.CreateMaintenanceSchedule{
+			Name:    maintenanceSchedule.Name,
+			Windows: maintenanceSchedule.MaintenanceWindows,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create maintenance schedule %s: %w", maintenanceSchedule.Name, err)
+		}
+		identities[maintenanceSchedule.Identity] = created.Identity
+		r.result.created("maintenance schedule %s", maintenanceSchedule.Name)
+	}
+	return identities, nil
+}
+
+func (r *restorer) restoreCloudAccounts(ctx context.Context, cloudAccounts []CloudAccount) (map[string]string, error) {
+	existing, err := r.client.GetCloudAccounts(ctx, r.org)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cloud accounts: %w", err)
+	}
+	identities := map[string]string{}
+	for _, cloudAccount := range cloudAccounts {
+		if identity, ok := r.opts.CloudAccountIdentities[cloudAccount.Identity]; ok {
+			identities[cloudAccount.Identity] = identity
+			r.result.skipped("cloud account %s", cloudAccount.DisplayName)
+			continue
+		}
+		index := slices.IndexFunc(existing, func(e acloudapi.CloudAccount) bool {
+			return e.DisplayName == cloudAccount.DisplayName && e.CloudProfile.Identity == cloudAccount.CloudProfile
+		})
+		if index >= 0 {
+			identities[cloudAccount.Identity] = existing[index].Identity
+			r.result.skipped("cloud account %s", cloudAccount.DisplayName)
+			continue
+		}
+		created, err := r.client.CreateCloudAccount(ctx, r.org, acloudapi.CreateCloudAccount{
+			DisplayName:  cloudAccount.DisplayName,
+			CloudProfile: cloudAccount.CloudProfile,
+			Metadata:     cloudAccount.Metadata,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create cloud account %s: %w", cloudAccount.DisplayName, err)
+		}
+		identities[cloudAccount.Identity] = created.Identity
+		r.result.created("cloud account %s", cloudAccount.DisplayName)
+		r.result.warn("cloud account %s has been created without credentials", cloudAccount.DisplayName)
+	}
+	return identities, nil
+}
+
+func (r *restorer) restoreEnvironments(ctx context.Context, environments []Environment) error {
+	existing, err := r.client.GetEnvironments(ctx, r.org)
+	if err != nil {
+		return fmt.Errorf("failed to get environments: %w", err)
+	}
+	clusters, err := r.client.GetClustersByOrg(ctx, r.org)
+	if err != nil {
+		return fmt.Errorf("failed to get clusters: %w", err)
+	}
+	for _, environment := range environments {
+		slug, remapped := r.opts.EnvironmentSlugs[environment.Slug]
+		if !remapped {
+			slug = environment.Slug
+		}
+		var target acloudapi.Environment
+		if index := slices.IndexFunc(existing, func(e acloudapi.Environment) bool { return e.Slug == slug }); index >= 0 {
+			target = existing[index]
+			r.result.skipped("environment %s", slug)
+		} else {
+			create := environment.CreateEnvironment
+			if remapped {
+				create.Name = slug
+			}
+			created, err := r.client.CreateEnvironment(ctx, create, r.org)
+			if err != nil {
+				return fmt.Errorf("failed to create environment %s: %w", slug, err)
+			}
+			target = *created
+			r.result.created("environment %s", target.Slug)
+		}
+
+		for _, cluster := range environment.Clusters {
+			index := slices.IndexFunc(clusters, func(c acloudapi.Cluster) bool {
+				return c.EnvironmentSlug == target.Slug && c.Slug == cluster.Slug && c.Status != acloudapi.ClusterStatusDeleted
+			})
+			var live *acloudapi.Cluster
+			if index >= 0 {
+				live = &clusters[index]
+			}
+			if err := r.restoreCluster(ctx, target, cluster, live); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// restoreCluster creates the cluster unless it exists, and then creates its missing node pools once the cluster is
+// running
+func (r *restorer) restoreCluster(ctx context.Context, environment acloudapi.Environment, cluster Cluster, live *acloudapi.Cluster) error {
+	name := environment.Slug + "/" + cluster.Slug
+	var existingNodePools []acloudapi.NodePool
+	if live != nil {
+		r.result.skipped("cluster %s", name)
+		nodePools, err := r.client.GetNodePoolsByCluster(ctx, *live)
+		if err != nil {
+			return fmt.Errorf("failed to get node pools of cluster %s: %w", name, err)
+		}
+		existingNodePools = nodePools
+	} else {
+		create := cluster.CreateCluster
+		create.EnvironmentID = strconv.Itoa(environment.ID)
+		create.NodePools = nil
+		if create.CloudAccountIdentity != "" {
+			identity, ok := r.cloudAccounts[create.CloudAccountIdentity]
+			if !ok {
+				return fmt.Errorf("failed to create cluster %s: cloud account %s is not part of the snapshot", name, create.CloudAccountIdentity)
+			}
+			create.CloudAccountIdentity = identity
+		}
+		if create.MaintenanceScheduleIdentity != "" {
+			identity, ok := r.maintenanceSchedules[create.MaintenanceScheduleIdentity]
+			if !ok {
+				return fmt.Errorf("failed to create cluster %s: maintenance schedule %s is not part of the snapshot", name, create.MaintenanceScheduleIdentity)
+			}
+			create.MaintenanceScheduleIdentity = identity
+		}
+		created, err := r.client.CreateCluster(ctx, r.org, environment.Slug, create)
+		if err != nil {
+			return fmt.Errorf("failed to create cluster %s: %w", name, err)
+		}
+		live = created
+		r.result.created("cluster %s", name)
+		if cluster.DeleteProtection {
+			if _, err := r.client.UpdateCluster(ctx, r.org, environment.Slug, live.Slug, acloudapi.UpdateCluster{DeleteProtection: acloudapi.True()}); err != nil {
+				return fmt.Errorf("failed to enable delete protection of cluster %s: %w", name, err)
+			}
+		}
+	}
+
+	var missingNodePools []acloudapi.CreateNodePool
+	for _, nodePool := range cluster.NodePools {
+		if slices.ContainsFunc(existingNodePools, func(n acloudapi.NodePool) bool { return n.Name == nodePool.Name }) {
+			r.result.skipped("node pool %s/%s", name, nodePool.Name)
+			continue
+		}
+		missingNodePools = append(missingNodePools, nodePool)
+	}
+	if len(missingNodePools) == 0 {
+		return nil
+	}
+	// node pools can only be added once the cluster is running
+	live, err := acloudapi.WaitForCluster(ctx, r.client, r.org, environment.Slug, live.Slug, acloudapi.WaitForClusterOpts{WaitOpts: r.opts.WaitOpts})
+	if err != nil {
+		return fmt.Errorf("failed to wait for cluster %s: %w", name, err)
+	}
+	for _, nodePool := range missingNodePools {
+		if _, err := r.client.CreateNodePool(ctx, *live, nodePool); err != nil {
+			return fmt.Errorf("failed to create node pool %s/%s: %w", name, nodePool.Name, err)
+		}
+		r.result.created("node pool %s/%s", name, nodePool.Name)
+	}
+	return nil
+}
+
+func (r *restorer) restoreObservabilityTenants(ctx context.Context, tenants []ObservabilityTenant) error {
+	existing, err := r.client.GetObservabilityTenants(ctx, r.org)
+	if err != nil {
+		return fmt.Errorf("failed to get observability tenants: %w", err)
+	}
+	for _, tenant := range tenants {
+		slug, ok := r.opts.ObservabilityTenantSlugs[tenant.Slug]
+		if !ok {
+			slug = tenant.Slug
+		}
+		if !slices.ContainsFunc(existing, func(e acloudapi.ObservabilityTenant) bool { return e.Slug == slug }) {
+			r.result.warn("observability tenant %s does not exist, its Prometheus rules and silences have not been restored", slug)
+			continue
+		}
+
+		if len(tenant.PrometheusRules) > 0 {
+			rules := make([]acloudapi.PrometheusRules, 0, len(tenant.PrometheusRules))
+			for _, name := range slices.Sorted(maps.Keys(tenant.PrometheusRules)) {
+				rules = append(rules, acloudapi.PrometheusRules{Name: name, Content: []byte(tenant.PrometheusRules[name])})
+			}
+			if err := r.client.AddObservabilityTenantPrometheusRules(ctx, r.org, slug, rules, true); err != nil {
+				return fmt.Errorf("failed to restore Prometheus rules of observability tenant %s: %w", slug, err)
+			}
+			for _, rule := range rules {
+				r.result.created("Prometheus rules %s/%s", slug, rule.Name)
+			}
+		}
+
+		if err := r.restoreSilences(ctx, slug, tenant.Silences); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreSilences creates the silences that have not ended yet, unless a silence with the same matchers and comment
+// exists
+func (r *restorer) restoreSilences(ctx context.Context, tenant string, silences []acloudapi.Silence) error {
+	if len(silences) == 0 {
+		return nil
+	}
+	existing, err := r.client.GetSilences(ctx, r.org, tenant)
+	if err != nil {
+		return fmt.Errorf("failed to get silences of observability tenant %s: %w", tenant, err)
+	}
+	now := time.Now()
+	for _, silence := range silences {
+		if !silence.EndsAt.After(now) {
+			continue
+		}
+		if slices.ContainsFunc(existing, func(e acloudapi.Silence) bool {
+			return !strings.EqualFold(e.Status.State, silenceStateExpired) && e.Comment == silence.Comment && slices.Equal(e.Matchers, silence.Matchers)
+		}) {
+			r.result.skipped("silence %s/%s", tenant, silence.Comment)
+			continue
+		}
+		_, err := r.client.CreateSilence(ctx, acloudapi.CreateSilence{
+			Matchers: silence.Matchers,
+			StartsAt: silence.StartsAt,
+			EndsAt:   silence.EndsAt,
+			Comment:  silence.Comment,
+		}, r.org, tenant)
+		if err != nil {
+			return fmt.Errorf("failed to create silence %q of observability tenant %s: %w", silence.Comment, tenant, err)
+		}
+		r.result.created("silence %s/%s", tenant, silence.Comment)
+	}
+	return nil
+}
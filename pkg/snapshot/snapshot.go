@@ -0,0 +1,177 @@
+// Package snapshot exports the configuration of an organisation into a versioned archive, and restores it into the
+// same or another organisation, e.g. to recreate production in a disaster recovery organisation:
+//
+//	snap, err := snapshot.Export(ctx, client, "production-org")
+//	if err != nil {
+//		return err
+//	}
+//	if err := snap.WriteFile("production-org.json.gz"); err != nil {
+//		return err
+//	}
+//	result, err := snapshot.Restore(ctx, client, *snap, "dr-org", snapshot.RestoreOpts{
+//		EnvironmentSlugs: map[string]string{"production": "dr-production"},
+//	})
+//
+// Snapshots contain no secrets: cloud accounts are exported without their credentials, and the Alertmanager
+// configuration of observability tenants, which may contain receiver credentials, is not exported.
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/avisi-cloud/go-client/pkg/acloudapi"
+)
+
+const (
+	// APIVersion is the version of the snapshot format written by this package
+	APIVersion = "snapshot.avisi.cloud/v1"
+	// Kind identifies a snapshot archive
+	Kind = "OrganisationSnapshot"
+)
+
+// Snapshot is the configuration of an organisation
+type Snapshot struct {
+	APIVersion   string    `json:"apiVersion"`
+	Kind         string    `json:"kind"`
+	Organisation string    `json:"organisation"`
+	CreatedAt    time.Time `json:"createdAt"`
+
+	Environments         []Environment                   `json:"environments"`
+	MaintenanceSchedules []acloudapi.MaintenanceSchedule `json:"maintenanceSchedules"`
+	CloudAccounts        []CloudAccount                  `json:"cloudAccounts"`
+	ObservabilityTenants []ObservabilityTenant           `json:"observabilityTenants"`
+}
+
+// Environment is an environment and its clusters
+type Environment struct {
+	Slug string `json:"slug"`
+	acloudapi.CreateEnvironment
+	Clusters []Cluster `json:"clusters"`
+}
+
+// Cluster is a cluster and its node pools. CloudAccountIdentity and MaintenanceScheduleIdentity refer to the identities
+// in the exported organisation, they are mapped to the restored ones by Restore.
+type Cluster struct {
+	Slug string `json:"slug"`
+	// CreateCluster contains the settings of the cluster, its NodePools are replaced by the NodePools of the Cluster
+	acloudapi.CreateCluster
+	DeleteProtection bool                       `json:"deleteProtection"`
+	NodePools        []acloudapi.CreateNodePool `json:"nodePools"`
+}
+
+// CloudAccount is the metadata of a cloud account, without its credentials
+type CloudAccount struct {
+	Identity     string                         `json:"identity"`
+	DisplayName  string                         `json:"displayName"`
+	CloudProfile string                         `json:"cloudProfile"`
+	Metadata     acloudapi.CloudAccountMetadata `json:"metadata"`
+}
+
+// ObservabilityTenant are the Prometheus rules and the active and pending silences of an observability tenant
+type ObservabilityTenant struct {
+	Slug            string              `json:"slug"`
+	Name            string              `json:"name"`
+	PrometheusRules map[string]string   `json:"prometheusRules"`
+	Silences        []acloudapi.Silence `json:"silences"`
+}
+
+// Validate checks that the snapshot is an organisation snapshot of a supported version
+func (s Snapshot) Validate() error {
+	if s.Kind != Kind {
+		return fmt.Errorf("unsupported kind %q, expected %q", s.Kind, Kind)
+	}
+	if s.APIVersion != APIVersion {
+		return fmt.Errorf("unsupported snapshot version %q, expected %q", s.APIVersion, APIVersion)
+	}
+	return nil
+}
+
+// Write writes the snapshot as indented JSON
+func (s Snapshot) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
+
+// WriteFile writes the snapshot to a file, compressed with gzip when the file name ends with ".gz"
+func (s Snapshot) WriteFile(path string) error {
+	var buf bytes.Buffer
+	if strings.HasSuffix(path, ".gz") {
+		gz := gzip.NewWriter(&buf)
+		if err := s.Write(gz); err != nil {
+			return err
+		}
+		if err := gz.Close(); err != nil {
+			return err
+		}
+	} else if err := s.Write(&buf); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if err := file.Chmod(0o600); err != nil {
+		file.Close()
+		return err
+	}
+	if _, err := file.Write(buf.Bytes()); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+// Read reads and validates a snapshot, written by Write or WriteFile. Gzip compressed snapshots are detected and
+// decompressed.
+func Read(r io.Reader) (*Snapshot, error) {
+	reader := bufio.NewReader(r)
+	if magic, err := reader.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		return decode(gz)
+	}
+	return decode(reader)
+}
+
+// ReadFile reads and validates a snapshot from a file
+func ReadFile(path string) (*Snapshot, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	snapshot, err := Read(file)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snapshot %s: %w", path, err)
+	}
+	return snapshot, nil
+}
+
+func decode(r io.Reader) (*Snapshot, error) {
+	snapshot := Snapshot{}
+	if err := json.NewDecoder(r).Decode(&snapshot); err != nil {
+		return nil, err
+	}
+	if err := snapshot.Validate(); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
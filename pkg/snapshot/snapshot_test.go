@@ -0,0 +1,172 @@
+package snapshot
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/avisi-cloud/go-client/pkg/acloudapi"
+	"github.com/avisi-cloud/go-client/pkg/acloudapi/acloudapitest"
+)
+
+func TestExportAndRestore(t *testing.T) {
+	server := acloudapitest.NewServer(acloudapitest.Opts{})
+	defer server.Close()
+	client := server.Client()
+	ctx := context.Background()
+
+	for _, org := range []string{"org1", "org2"} {
+		server.AddOrganisation(acloudapi.Organisation{Slug: org})
+		server.AddCloudProfile(org, acloudapi.CloudProfile{Identity: "aws-profile", CloudProvider: "aws"})
+		server.AddObservabilityTenant(org, acloudapi.ObservabilityTenant{Slug: "monitoring-" + org})
+	}
+	tenantID := "tenant-1"
+	cloudAccount := server.AddCloudAccount("org1", acloudapi.CloudAccount{
+		DisplayName:  "aws",
+		CloudProfile: acloudapi.CloudProfile{Identity: "aws-profile", CloudProvider: "aws"},
+		Metadata:     acloudapi.CloudAccountMetadata{OpenStackTenantID: &tenantID},
+	})
+	maintenanceSchedule := server.AddMaintenanceSchedule("org1", acloudapi.MaintenanceSchedule{
+		Name:               "weekly",
+		MaintenanceWindows: []acloudapi.MaintenanceWindow{{Day: "MONDAY", StartTime: "02:00", Duration: 120}},
+	})
+	production := server.AddEnvironment("org1", acloudapi.Environment{Name: "production", Slug: "production", Type: "production"})
+	web := server.AddCluster(acloudapi.Cluster{
+		CustomerSlug:        "org1",
+		EnvironmentSlug:     production.Slug,
+		Name:                "web",
+		Slug:                "web",
+		Region:              "eu-west-1",
+		Version:             "1.31.2",
+		DeleteProtection:    true,
+		CloudAccount:        &acloudapi.CloudAccountReference{Identity: cloudAccount.Identity, DisplayName: cloudAccount.DisplayName},
+		MaintenanceSchedule: &maintenanceSchedule,
+		IPWhitelist:         []acloudapi.IpWhitelistResponse{{Cidr: "10.0.0.0/8", Description: "office"}},
+	})
+	server.AddNodePool(web.Identity, acloudapi.NodePool{
+		Name:     "workers",
+		NodeSize: "small",
+		MinSize:  3,
+		MaxSize:  3,
+		Labels:   map[string]string{"role": "worker"},
+		Taints:   []acloudapi.NodeTaint{{Key: "dedicated", Value: "web", Effect: "NoSchedule"}},
+	})
+	if err := client.AddObservabilityTenantPrometheusRules(ctx, "org1", "monitoring-org1", []acloudapi.PrometheusRules{{Name: "web.yaml", Content: []byte("groups: []")}}, false); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	matchers := []acloudapi.SilenceMatcher{{Name: "alertname", Value: "Watchdog", IsEqual: true}}
+	server.AddSilence("org1", "monitoring-org1", acloudapi.Silence{Matchers: matchers, StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour), Comment: "active"})
+	server.AddSilence("org1", "monitoring-org1", acloudapi.Silence{Matchers: matchers, StartsAt: now.Add(-2 * time.Hour), EndsAt: now.Add(-time.Hour), Comment: "expired"})
+
+	exported, err := Export(ctx, client, "org1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "org1.json.gz")
+	if err := exported.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(snapshot.Environments) != 1 || len(snapshot.Environments[0].Clusters) != 1 || len(snapshot.CloudAccounts) != 1 || len(snapshot.MaintenanceSchedules) != 1 {
+		t.Fatalf("unexpected snapshot %+v", snapshot)
+	}
+	tenant := snapshot.ObservabilityTenants[0]
+	if tenant.PrometheusRules["web.yaml"] != "groups: []" || len(tenant.Silences) != 1 || tenant.Silences[0].Comment != "active" {
+		t.Fatalf("unexpected observability tenant %+v", tenant)
+	}
+
+	opts := RestoreOpts{
+		EnvironmentSlugs:         map[string]string{"production": "dr-production"},
+		ObservabilityTenantSlugs: map[string]string{"monitoring-org1": "monitoring-org2"},
+		WaitOpts:                 acloudapi.WaitOpts{PollInterval: time.Millisecond, Timeout: 5 * time.Second},
+	}
+	result, err := Restore(ctx, nodePoolCheckingClient{Client: client, t: t, server: server}, *snapshot, "org2", opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantCreated := []string{
+		"maintenance schedule weekly",
+		"cloud account aws",
+		"environment dr-production",
+		"cluster dr-production/web",
+		"node pool dr-production/web/workers",
+		"Prometheus rules monitoring-org2/web.yaml",
+		"silence monitoring-org2/active",
+	}
+	if !slices.Equal(result.Created, wantCreated) || len(result.Skipped) != 0 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "without credentials") {
+		t.Fatalf("unexpected warnings %v", result.Warnings)
+	}
+
+	restored, err := client.GetCluster(ctx, "org2", "dr-production", "web")
+	if err != nil {
+		t.Fatalf("cluster not restored: %v", err)
+	}
+	if !restored.DeleteProtection || restored.Version != "1.31.2" || restored.CloudAccount == nil || restored.CloudAccount.Identity == cloudAccount.Identity ||
+		restored.MaintenanceSchedule == nil || restored.MaintenanceSchedule.Name != "weekly" || len(restored.IPWhitelist) != 1 {
+		t.Fatalf("unexpected restored cluster %+v", restored)
+	}
+	nodePools := server.NodePools(restored.Identity)
+	if len(nodePools) != 1 || nodePools[0].Labels["role"] != "worker" || len(nodePools[0].Taints) != 1 {
+		t.Fatalf("unexpected restored node pools %+v", nodePools)
+	}
+
+	// restoring again skips everything that exists
+	result, err = Restore(ctx, client, *snapshot, "org2", opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Created) != 1 || result.Created[0] != "Prometheus rules monitoring-org2/web.yaml" || len(result.Skipped) != 6 {
+		t.Fatalf("unexpected result of the second restore %+v", result)
+	}
+}
+
+// nodePoolCheckingClient fails the test when a node pool is created in a cluster that is not running
+type nodePoolCheckingClient struct {
+	acloudapi.Client
+	t      *testing.T
+	server *acloudapitest.Server
+}
+
+func (c nodePoolCheckingClient) CreateNodePool(ctx context.Context, cluster acloudapi.Cluster, create acloudapi.CreateNodePool) (*acloudapi.NodePool, error) {
+	if live, _ := c.server.Cluster(cluster.Identity); live.Status != acloudapi.ClusterStatusRunning {
+		c.t.Errorf("node pool %s created while cluster %s is %s", create.Name, cluster.Slug, live.Status)
+	}
+	return c.Client.CreateNodePool(ctx, cluster, create)
+}
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{name: "valid", input: `{"apiVersion": "snapshot.avisi.cloud/v1", "kind": "OrganisationSnapshot", "organisation": "org1"}`},
+		{name: "unsupported version", input: `{"apiVersion": "snapshot.avisi.cloud/v2", "kind": "OrganisationSnapshot"}`, wantErr: "unsupported snapshot version"},
+		{name: "wrong kind", input: `{"apiVersion": "snapshot.avisi.cloud/v1", "kind": "Manifest"}`, wantErr: "unsupported kind"},
+		{name: "invalid JSON", input: `{`, wantErr: "unexpected EOF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshot, err := Read(strings.NewReader(tt.input))
+			if tt.wantErr == "" {
+				if err != nil || snapshot.Organisation != "org1" {
+					t.Fatalf("unexpected result %+v, %v", snapshot, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,312 @@
+// Package upgrade plans the upgrade path of a cluster to a target Kubernetes version, or the head of its update
+// channel. Every step upgrades to the next minor version, as minor versions cannot be skipped. The plan lists the node
+// pools whose nodes are drained or replaced in each step, and the maintenance window each step is expected to fall
+// into:
+//
+//	plan, err := upgrade.Fetch(ctx, client, *cluster, upgrade.Opts{TargetVersion: "1.31"})
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Print(plan)
+package upgrade
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/avisi-cloud/go-client/pkg/acloudapi"
+)
+
+// Client is the part of the API client used to fetch the data of a plan
+type Client interface {
+	acloudapi.UpdateChannelAPI
+	acloudapi.ClusterVersionAPI
+	acloudapi.NodePoolsAPI
+}
+
+// Opts are the options of NewPlan and Fetch
+type Opts struct {
+	// TargetVersion is the version to upgrade to. A minor version (e.g. 1.31) upgrades to its latest available patch
+	// version. By default the cluster is upgraded to the version of its update channel.
+	TargetVersion string
+	// UpdateChannel is the update channel to upgrade to the version of, instead of the update channel of the cluster
+	UpdateChannel string
+	// Now is the time from which upcoming maintenance windows are estimated, defaults to the current time
+	Now time.Time
+	// Location is the time zone of the maintenance windows, defaults to UTC
+	Location *time.Location
+}
+
+func mergeOpts(opts []Opts) Opts {
+	merged := Opts{}
+	for _, opt := range opts {
+		if opt.TargetVersion != "" {
+			merged.TargetVersion = opt.TargetVersion
+		}
+		if opt.UpdateChannel != "" {
+			merged.UpdateChannel = opt.UpdateChannel
+		}
+		if !opt.Now.IsZero() {
+			merged.Now = opt.Now
+		}
+		if opt.Location != nil {
+			merged.Location = opt.Location
+		}
+	}
+	if merged.Now.IsZero() {
+		merged.Now = time.Now()
+	}
+	if merged.Location == nil {
+		merged.Location = time.UTC
+	}
+	return merged
+}
+
+// Plan is the upgrade path of a cluster
+type Plan struct {
+	// Cluster is the identifier of the cluster, e.g. "org/env/cluster"
+	Cluster       string            `json:"cluster"`
+	From          acloudapi.Version `json:"from"`
+	To            acloudapi.Version `json:"to"`
+	UpdateChannel string            `json:"updateChannel,omitempty"`
+	Steps         []Step            `json:"steps"`
+	Warnings      []string          `json:"warnings,omitempty"`
+}
+
+// Step is a single upgrade of the cluster
+type Step struct {
+	From acloudapi.Version `json:"from"`
+	To   acloudapi.Version `json:"to"`
+	// Minor is true if the step upgrades to the next minor version, false for a patch upgrade
+	Minor bool `json:"minor"`
+	// Window is the maintenance window the step is expected to fall into, assuming a step per window. It is nil when
+	// the cluster has no maintenance schedule.
+	Window *acloudapi.MaintenanceWindowOccurrence `json:"window,omitempty"`
+	// NodePools are the node pools whose nodes are drained or replaced by the step
+	NodePools []NodePoolImpact `json:"nodePools"`
+}
+
+// NodePoolImpact is the impact of an upgrade step on the nodes of a node pool
+type NodePoolImpact struct {
+	NodePool        string                            `json:"nodePool"`
+	UpgradeStrategy acloudapi.NodePoolUpgradeStrategy `json:"upgradeStrategy"`
+	// Drain is true if the workloads are evicted from the nodes
+	Drain bool `json:"drain"`
+	// Replace is true if the nodes are replaced by new nodes
+	Replace bool `json:"replace"`
+}
+
+// Disruptive returns true if the nodes are drained or replaced
+func (n NodePoolImpact) Disruptive() bool {
+	return n.Drain || n.Replace
+}
+
+// HasSteps returns true if the cluster needs to be upgraded
+func (p *Plan) HasSteps() bool {
+	return len(p.Steps) > 0
+}
+
+// String returns a human-readable report of the plan
+func (p *Plan) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Upgrade plan for %s: %s -> %s", p.Cluster, p.From, p.To)
+	if p.UpdateChannel != "" {
+		fmt.Fprintf(&b, " (update channel %s)", p.UpdateChannel)
+	}
+	b.WriteString("\n")
+	if !p.HasSteps() {
+		b.WriteString("  cluster is up to date\n")
+	}
+	for i, step := range p.Steps {
+		kind := "patch"
+		if step.Minor {
+			kind = "minor"
+		}
+		fmt.Fprintf(&b, "%d. %s -> %s (%s)", i+1, step.From, step.To, kind)
+		if step.Window != nil {
+			fmt.Fprintf(&b, " in maintenance window %s", step.Window)
+		}
+		b.WriteString("\n")
+		for _, nodePool := range step.NodePools {
+			fmt.Fprintf(&b, "   node pool %s (%s): %s\n", nodePool.NodePool, nodePool.UpgradeStrategy, nodePool.description())
+		}
+	}
+	for _, warning := range p.Warnings {
+		fmt.Fprintf(&b, "warning: %s\n", warning)
+	}
+	return b.String()
+}
+
+func (n NodePoolImpact) description() string {
+	switch {
+	case n.Replace:
+		return "nodes are drained and replaced"
+	case n.Drain:
+		return "nodes are drained and upgraded in place"
+	default:
+		return "nodes are upgraded in place without draining"
+	}
+}
+
+// Fetch gets the update channels, cluster versions and node pools of the cluster, and plans its upgrade
+func Fetch(ctx context.Context, client Client, cluster acloudapi.Cluster, opts ...Opts) (*Plan, error) {
+	updateChannels, err := client.GetUpdateChannels(ctx, cluster.CustomerSlug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get update channels: %w", err)
+	}
+	clusterVersions, err := client.GetClusterVersions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster versions: %w", err)
+	}
+	nodePools, err := client.GetNodePoolsByCluster(ctx, cluster)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get node pools of cluster %s: %w", cluster.Identifier(), err)
+	}
+	return NewPlan(cluster, updateChannels, clusterVersions, nodePools, opts...)
+}
+
+// NewPlan plans the upgrade of the cluster to the target version, using the available cluster versions. Every step
+// upgrades to the latest available patch version of the next minor version, or of the target version in the last
+// step. Downgrades are not supported.
+func NewPlan(cluster acloudapi.Cluster, updateChannels []acloudapi.UpdateChannelResponse, clusterVersions []acloudapi.ClusterVersion, nodePools []acloudapi.NodePool, opts ...Opts) (*Plan, error) {
+	mergedOpts := mergeOpts(opts)
+	plan := &Plan{Cluster: cluster.Identifier(), Steps: []Step{}}
+	from, err := acloudapi.ParseVersion(cluster.Version)
+	if err != nil {
+		return nil, fmt.Errorf("invalid version of cluster %s: %w", plan.Cluster, err)
+	}
+	plan.From = from
+
+	var available []acloudapi.Version
+	for _, clusterVersion := range clusterVersions {
+		version, err := clusterVersion.ParsedVersion()
+		if err != nil {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("ignoring invalid cluster version %q", clusterVersion.Version))
+			continue
+		}
+		available = append(available, version)
+	}
+	slices.SortFunc(available, acloudapi.Version.Compare)
+
+	target, err := targetVersion(plan, cluster, updateChannels, available, mergedOpts)
+	if err != nil {
+		return nil, err
+	}
+	plan.To = target
+	switch {
+	case target.Major != from.Major:
+		return nil, fmt.Errorf("cannot upgrade cluster %s from %s to %s: major version upgrades are not supported", plan.Cluster, from, target)
+	case target.LessThan(from):
+		return nil, fmt.Errorf("cannot upgrade cluster %s from %s to %s: downgrades are not supported", plan.Cluster, from, target)
+	}
+
+	current := from
+	for minor := from.Minor + 1; minor <= target.Minor; minor++ {
+		to := target
+		if minor < target.Minor {
+			to, err = latestPatch(available, acloudapi.Version{Major: from.Major, Minor: minor})
+			if err != nil {
+				return nil, fmt.Errorf("cannot upgrade cluster %s to %s: %w", plan.Cluster, target, err)
+			}
+		}
+		plan.Steps = append(plan.Steps, newStep(current, to, nodePools))
+		current = to
+	}
+	if current.LessThan(target) {
+		plan.Steps = append(plan.Steps, newStep(current, target, nodePools))
+	}
+
+	if len(plan.Steps) > 0 {
+		if cluster.MaintenanceSchedule == nil || len(cluster.MaintenanceSchedule.MaintenanceWindows) == 0 {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("cluster %s has no maintenance schedule, the upgrades are not restricted to maintenance windows", plan.Cluster))
+		} else {
+			windows, err := cluster.MaintenanceSchedule.NextOccurrences(mergedOpts.Now, mergedOpts.Location, len(plan.Steps))
+			if err != nil {
+				return nil, fmt.Errorf("invalid maintenance schedule of cluster %s: %w", plan.Cluster, err)
+			}
+			for i, window := range windows {
+				plan.Steps[i].Window = &window
+			}
+		}
+	}
+	return plan, nil
+}
+
+// targetVersion returns the target version of the options, or else the version of the update channel
+func targetVersion(plan *Plan, cluster acloudapi.Cluster, updateChannels []acloudapi.UpdateChannelResponse, available []acloudapi.Version, opts Opts) (acloudapi.Version, error) {
+	target := opts.TargetVersion
+	if target == "" {
+		plan.UpdateChannel = opts.UpdateChannel
+		if plan.UpdateChannel == "" && cluster.UpdateChannel != nil {
+			plan.UpdateChannel = cluster.UpdateChannel.Name
+		}
+		if plan.UpdateChannel == "" {
+			return acloudapi.Version{}, fmt.Errorf("cluster %s has no update channel, a target version is required", plan.Cluster)
+		}
+		index := slices.IndexFunc(updateChannels, func(u acloudapi.UpdateChannelResponse) bool { return u.Name == plan.UpdateChannel })
+		if index < 0 {
+			return acloudapi.Version{}, fmt.Errorf("update channel %s does not exist", plan.UpdateChannel)
+		}
+		target = updateChannels[index].KubernetesClusterVersion
+	}
+
+	version, err := acloudapi.ParseVersion(target)
+	if err != nil {
+		return acloudapi.Version{}, fmt.Errorf("invalid target version: %w", err)
+	}
+	if version.IsPartial() {
+		return latestPatch(available, version)
+	}
+	if !slices.ContainsFunc(available, version.Equal) {
+		return acloudapi.Version{}, fmt.Errorf("version %s is not available", version)
+	}
+	return version, nil
+}
+
+// latestPatch returns the highest available version included by the partial version
+func latestPatch(available []acloudapi.Version, partial acloudapi.Version) (acloudapi.Version, error) {
+	if !partial.IsPartial() {
+		partial = partial.MinorVersion()
+	}
+	for _, version := range slices.Backward(available) {
+		if partial.Includes(version) {
+			return version, nil
+		}
+	}
+	return acloudapi.Version{}, fmt.Errorf("no version %s is available", partial)
+}
+
+func newStep(from, to acloudapi.Version, nodePools []acloudapi.NodePool) Step {
+	step := Step{From: from, To: to, Minor: !from.SameMinor(to), NodePools: []NodePoolImpact{}}
+	for _, nodePool := range nodePools {
+		impact := nodePoolImpact(nodePool, step.Minor)
+		if impact.Disruptive() {
+			step.NodePools = append(step.NodePools, impact)
+		}
+	}
+	return step
+}
+
+// nodePoolImpact returns whether the upgrade strategy of the node pool drains or replaces its nodes. Node pools
+// without a known upgrade strategy are assumed to be replaced.
+func nodePoolImpact(nodePool acloudapi.NodePool, minor bool) NodePoolImpact {
+	impact := NodePoolImpact{NodePool: nodePool.Name, UpgradeStrategy: nodePool.UpgradeStrategy}
+	switch nodePool.UpgradeStrategy {
+	case acloudapi.NodePoolUpgradeStrategyInPlace:
+		impact.Drain = true
+	case acloudapi.NodePoolUpgradeStrategyInPlaceWithoutDrain:
+	case acloudapi.NodePoolUpgradeStrategyReplaceMinorInPlacePatch:
+		impact.Drain = true
+		impact.Replace = minor
+	case acloudapi.NodePoolUpgradeStrategyReplaceMinorInPlacePatchNoDrain:
+		impact.Drain = minor
+		impact.Replace = minor
+	default:
+		impact.Drain = true
+		impact.Replace = true
+	}
+	return impact
+}
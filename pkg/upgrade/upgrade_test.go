@@ -0,0 +1,135 @@
+package upgrade
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/avisi-cloud/go-client/pkg/acloudapi"
+	"github.com/avisi-cloud/go-client/pkg/acloudapi/acloudapitest"
+)
+
+func TestFetch(t *testing.T) {
+	server := acloudapitest.NewServer(acloudapitest.Opts{})
+	defer server.Close()
+	server.AddOrganisation(acloudapi.Organisation{Slug: "org1"})
+	server.AddEnvironment("org1", acloudapi.Environment{Slug: "env1"})
+	for _, version := range []string{"1.9.0", "1.29.4", "1.29.8", "1.30.1", "1.30.6", "1.31.0", "1.31.2", "1.32.0"} {
+		server.AddClusterVersion(acloudapi.AdminClusterVersion{Version: version, Available: true})
+	}
+	server.AddUpdateChannel(acloudapi.UpdateChannelResponse{Name: "stable", Available: true, KubernetesClusterVersion: "1.31"})
+	cluster := server.AddCluster(acloudapi.Cluster{
+		CustomerSlug:    "org1",
+		EnvironmentSlug: "env1",
+		Slug:            "web",
+		Version:         "1.29.4",
+		UpdateChannel:   &acloudapi.UpdateChannelResponse{Name: "stable"},
+		MaintenanceSchedule: &acloudapi.MaintenanceSchedule{MaintenanceWindows: []acloudapi.MaintenanceWindow{
+			{Day: "MONDAY", StartTime: "02:00", Duration: 120},
+			{Day: "THURSDAY", StartTime: "22:00", Duration: 60},
+		}},
+	})
+	server.AddNodePool(cluster.Identity, acloudapi.NodePool{Name: "replaced", NodeSize: "small", MinSize: 1, MaxSize: 1, UpgradeStrategy: acloudapi.NodePoolUpgradeStrategyReplace})
+	server.AddNodePool(cluster.Identity, acloudapi.NodePool{Name: "in-place", NodeSize: "small", MinSize: 1, MaxSize: 1, UpgradeStrategy: acloudapi.NodePoolUpgradeStrategyInPlaceWithoutDrain})
+
+	// Monday 05:00, after this week's Monday window
+	now := time.Date(2026, time.October, 19, 5, 0, 0, 0, time.UTC)
+	plan, err := Fetch(context.Background(), server.Client(), cluster, Opts{Now: now})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `Upgrade plan for org1/env1/web: 1.29.4 -> 1.31.2 (update channel stable)
+1. 1.29.4 -> 1.30.6 (minor) in maintenance window Thu 2026-10-22 22:00 - 23:00 UTC
+   node pool replaced (REPLACE): nodes are drained and replaced
+2. 1.30.6 -> 1.31.2 (minor) in maintenance window Mon 2026-10-26 02:00 - 04:00 UTC
+   node pool replaced (REPLACE): nodes are drained and replaced
+`
+	if plan.String() != want {
+		t.Fatalf("unexpected plan:\n%s\nwant:\n%s", plan, want)
+	}
+
+	data, err := json.Marshal(plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := Plan{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("invalid JSON plan: %v", err)
+	}
+	if !decoded.To.Equal(acloudapi.MustParseVersion("1.31.2")) || len(decoded.Steps) != 2 || decoded.Steps[1].Window == nil {
+		t.Fatalf("unexpected JSON plan %s", data)
+	}
+}
+
+func TestNewPlan(t *testing.T) {
+	clusterVersions := []acloudapi.ClusterVersion{{Version: "1.29.4"}, {Version: "1.29.8"}, {Version: "1.31.2"}}
+	updateChannels := []acloudapi.UpdateChannelResponse{{Name: "stable", KubernetesClusterVersion: "1.29"}}
+	nodePools := []acloudapi.NodePool{
+		{Name: "mixed", UpgradeStrategy: acloudapi.NodePoolUpgradeStrategyReplaceMinorInPlacePatch},
+		{Name: "mixed-without-drain", UpgradeStrategy: acloudapi.NodePoolUpgradeStrategyReplaceMinorInPlacePatchNoDrain},
+	}
+	tests := []struct {
+		name    string
+		cluster acloudapi.Cluster
+		opts    Opts
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "patch upgrade",
+			cluster: acloudapi.Cluster{Slug: "web", Version: "1.29.4", UpdateChannel: &acloudapi.UpdateChannelResponse{Name: "stable"}},
+			want: `1. 1.29.4 -> 1.29.8 (patch)
+   node pool mixed (REPLACE_MINOR_INPLACE_PATCH): nodes are drained and upgraded in place
+warning: cluster //web has no maintenance schedule, the upgrades are not restricted to maintenance windows`,
+		},
+		{
+			name:    "up to date",
+			cluster: acloudapi.Cluster{Slug: "web", Version: "1.29.8"},
+			opts:    Opts{TargetVersion: "1.29"},
+			want:    "  cluster is up to date",
+		},
+		{
+			name:    "skipped minor version",
+			cluster: acloudapi.Cluster{Slug: "web", Version: "1.29.8"},
+			opts:    Opts{TargetVersion: "1.31.2"},
+			wantErr: "no version 1.30 is available",
+		},
+		{
+			name:    "downgrade",
+			cluster: acloudapi.Cluster{Slug: "web", Version: "1.31.2"},
+			opts:    Opts{TargetVersion: "1.29.8"},
+			wantErr: "downgrades are not supported",
+		},
+		{
+			name:    "unavailable target version",
+			cluster: acloudapi.Cluster{Slug: "web", Version: "1.29.4"},
+			opts:    Opts{TargetVersion: "1.29.5"},
+			wantErr: "version 1.29.5 is not available",
+		},
+		{
+			name:    "no update channel",
+			cluster: acloudapi.Cluster{Slug: "web", Version: "1.29.4"},
+			wantErr: "a target version is required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := NewPlan(tt.cluster, updateChannels, clusterVersions, nodePools, tt.opts)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			_, report, _ := strings.Cut(plan.String(), "\n")
+			if strings.TrimRight(report, "\n") != tt.want {
+				t.Fatalf("unexpected plan:\n%s\nwant:\n%s", report, tt.want)
+			}
+		})
+	}
+}